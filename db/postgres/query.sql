@@ -1,6 +1,7 @@
--- name: CreateUser :exec
+-- name: CreateUser :one
 INSERT INTO users(name, password, email, role)
-VALUES ($1, $2, $3, $4);
+VALUES ($1, $2, $3, $4)
+RETURNING id;
 
 -- name: UpdateUser :exec
 UPDATE users SET name=$2, password=$3, role=$4, is_deleted=$5
@@ -10,22 +11,89 @@ WHERE email = $1;
 UPDATE users SET is_deleted=true
 WHERE email = $1;
 
+-- name: VerifyUser :execrows
+UPDATE users SET verifay=true
+WHERE email = $1 and is_deleted IS NOT TRUE;
+
 -- name: ReadUser :one
 SELECT * FROM users
 WHERE email = $1;
 
--- name: CreateSession :exec
+-- name: UpdateUserPassword :execrows
+UPDATE users SET password=$2
+WHERE email = $1 and is_deleted IS NOT TRUE;
+
+-- name: CreatePasswordResetToken :exec
+INSERT INTO password_reset_tokens(token_hash, user_email, expires_in)
+VALUES ($1, $2, $3);
+
+-- name: UsePasswordResetToken :one
+UPDATE password_reset_tokens SET used_at=now()
+WHERE token_hash=$1 and used_at IS NULL and expires_in > $2
+RETURNING user_email;
+
+-- name: RevokePasswordResetTokens :exec
+UPDATE password_reset_tokens SET used_at=now()
+WHERE user_email=$1 and used_at IS NULL;
+
+-- name: ReadUserByID :one
+SELECT id, name, email, role, is_deleted, verifay FROM users
+WHERE id = $1;
+
+-- name: ListUsers :many
+SELECT id, name, email, role, is_deleted, verifay FROM users
+WHERE (sqlc.arg(search)::text = '' OR email ILIKE '%' || sqlc.arg(search)::text || '%'
+    OR name ILIKE '%' || sqlc.arg(search)::text || '%')
+  AND (sqlc.arg(with_deleted)::bool OR is_deleted IS NOT TRUE)
+ORDER BY id
+LIMIT sqlc.arg(limit_count) OFFSET sqlc.arg(offset_count);
+
+-- name: CountUsers :one
+SELECT count(*) FROM users
+WHERE (sqlc.arg(search)::text = '' OR email ILIKE '%' || sqlc.arg(search)::text || '%'
+    OR name ILIKE '%' || sqlc.arg(search)::text || '%')
+  AND (sqlc.arg(with_deleted)::bool OR is_deleted IS NOT TRUE);
+
+-- name: UpdateUserRole :one
+UPDATE users SET role=$2
+WHERE id = $1
+RETURNING email;
+
+-- name: DeleteUserByID :one
+UPDATE users SET is_deleted=true
+WHERE id = $1
+RETURNING email;
+
+-- name: RestoreUser :execrows
+UPDATE users SET is_deleted=false
+WHERE id = $1;
+
+-- name: DeleteUserSessions :many
+DELETE FROM sessions
+WHERE user_email=$1
+RETURNING *;
+
+-- name: CreateSession :one
 INSERT INTO sessions(user_email, refresh_token, access_token, user_agent, ip, expires_in, created_at)
-VALUES ($1, $2, $3, $4, $5, $6, DEFAULT);
+VALUES ($1, $2, $3, $4, $5, $6, DEFAULT)
+RETURNING id;
 
 -- name: UpdateSession :exec
 UPDATE sessions SET refresh_token=$3, expires_in=$4, created_at=DEFAULT
 WHERE user_email= $1 and refresh_token=$2;
 
--- name: UpdateSessionTokenOnly :exec
+-- name: UpdateSessionTokenOnly :execrows
 UPDATE sessions SET refresh_token=$2, expires_in=$3, created_at=DEFAULT
 WHERE refresh_token=$1;
 
+-- name: CreateRetiredRefreshToken :exec
+INSERT INTO retired_refresh_tokens(token, session_id, user_email, retired_at)
+VALUES ($1, $2, $3, DEFAULT);
+
+-- name: ReadRetiredRefreshToken :one
+SELECT * FROM retired_refresh_tokens
+WHERE token=$1;
+
 -- name: DeleteSession :exec
 DELETE FROM sessions
 WHERE user_agent=$1 and ip=$2 and user_email=$3;
@@ -34,31 +102,353 @@ WHERE user_agent=$1 and ip=$2 and user_email=$3;
 SELECT * FROM sessions
 WHERE user_email=$1 and  user_agent=$2 and ip=$3;
 
--- name: CreateContent :exec
+-- name: ListSessions :many
+SELECT * FROM sessions
+WHERE user_email=$1 and expires_in > $2
+ORDER BY created_at DESC;
+
+-- name: UpdateSessionAccessToken :exec
+UPDATE sessions SET access_token=$2, access_expires_in=$3
+WHERE id=$1;
+
+-- name: DeleteSessionByID :one
+DELETE FROM sessions
+WHERE id=$1 and user_email=$2
+RETURNING *;
+
+-- name: DeleteOtherSessions :many
+DELETE FROM sessions
+WHERE user_email=$1 and id <> $2
+RETURNING *;
+
+-- name: CreateContent :one
 INSERT INTO content(user_email, name, description)
-VALUES ($1, $2, $3);
+VALUES ($1, $2, $3)
+RETURNING id;
 
--- name: UpdateContent :exec
-UPDATE content SET user_email=$1, name=$3, description=$4
-WHERE user_email= $1 and name =$2;
+-- name: UpdateContent :one
+UPDATE content SET name=$3, description=$4, updated_at=now()
+WHERE id=$2 and (user_email=$1 OR EXISTS (SELECT 1 FROM content_shares s
+                                         WHERE s.content_id = content.id AND s.user_email = $1
+                                           AND s.permission = 'edit'))
+RETURNING id, user_email, name, description, created_at, updated_at;
 
--- name: DeleteContent :exec
+-- name: DeleteContent :execrows
 DELETE FROM content
-WHERE user_email=$1 and name=$2;
+WHERE user_email=$1 and id=$2;
+
+-- name: ListContent :many
+SELECT id, user_email, name, description, created_at, updated_at FROM content
+WHERE user_email = sqlc.arg(user_email)
+  AND (sqlc.arg(name_filter)::text = '' OR name ILIKE '%' || sqlc.arg(name_filter)::text || '%' ESCAPE '\')
+  AND (sqlc.arg(cursor_id)::int = 0
+    OR (sqlc.arg(sort_by)::text = 'name' AND NOT sqlc.arg(sort_desc)::bool
+        AND (coalesce(name, ''), id) > (sqlc.arg(cursor_name)::text, sqlc.arg(cursor_id)::int))
+    OR (sqlc.arg(sort_by)::text = 'name' AND sqlc.arg(sort_desc)::bool
+        AND (coalesce(name, ''), id) < (sqlc.arg(cursor_name)::text, sqlc.arg(cursor_id)::int))
+    OR (sqlc.arg(sort_by)::text = 'created_at' AND NOT sqlc.arg(sort_desc)::bool
+        AND (created_at, id) > (sqlc.arg(cursor_time)::timestamptz, sqlc.arg(cursor_id)::int))
+    OR (sqlc.arg(sort_by)::text = 'created_at' AND sqlc.arg(sort_desc)::bool
+        AND (created_at, id) < (sqlc.arg(cursor_time)::timestamptz, sqlc.arg(cursor_id)::int))
+    OR (sqlc.arg(sort_by)::text = 'updated_at' AND NOT sqlc.arg(sort_desc)::bool
+        AND (updated_at, id) > (sqlc.arg(cursor_time)::timestamptz, sqlc.arg(cursor_id)::int))
+    OR (sqlc.arg(sort_by)::text = 'updated_at' AND sqlc.arg(sort_desc)::bool
+        AND (updated_at, id) < (sqlc.arg(cursor_time)::timestamptz, sqlc.arg(cursor_id)::int)))
+ORDER BY
+    CASE WHEN sqlc.arg(sort_by)::text = 'name' AND NOT sqlc.arg(sort_desc)::bool THEN coalesce(name, '') END,
+    CASE WHEN sqlc.arg(sort_by)::text = 'name' AND sqlc.arg(sort_desc)::bool THEN coalesce(name, '') END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'created_at' AND NOT sqlc.arg(sort_desc)::bool THEN created_at END,
+    CASE WHEN sqlc.arg(sort_by)::text = 'created_at' AND sqlc.arg(sort_desc)::bool THEN created_at END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'updated_at' AND NOT sqlc.arg(sort_desc)::bool THEN updated_at END,
+    CASE WHEN sqlc.arg(sort_by)::text = 'updated_at' AND sqlc.arg(sort_desc)::bool THEN updated_at END DESC,
+    CASE WHEN NOT sqlc.arg(sort_desc)::bool THEN id END,
+    CASE WHEN sqlc.arg(sort_desc)::bool THEN id END DESC
+LIMIT sqlc.arg(limit_count);
 
 -- name: ReadContent :one
-SELECT * FROM content
-WHERE user_email=$1 and id=$2;
+SELECT c.id, c.user_email, c.name, c.description, c.created_at, c.updated_at,
+       (CASE WHEN c.user_email = sqlc.arg(user_email) THEN 'owner' ELSE s.permission END)::text AS access
+FROM content c
+LEFT JOIN content_shares s ON s.content_id = c.id AND s.user_email = sqlc.arg(user_email)
+WHERE c.id = sqlc.arg(id) AND (c.user_email = sqlc.arg(user_email) OR s.permission IS NOT NULL);
+
+-- name: ReadContentForUpdate :one
+SELECT id, name, description FROM content
+WHERE id=$1
+FOR UPDATE;
+
+-- name: CreateContentRevision :one
+INSERT INTO content_revisions(content_id, revision, author_email, name, description, summary)
+VALUES ($1, (SELECT coalesce(max(revision), 0) + 1 FROM content_revisions WHERE content_id = $1), $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ReadContentRevision :one
+SELECT * FROM content_revisions
+WHERE content_id=$1 and revision=$2;
+
+-- name: ListContentRevisions :many
+SELECT * FROM content_revisions
+WHERE content_id = sqlc.arg(content_id)
+ORDER BY revision DESC
+LIMIT sqlc.arg(limit_count) OFFSET sqlc.arg(offset_count);
+
+-- name: CountContentRevisions :one
+SELECT count(*) FROM content_revisions
+WHERE content_id=$1;
+
+-- name: UpsertContentShare :one
+INSERT INTO content_shares(content_id, user_email, permission)
+VALUES ($1, $2, $3)
+ON CONFLICT (content_id, user_email) DO UPDATE SET permission = excluded.permission
+RETURNING *;
+
+-- name: DeleteContentShare :execrows
+DELETE FROM content_shares
+WHERE content_id=$1 and user_email=$2;
+
+-- name: ListContentShares :many
+SELECT * FROM content_shares
+WHERE content_id=$1
+ORDER BY created_at, user_email;
+
+-- name: ListSharedContent :many
+SELECT c.id, c.user_email, c.name, c.description, c.created_at, c.updated_at, s.permission FROM content_shares s
+INNER JOIN content c ON c.id = s.content_id
+WHERE s.user_email = sqlc.arg(user_email)
+ORDER BY s.created_at DESC, c.id DESC
+LIMIT sqlc.arg(limit_count) OFFSET sqlc.arg(offset_count);
+
+-- name: CountSharedContent :one
+SELECT count(*) FROM content_shares
+WHERE user_email=$1;
+
+-- name: CreateContentLink :one
+INSERT INTO content_links(content_id, token_hash, expires_in)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ListContentLinks :many
+SELECT * FROM content_links
+WHERE content_id=$1 and expires_in > $2
+ORDER BY created_at, id;
 
--- name: CreateBlog :exec
-INSERT INTO blog(name, description)
-VALUES ($1, $2);
+-- name: DeleteContentLink :execrows
+DELETE FROM content_links
+WHERE id=$1 and content_id=$2;
+
+-- name: ReadContentByLink :one
+SELECT c.id, c.user_email, c.name, c.description, c.created_at, c.updated_at, l.expires_in FROM content_links l
+INNER JOIN content c ON c.id = l.content_id
+WHERE l.token_hash=$1;
+
+-- name: CreateAttachment :one
+INSERT INTO attachments(content_id, user_email, file_name, content_type, size, storage_key)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: ReadAttachment :one
+SELECT * FROM attachments
+WHERE id=$1 and content_id=$2;
+
+-- name: ListAttachments :many
+SELECT * FROM attachments
+WHERE content_id=$1
+ORDER BY created_at, id;
+
+-- name: DeleteAttachment :one
+DELETE FROM attachments
+WHERE id=$1 and content_id=$2
+RETURNING storage_key;
+
+-- name: ListAttachmentKeys :many
+SELECT storage_key FROM attachments
+WHERE content_id=$1;
+
+-- name: CreateBlog :one
+INSERT INTO blog(name, description, slug, author_email)
+VALUES ($1, $2, $3, $4)
+RETURNING id;
 
 -- name: ReadBlog :one
-SELECT * FROM blog
+SELECT id, name, description, slug, status, author_email, created_at, updated_at, published_at FROM blog
+WHERE id=$1;
+
+-- name: ReadBlogBySlug :one
+SELECT id, name, description, slug, status, author_email, created_at, updated_at, published_at FROM blog
+WHERE slug=$1;
+
+-- name: ListBlogs :many
+SELECT id, name, description, slug, status, author_email, created_at, updated_at, published_at FROM blog
+WHERE (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text)
+  AND (sqlc.arg(tag)::text = '' OR EXISTS (SELECT 1 FROM blog_tags bt JOIN tags t ON t.id = bt.tag_id
+                                           WHERE bt.blog_id = blog.id AND t.slug = sqlc.arg(tag)::text))
+ORDER BY coalesce(published_at, created_at) DESC, id DESC
+LIMIT sqlc.arg(limit_count) OFFSET sqlc.arg(offset_count);
+
+-- name: CountBlogs :one
+SELECT count(*) FROM blog
+WHERE (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text)
+  AND (sqlc.arg(tag)::text = '' OR EXISTS (SELECT 1 FROM blog_tags bt JOIN tags t ON t.id = bt.tag_id
+                                           WHERE bt.blog_id = blog.id AND t.slug = sqlc.arg(tag)::text));
+
+-- name: UpdateBlog :one
+UPDATE blog SET name=$2, description=$3, slug=$4, updated_at=now()
+WHERE id=$1
+RETURNING id, name, description, slug, status, author_email, created_at, updated_at, published_at;
+
+-- name: UpdateBlogStatus :one
+UPDATE blog SET status=sqlc.arg(status), updated_at=now(),
+    published_at = CASE WHEN sqlc.arg(status) = 'published' THEN coalesce(published_at, now())
+                        ELSE published_at END
+WHERE id=sqlc.arg(id)
+RETURNING id, name, description, slug, status, author_email, created_at, updated_at, published_at;
+
+-- name: DeleteBlog :execrows
+DELETE FROM blog
 WHERE id=$1;
 
 -- name: ReadEmailRoleFromSessions :one
-SELECT email, role, s.expires_in
+SELECT users.id as user_id, email, role, s.expires_in, s.id as session_id, s.access_token, s.access_expires_in
 FROM users INNER JOIN sessions s on users.email = s.user_email
 WHERE s.refresh_token = $1;
+
+-- name: ListRolePermissions :many
+SELECT role, permission FROM role_permissions
+ORDER BY role, permission;
+
+-- name: ReadLoginAttempts :one
+SELECT key, failures, locked_until, expires_in FROM login_attempts
+WHERE key=$1 and expires_in > $2;
+
+-- name: IncrementLoginAttempts :one
+INSERT INTO login_attempts(key, failures, locked_until, expires_in)
+VALUES (sqlc.arg(key), 1, 0, sqlc.arg(expires_in))
+ON CONFLICT (key) DO UPDATE
+SET failures=CASE WHEN login_attempts.expires_in > sqlc.arg(now) THEN login_attempts.failures + 1 ELSE 1 END,
+    locked_until=CASE WHEN login_attempts.expires_in > sqlc.arg(now) THEN login_attempts.locked_until ELSE 0 END,
+    expires_in=GREATEST(login_attempts.expires_in, EXCLUDED.expires_in)
+RETURNING key, failures, locked_until, expires_in;
+
+-- name: LockLoginAttempts :exec
+UPDATE login_attempts
+SET locked_until=GREATEST(locked_until, sqlc.arg(locked_until)), expires_in=GREATEST(expires_in, sqlc.arg(locked_until))
+WHERE key=sqlc.arg(key);
+
+-- name: DeleteLoginAttempts :exec
+DELETE FROM login_attempts
+WHERE key=$1;
+
+-- name: DeleteExpiredLoginAttempts :exec
+DELETE FROM login_attempts
+WHERE expires_in < $1;
+
+-- name: SearchBlogs :many
+SELECT id, name, description, slug, status, author_email, created_at, updated_at, published_at,
+       ts_rank(search_vector, query)::real AS rank,
+       ts_headline('russian', coalesce(description, ''), query,
+                   'StartSel=' || chr(1) || ', StopSel=' || chr(2) || ', MaxFragments=2, MaxWords=25, MinWords=10')::text AS snippet
+FROM blog, websearch_to_tsquery('russian', sqlc.arg(query)) query
+WHERE status = 'published' AND search_vector @@ query
+ORDER BY rank DESC, id DESC
+LIMIT sqlc.arg(limit_count) OFFSET sqlc.arg(offset_count);
+
+-- name: CountSearchBlogs :one
+SELECT count(*) FROM blog
+WHERE status = 'published' AND search_vector @@ websearch_to_tsquery('russian', sqlc.arg(query));
+
+-- name: SearchContent :many
+SELECT id, user_email, name, description, created_at, updated_at,
+       ts_rank(search_vector, query)::real AS rank,
+       ts_headline('russian', coalesce(description, ''), query,
+                   'StartSel=' || chr(1) || ', StopSel=' || chr(2) || ', MaxFragments=2, MaxWords=25, MinWords=10')::text AS snippet
+FROM content, websearch_to_tsquery('russian', sqlc.arg(query)) query
+WHERE user_email = sqlc.arg(user_email) AND search_vector @@ query
+ORDER BY rank DESC, id DESC
+LIMIT sqlc.arg(limit_count) OFFSET sqlc.arg(offset_count);
+
+-- name: CountSearchContent :one
+SELECT count(*) FROM content
+WHERE user_email = sqlc.arg(user_email) AND search_vector @@ websearch_to_tsquery('russian', sqlc.arg(query));
+
+-- name: UpsertTags :many
+INSERT INTO tags(name, slug)
+SELECT unnest(sqlc.arg(names)::text[]), unnest(sqlc.arg(slugs)::text[])
+ON CONFLICT (slug) DO UPDATE SET slug = excluded.slug
+RETURNING id, name, slug;
+
+-- name: DeleteBlogTags :exec
+DELETE FROM blog_tags
+WHERE blog_id=$1;
+
+-- name: AddBlogTags :exec
+INSERT INTO blog_tags(blog_id, tag_id)
+SELECT sqlc.arg(blog_id), unnest(sqlc.arg(tag_ids)::int[])
+ON CONFLICT DO NOTHING;
+
+-- name: ListBlogsTags :many
+SELECT bt.blog_id, t.id, t.name, t.slug FROM blog_tags bt
+JOIN tags t ON t.id = bt.tag_id
+WHERE bt.blog_id = ANY(sqlc.arg(blog_ids)::int[])
+ORDER BY t.name;
+
+-- name: ListTags :many
+SELECT t.id, t.name, t.slug, count(b.id) AS posts FROM tags t
+LEFT JOIN blog_tags bt ON bt.tag_id = t.id
+LEFT JOIN blog b ON b.id = bt.blog_id AND b.status = 'published'
+GROUP BY t.id
+ORDER BY posts DESC, t.name;
+
+-- name: CreateComment :one
+INSERT INTO comments(blog_id, parent_id, author_email, body)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ReadComment :one
+SELECT * FROM comments
+WHERE id=$1;
+
+-- name: UpdateCommentBody :one
+UPDATE comments SET body=$3, updated_at=now(),
+    status = CASE WHEN status = 'hidden' THEN status ELSE 'pending' END
+WHERE id=$1 AND author_email=$2 AND NOT is_deleted
+RETURNING *;
+
+-- name: DeleteComment :execrows
+UPDATE comments SET is_deleted=true, updated_at=now()
+WHERE id=$1 AND author_email=$2 AND NOT is_deleted;
+
+-- name: UpdateCommentStatus :one
+UPDATE comments SET status=$2, updated_at=now()
+WHERE id=$1 AND NOT is_deleted
+RETURNING *;
+
+-- name: ListBlogComments :many
+SELECT c.*, coalesce(u.name, '')::text AS author_name FROM comments c
+LEFT JOIN users u ON u.email = c.author_email
+WHERE c.blog_id = sqlc.arg(blog_id) AND c.parent_id IS NULL AND c.status = 'approved'
+  AND (NOT c.is_deleted OR EXISTS (SELECT 1 FROM comments r
+                                   WHERE r.parent_id = c.id AND r.status = 'approved' AND NOT r.is_deleted))
+ORDER BY c.created_at, c.id
+LIMIT sqlc.arg(limit_count) OFFSET sqlc.arg(offset_count);
+
+-- name: CountBlogComments :one
+SELECT count(*) FROM comments c
+WHERE c.blog_id = $1 AND c.parent_id IS NULL AND c.status = 'approved'
+  AND (NOT c.is_deleted OR EXISTS (SELECT 1 FROM comments r
+                                   WHERE r.parent_id = c.id AND r.status = 'approved' AND NOT r.is_deleted));
+
+-- name: ListCommentReplies :many
+SELECT c.*, coalesce(u.name, '')::text AS author_name FROM comments c
+LEFT JOIN users u ON u.email = c.author_email
+WHERE c.parent_id = ANY(sqlc.arg(parent_ids)::int[]) AND c.status = 'approved' AND NOT c.is_deleted
+ORDER BY c.created_at, c.id;
+
+-- name: ListCommentsByStatus :many
+SELECT * FROM comments
+WHERE status = sqlc.arg(status) AND NOT is_deleted
+ORDER BY created_at, id
+LIMIT sqlc.arg(limit_count) OFFSET sqlc.arg(offset_count);
+
+-- name: CountCommentsByStatus :one
+SELECT count(*) FROM comments
+WHERE status = $1 AND NOT is_deleted;
@@ -1,3 +1,54 @@
+-- Roles and permissions (RBAC), the mapping is loaded by the service at startup
+CREATE TABLE roles
+(
+    name        text PRIMARY KEY,
+    description text
+);
+
+CREATE TABLE permissions
+(
+    name        text PRIMARY KEY, -- resource:action, for example blog:create
+    description text
+);
+
+CREATE TABLE role_permissions
+(
+    role       text NOT NULL,
+    permission text NOT NULL,
+    PRIMARY KEY (role, permission),
+    FOREIGN KEY (role) REFERENCES roles (name) ON DELETE CASCADE,
+    FOREIGN KEY (permission) REFERENCES permissions (name) ON DELETE CASCADE
+);
+
+INSERT INTO roles(name, description)
+VALUES ('User', 'registered user'),
+       ('Admin', 'site administrator');
+
+INSERT INTO permissions(name, description)
+VALUES ('content:read', 'read own content'),
+       ('content:write', 'create and edit own content'),
+       ('blog:create', 'create blog posts'),
+       ('blog:update', 'edit blog posts and see drafts'),
+       ('blog:delete', 'delete blog posts'),
+       ('blog:publish', 'publish and archive blog posts'),
+       ('users:manage', 'manage users'),
+       ('comments:write', 'comment blog posts'),
+       ('comments:moderate', 'approve, reject and hide comments');
+
+INSERT INTO role_permissions(role, permission)
+VALUES ('User', 'content:read'),
+       ('User', 'content:write'),
+       ('User', 'comments:write'),
+       ('Admin', 'content:read'),
+       ('Admin', 'content:write'),
+       ('Admin', 'blog:create'),
+       ('Admin', 'blog:update'),
+       ('Admin', 'blog:delete'),
+       ('Admin', 'blog:publish'),
+       ('Admin', 'users:manage'),
+       ('Admin', 'comments:write'),
+       ('Admin', 'comments:moderate');
+
 -- Creation users table
 CREATE TABLE users
 (
@@ -7,7 +58,8 @@ CREATE TABLE users
     email      text NOT NULL,
     role       text NOT NULL DEFAULT 'User',
     is_deleted bool default false,
-    verifay bool NOT NULL DEFAULT false
+    verifay bool NOT NULL DEFAULT false,
+    FOREIGN KEY (role) REFERENCES roles (name) ON UPDATE CASCADE
 );
 
 create unique index emails_index
@@ -18,17 +70,48 @@ CREATE TABLE sessions
     id            serial PRIMARY KEY,
     user_email    text,
     refresh_token text,
-    access_token  text,                                            -- give token for role services
+    access_token  text,                                            -- id (jti) of the last access token
     user_agent    text,
     ip            varchar(20),
     expires_in    bigint                   NOT NULL,
     created_at    timestamp with time zone NOT NULL DEFAULT now(), -- UTC
+    access_expires_in bigint               NOT NULL DEFAULT 0,     -- expiration of the last access token
     FOREIGN KEY (user_email) REFERENCES users (email) ON DELETE SET NULL
 );
 
 create unique index refresh_token_index
     on sessions (refresh_token);
 
+-- Refresh tokens already exchanged within the session (token family),
+-- presenting one of them again means that the token was stolen.
+CREATE TABLE retired_refresh_tokens
+(
+    token      text PRIMARY KEY,
+    session_id integer                  NOT NULL,
+    user_email text,
+    retired_at timestamp with time zone NOT NULL DEFAULT now(), -- UTC
+    FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE CASCADE
+);
+
+-- Single-use tokens of password reset, only sha256 of the token is stored
+CREATE TABLE password_reset_tokens
+(
+    token_hash text PRIMARY KEY,
+    user_email text                     NOT NULL,
+    expires_in bigint                   NOT NULL,
+    used_at    timestamp with time zone,
+    created_at timestamp with time zone NOT NULL DEFAULT now(), -- UTC
+    FOREIGN KEY (user_email) REFERENCES users (email) ON DELETE CASCADE
+);
+
+-- Failed sign-in attempts per account or ip, used by the postgres lockout store
+CREATE TABLE login_attempts
+(
+    key          text PRIMARY KEY, -- account:<email> or ip:<address>
+    failures     integer NOT NULL,
+    locked_until bigint  NOT NULL DEFAULT 0,
+    expires_in   bigint  NOT NULL
+);
 
 CREATE TABLE content
 (
@@ -36,18 +119,171 @@ CREATE TABLE content
     user_email  text,
     name        text,
     description text,
+    created_at  timestamp with time zone NOT NULL DEFAULT now(), -- UTC
+    updated_at  timestamp with time zone NOT NULL DEFAULT now(), -- UTC
+    search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('russian', coalesce(description, '')), 'B')) STORED,
     FOREIGN KEY (user_email) REFERENCES users (email) ON DELETE SET NULL
 );
 
+create index content_search_index
+    on content using gin (search_vector);
+
+-- indexes of the cursor pagination
+create index content_created_at_index
+    on content (user_email, created_at, id);
+
+create index content_updated_at_index
+    on content (user_email, updated_at, id);
+
 create unique index content_name_index
     on content (user_email, name);
 
+-- Revisions of content, every create and update saves full snapshot of the content
+CREATE TABLE content_revisions
+(
+    id           serial PRIMARY KEY,
+    content_id   integer                  NOT NULL,
+    revision     integer                  NOT NULL, -- number inside the content, from 1
+    author_email text,
+    name         text,
+    description  text,
+    summary      text                     NOT NULL,
+    created_at   timestamp with time zone NOT NULL DEFAULT now(), -- UTC
+    FOREIGN KEY (content_id) REFERENCES content (id) ON DELETE CASCADE,
+    FOREIGN KEY (author_email) REFERENCES users (email) ON DELETE SET NULL
+);
+
+create unique index content_revisions_index
+    on content_revisions (content_id, revision);
+
+-- Files attached to content, the file itself is kept in the storage by storage_key
+CREATE TABLE attachments
+(
+    id           serial PRIMARY KEY,
+    content_id   integer                  NOT NULL,
+    user_email   text,                                    -- who uploaded the file
+    file_name    text                     NOT NULL,
+    content_type text                     NOT NULL,
+    size         bigint                   NOT NULL,       -- in bytes
+    storage_key  text                     NOT NULL,
+    created_at   timestamp with time zone NOT NULL DEFAULT now(), -- UTC
+    FOREIGN KEY (content_id) REFERENCES content (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_email) REFERENCES users (email) ON DELETE SET NULL
+);
+
+create unique index attachments_storage_key_index
+    on attachments (storage_key);
+
+create index attachments_content_index
+    on attachments (content_id, created_at);
+
+-- Access of other users to content, the owner grants read or edit permission
+CREATE TABLE content_shares
+(
+    content_id integer                  NOT NULL,
+    user_email text                     NOT NULL,
+    permission text                     NOT NULL CHECK (permission IN ('read', 'edit')),
+    created_at timestamp with time zone NOT NULL DEFAULT now(), -- UTC
+    PRIMARY KEY (content_id, user_email),
+    FOREIGN KEY (content_id) REFERENCES content (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_email) REFERENCES users (email) ON DELETE CASCADE
+);
+
+create index content_shares_user_index
+    on content_shares (user_email, created_at);
+
+-- Public read-only links to content, only sha256 of the link token is stored
+CREATE TABLE content_links
+(
+    id         serial PRIMARY KEY,
+    content_id integer                  NOT NULL,
+    token_hash text                     NOT NULL,
+    expires_in bigint                   NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(), -- UTC
+    FOREIGN KEY (content_id) REFERENCES content (id) ON DELETE CASCADE
+);
+
+create unique index content_links_token_index
+    on content_links (token_hash);
+
+create index content_links_content_index
+    on content_links (content_id);
+
 CREATE TABLE blog
 (
-    id          serial PRIMARY KEY,
-    name        text,
-    description text
+    id           serial PRIMARY KEY,
+    name         text,
+    description  text,
+    slug         text                     NOT NULL,
+    status       text                     NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'published', 'archived')),
+    author_email text,
+    created_at   timestamp with time zone NOT NULL DEFAULT now(), -- UTC
+    updated_at   timestamp with time zone NOT NULL DEFAULT now(), -- UTC
+    published_at timestamp with time zone,                        -- UTC, first publication
+    search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('russian', coalesce(description, '')), 'B')) STORED,
+    FOREIGN KEY (author_email) REFERENCES users (email) ON DELETE SET NULL
 );
 
+create index blog_search_index
+    on blog using gin (search_vector);
+
+-- Tags of blog posts, slug is the normalized name
+CREATE TABLE tags
+(
+    id   serial PRIMARY KEY,
+    name text NOT NULL,
+    slug text NOT NULL
+);
+
+create unique index tags_slug_index
+    on tags (slug);
+
+CREATE TABLE blog_tags
+(
+    blog_id integer NOT NULL,
+    tag_id  integer NOT NULL,
+    PRIMARY KEY (blog_id, tag_id),
+    FOREIGN KEY (blog_id) REFERENCES blog (id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags (id) ON DELETE CASCADE
+);
+
+create index blog_tags_tag_index
+    on blog_tags (tag_id);
+
+-- Comments of blog posts, replies have parent_id, only one level of replies is allowed
+CREATE TABLE comments
+(
+    id           serial PRIMARY KEY,
+    blog_id      integer                  NOT NULL,
+    parent_id    integer,
+    author_email text,
+    body         text                     NOT NULL,
+    status       text                     NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected', 'hidden')),
+    is_deleted   boolean                  NOT NULL DEFAULT false, -- deleted by author
+    created_at   timestamp with time zone NOT NULL DEFAULT now(), -- UTC
+    updated_at   timestamp with time zone NOT NULL DEFAULT now(), -- UTC
+    FOREIGN KEY (blog_id) REFERENCES blog (id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES comments (id) ON DELETE CASCADE,
+    FOREIGN KEY (author_email) REFERENCES users (email) ON DELETE SET NULL
+);
+
+create index comments_blog_index
+    on comments (blog_id, parent_id, created_at);
+
+create index comments_status_index
+    on comments (status, created_at);
+
+create unique index blog_slug_index
+    on blog (slug);
+
+create index blog_status_index
+    on blog (status, published_at);
+
 create unique index blog_name_index
     on blog (name);
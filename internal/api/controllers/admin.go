@@ -0,0 +1,246 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Dsmit05/metida/internal/api/response"
+	"github.com/Dsmit05/metida/internal/models"
+	"github.com/Dsmit05/metida/internal/validation"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultUsersLimit = 20
+	maxUsersLimit     = 100
+)
+
+type adminServiceI interface {
+	ListUsers(ctx context.Context, search string, withDeleted bool, limit, offset int32) ([]models.User, int64, error)
+	ReadUserByID(ctx context.Context, id int32) (*models.User, error)
+	UpdateUserRole(ctx context.Context, id int32, role string) error
+	DeleteUser(ctx context.Context, adminID, id int32) (int, error)
+	RestoreUser(ctx context.Context, id int32) error
+}
+
+type rolesI interface {
+	Roles() []string
+}
+
+// AdminUsers defines the user management controller methods
+type AdminUsers struct {
+	auth  adminServiceI
+	roles rolesI
+}
+
+func NewAdminUsers(auth adminServiceI, roles rolesI) *AdminUsers {
+	return &AdminUsers{auth, roles}
+}
+
+// UserOutput information about user, password is not shown.
+type UserOutput struct {
+	ID        int32  `json:"id" example:"1"`
+	Name      string `json:"name" example:"admin"`
+	Email     string `json:"email" example:"admin@email.com"`
+	Role      string `json:"role" example:"Admin"`
+	IsDeleted bool   `json:"isDeleted" example:"false"`
+	Verified  bool   `json:"verified" example:"true"`
+}
+
+// UsersOutput page of users.
+type UsersOutput struct {
+	Users []UserOutput `json:"users"`
+	Total int64        `json:"total" example:"1"`
+}
+
+type UpdateRoleInput struct {
+	Role string `json:"role" binding:"required" example:"Admin"`
+	// roles known to rbac, the input is checked against them.
+	roles []string
+}
+
+func (o UpdateRoleInput) Validate(v *validation.Validator) {
+	v.Required("role", o.Role)
+	v.OneOf("role", o.Role, o.roles...)
+}
+
+// @Summary List users
+// @Tags admin
+// @Description Show users, search by email or name
+// @ID admin-list-users
+// @Produce json
+// @Param search query string false "part of email or name"
+// @Param deleted query bool false "show deleted users"
+// @Param limit query int false "page size, max 100"
+// @Param offset query int false "number of skipped users"
+// @Success 200 {object} response.Success{data=UsersOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /admin/users [GET]
+func (o *AdminUsers) ListUsers(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	search := c.Query("search")
+	withDeleted := c.Query("deleted") == "true"
+
+	limit, offset, err := parsePage(c, defaultUsersLimit, maxUsersLimit)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	users, total, err := o.auth.ListUsers(ctx, search, withDeleted, limit, offset)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	output := UsersOutput{Users: make([]UserOutput, 0, len(users)), Total: total}
+	for _, user := range users {
+		output.Users = append(output.Users, newUserOutput(user))
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, output, "")
+}
+
+// @Summary Show user
+// @Tags admin
+// @Description Show user by ID
+// @ID admin-show-user
+// @Produce json
+// @Param id path int true "user_id"
+// @Success 200 {object} response.Success{data=UserOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /admin/users/{id} [GET]
+func (o *AdminUsers) ShowUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := o.getUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := o.auth.ReadUserByID(ctx, id)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, newUserOutput(*user), "")
+}
+
+// @Summary Change role
+// @Tags admin
+// @Description Change role of the user, access tokens of the user are revoked
+// @ID admin-update-user-role
+// @Accept json
+// @Produce json
+// @Param id path int true "user_id"
+// @Param input body UpdateRoleInput true "new role"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/role [PUT]
+func (o *AdminUsers) UpdateUserRole(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := o.getUserID(c)
+	if !ok {
+		return
+	}
+
+	inputData := UpdateRoleInput{roles: o.roles.Roles()}
+
+	if !bindJSON(c, &inputData) {
+		return
+	}
+
+	if err := o.auth.UpdateUserRole(ctx, id, inputData.Role); err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, "", "Role changed")
+}
+
+// @Summary Delete user
+// @Tags admin
+// @Description Mark user as deleted and remove all user sessions
+// @ID admin-delete-user
+// @Produce json
+// @Param id path int true "user_id"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /admin/users/{id} [DELETE]
+func (o *AdminUsers) DeleteUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := o.getUserID(c)
+	if !ok {
+		return
+	}
+
+	value, _ := c.Get("userID")
+	adminID, _ := value.(int32)
+
+	revoked, err := o.auth.DeleteUser(ctx, adminID, id)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, gin.H{"revokedSessions": revoked}, "User deleted")
+}
+
+// @Summary Restore user
+// @Tags admin
+// @Description Remove deleted mark from the user
+// @ID admin-restore-user
+// @Produce json
+// @Param id path int true "user_id"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/restore [POST]
+func (o *AdminUsers) RestoreUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := o.getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := o.auth.RestoreUser(ctx, id); err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, "", "User restored")
+}
+
+// getUserID return user id from path.
+func (o *AdminUsers) getUserID(c *gin.Context) (int32, bool) {
+	return getPathID(c, "id")
+}
+
+func newUserOutput(user models.User) UserOutput {
+	return UserOutput{
+		ID:        user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		Role:      user.Role,
+		IsDeleted: user.IsDeleted,
+		Verified:  user.Verified,
+	}
+}
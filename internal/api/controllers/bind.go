@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"github.com/Dsmit05/metida/internal/api/response"
+	"github.com/Dsmit05/metida/internal/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// inputI body of the request, it checks own rules after binding.
+type inputI interface {
+	Validate(v *validation.Validator)
+}
+
+// bindJSON decodes the body into input and checks it, violations of binding tags
+// and of the rules of the input are answered together.
+func bindJSON(c *gin.Context, input inputI) bool {
+	return bindJSONWith(c, input, new(validation.Validator))
+}
+
+// bindJSONWith is bindJSON with the validator of the controller, e.g. with the password policy.
+func bindJSONWith(c *gin.Context, input inputI, v *validation.Validator) bool {
+	if err := c.ShouldBindJSON(input); err == nil || v.AddBindError(input, err) {
+		input.Validate(v)
+	}
+
+	if err := v.Err(); err != nil {
+		response.GinAppError(c, err)
+		return false
+	}
+
+	return true
+}
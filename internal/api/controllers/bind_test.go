@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Dsmit05/metida/internal/api/response"
+	"github.com/Dsmit05/metida/internal/apperr"
+	"github.com/Dsmit05/metida/internal/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestBindJSON(t *testing.T) {
+	logger.ZapLog = zap.NewNop()
+	gin.SetMode(gin.TestMode)
+
+	var tests = []struct {
+		name       string
+		body       string
+		wantOK     bool
+		wantFields []string
+	}{
+		{name: "Case-1: valid input",
+			body: `{"username": "Ivan", "email": "ivan@mail.com", "password": "Q@werty1_23"}`, wantOK: true},
+		{name: "Case-2: missing field and broken rules are reported together",
+			body:       `{"username": "Iv", "email": "ivan"}`,
+			wantFields: []string{"password", "username", "email"}},
+		{name: "Case-3: broken json", body: `{"username": `, wantFields: []string{"body"}},
+		{name: "Case-4: wrong type", body: `{"username": 1}`, wantFields: []string{"username"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/sign-up", strings.NewReader(tt.body))
+			c.Request.Header.Set("Accept", response.MIMEProblemJSON)
+
+			var input CreateUserInput
+			if ok := bindJSON(c, &input); ok != tt.wantOK {
+				t.Fatalf("bindJSON() = %v, want %v", ok, tt.wantOK)
+			}
+			if tt.wantOK {
+				return
+			}
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("bindJSON() status = %v, want %v", w.Code, http.StatusBadRequest)
+			}
+
+			var problem struct {
+				InvalidParams []apperr.FieldError `json:"invalidParams"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+
+			var fields []string
+			for _, violation := range problem.InvalidParams {
+				fields = append(fields, violation.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.wantFields, ",") {
+				t.Errorf("bindJSON() fields = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
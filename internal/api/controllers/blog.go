@@ -1,101 +1,472 @@
 package controllers
 
 import (
-	"fmt"
+	"context"
 	"net/http"
-	"strconv"
+	"time"
 
+	"github.com/Dsmit05/metida/internal/consts"
 	"github.com/Dsmit05/metida/internal/models"
+	"github.com/Dsmit05/metida/internal/service"
 
-	"github.com/Dsmit05/metida/internal/api/middlewares"
 	"github.com/Dsmit05/metida/internal/api/response"
-	"github.com/Dsmit05/metida/internal/consts"
+	"github.com/Dsmit05/metida/internal/validation"
 	"github.com/gin-gonic/gin"
 )
 
-type siteRepositoryI interface {
-	CreatBlog(name string, description string) error
-	ReadBlog(id int32) (*models.Blog, error)
+const (
+	defaultBlogsLimit = 10
+	maxBlogsLimit     = 50
+)
+
+type blogServiceI interface {
+	Create(ctx context.Context, authorEmail string, input service.BlogInput) (*models.Blog, error)
+	Update(ctx context.Context, id int32, input service.BlogInput) (*models.Blog, error)
+	ListPublished(ctx context.Context, tag string, limit, offset int32) ([]models.Blog, int64, error)
+	List(ctx context.Context, status, tag string, limit, offset int32) ([]models.Blog, int64, error)
+	Search(ctx context.Context, query string, limit, offset int32) ([]models.BlogSearchResult, int64, error)
+	Read(ctx context.Context, id int32) (*models.Blog, error)
+	ReadPublished(ctx context.Context, id int32) (*models.Blog, error)
+	ReadPublishedBySlug(ctx context.Context, slug string) (*models.Blog, error)
+	SetStatus(ctx context.Context, id int32, status string) (*models.Blog, error)
+	Delete(ctx context.Context, id int32) error
+	ListTags(ctx context.Context) ([]models.Tag, error)
 }
 
 // SiteBlog defines the blog controller methods
 type SiteBlog struct {
-	db siteRepositoryI
+	blog blogServiceI
 }
 
-func NewSiteBlog(db siteRepositoryI) *SiteBlog {
-	return &SiteBlog{db}
+func NewSiteBlog(blog blogServiceI) *SiteBlog {
+	return &SiteBlog{blog}
 }
 
+// CreateBlogInput slug is made from the name if it is empty, tags are replaced by the passed list.
 type CreateBlogInput struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description" binding:"required"`
+	Name        string   `json:"name" binding:"required" example:"First post"`
+	Slug        string   `json:"slug" example:"first-post"`
+	Description string   `json:"description" binding:"required" example:"Text of the post..."`
+	Tags        []string `json:"tags" example:"Go,PostgreSQL"`
+}
+
+// Validate slug and tags are checked by the service, when they are made.
+func (o CreateBlogInput) Validate(v *validation.Validator) {
+	v.Required("name", o.Name)
+	v.Required("description", o.Description)
+}
+
+// BlogOutput information about blog post.
+type BlogOutput struct {
+	ID          int32       `json:"id" example:"1"`
+	Name        string      `json:"name" example:"First post"`
+	Slug        string      `json:"slug" example:"first-post"`
+	Description string      `json:"description" example:"Text of the post..."`
+	Status      string      `json:"status" example:"published"`
+	Author      string      `json:"author,omitempty" example:"admin@email.com"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+	PublishedAt *time.Time  `json:"publishedAt,omitempty"`
+	Tags        []TagOutput `json:"tags"`
+}
+
+// TagOutput tag of blog posts, posts is shown only in the list of tags.
+type TagOutput struct {
+	Name  string `json:"name" example:"PostgreSQL"`
+	Slug  string `json:"slug" example:"postgresql"`
+	Posts int64  `json:"posts,omitempty" example:"3"`
+}
+
+// BlogsOutput page of blog posts.
+type BlogsOutput struct {
+	Blogs []BlogOutput `json:"blogs"`
+	Total int64        `json:"total" example:"1"`
+}
+
+// BlogSearchOutput found blog post, snippet is escaped html with found words in <mark> tags.
+type BlogSearchOutput struct {
+	BlogOutput
+	Rank    float32 `json:"rank" example:"0.6"`
+	Snippet string  `json:"snippet" example:"first <mark>post</mark> of the blog"`
+}
+
+// BlogsSearchOutput page of found blog posts.
+type BlogsSearchOutput struct {
+	Blogs []BlogSearchOutput `json:"blogs"`
+	Total int64              `json:"total" example:"1"`
 }
 
 // @Summary CreateBlog
 // @Tags blog
-// @Description Create Blog
+// @Description Create Blog as draft
 // @ID protected-create-blog
 // @Accept json
 // @Produce json
 // @Param input body CreateBlogInput true "credentials"
-// @Success 200 {string} string "data"
+// @Success 200 {object} response.Success
 // @Failure 400 {object} response.Error
-// @Failure 403 {object} string "error"
+// @Failure 403 {object} response.Error
+// @Failure 409 {object} response.Error
 // @Security ApiKeyAuth
 // @Router /lk/blog [POST]
 func (o *SiteBlog) CreateBlog(c *gin.Context) {
-	// Check role
-	if err := middlewares.CheckAccessRights(c, consts.RoleAdmin); err != nil {
-		response.GinError(c, http.StatusForbidden, response.CodeUnknownUser,
-			"You has no enough rights for access to resource.", err)
-		return
-	}
+	ctx := c.Request.Context()
 
 	var inputData CreateBlogInput
 
-	if err := c.ShouldBindJSON(&inputData); err != nil {
-		response.GinError(c, http.StatusBadRequest, response.CodeInvalidParams, "bad data, try again", err)
+	if !bindJSON(c, &inputData) {
 		return
 	}
 
-	err := o.db.CreatBlog(inputData.Name, inputData.Description)
+	blog, err := o.blog.Create(ctx, c.GetString("email"), newBlogInput(inputData))
 	if err != nil {
-		response.GinError(c, http.StatusUnauthorized, response.CodeBadRequest, err.Error(), err)
+		response.GinAppError(c, err)
 		return
 	}
 
-	response.GinSuccess(c, http.StatusOK, response.CodeOk, "", "Blog created")
+	response.GinSuccess(c, http.StatusOK, response.CodeOk,
+		gin.H{"id": blog.ID, "slug": blog.Slug, "tags": newTagOutputs(blog.Tags)}, "Blog created")
+}
+
+// @Summary List Blogs
+// @Tags blog
+// @Description Show published blog posts, newest first
+// @ID list-blogs
+// @Produce json
+// @Param tag query string false "tag slug"
+// @Param limit query int false "page size, max 50"
+// @Param offset query int false "number of skipped posts"
+// @Success 200 {object} response.Success{data=BlogsOutput}
+// @Failure 400 {object} response.Error
+// @Router /blog [GET]
+func (o *SiteBlog) ListBlogs(c *gin.Context) {
+	o.listBlogs(c, consts.BlogStatusPublished)
 }
 
 // @Summary ShowBlog
 // @Tags blog
-// @Description Show Blog by ID
+// @Description Show published Blog by ID
 // @ID show-blog
 // @Accept json
 // @Produce json
 // @Param id path int true "blog_id"
-// @Success 200 {object} models.Blog
+// @Success 200 {object} response.Success{data=BlogOutput}
 // @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
 // @Router /blog/{id} [GET]
 func (o *SiteBlog) ShowBlog(c *gin.Context) {
-	blogId := c.Param("id")
-	if blogId == "" {
-		err := fmt.Errorf("blog not selected")
-		response.GinError(c, http.StatusBadRequest, response.CodeBadRequest, "you need to select the content", err)
+	id, ok := o.getBlogID(c)
+	if !ok {
+		return
+	}
+
+	blog, err := o.blog.ReadPublished(c.Request.Context(), id)
+	o.showBlog(c, blog, err)
+}
+
+// @Summary SearchBlogs
+// @Tags blog
+// @Description Full-text search of published blog posts, most relevant first
+// @ID search-blogs
+// @Produce json
+// @Param q query string true "search query, supports quotes, or and -word"
+// @Param limit query int false "page size, max 50"
+// @Param offset query int false "number of skipped posts"
+// @Success 200 {object} response.Success{data=BlogsSearchOutput}
+// @Failure 400 {object} response.Error
+// @Router /blog/search [GET]
+func (o *SiteBlog) SearchBlogs(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	params, err := parseSearchParams(c)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	blogs, total, err := o.blog.Search(ctx, params.query, params.limit, params.offset)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	output := BlogsSearchOutput{Blogs: make([]BlogSearchOutput, 0, len(blogs)), Total: total}
+	for _, blog := range blogs {
+		output.Blogs = append(output.Blogs, BlogSearchOutput{
+			BlogOutput: newBlogOutput(blog.Blog),
+			Rank:       blog.Rank,
+			Snippet:    blog.Snippet,
+		})
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, output, "")
+}
+
+// @Summary ShowBlogBySlug
+// @Tags blog
+// @Description Show published Blog by slug
+// @ID show-blog-by-slug
+// @Produce json
+// @Param slug path string true "blog slug"
+// @Success 200 {object} response.Success{data=BlogOutput}
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Router /blog/slug/{slug} [GET]
+func (o *SiteBlog) ShowBlogBySlug(c *gin.Context) {
+	blog, err := o.blog.ReadPublishedBySlug(c.Request.Context(), c.Param("slug"))
+	o.showBlog(c, blog, err)
+}
+
+// @Summary List all Blogs
+// @Tags blog
+// @Description Show blog posts with any status, for editors
+// @ID protected-list-blogs
+// @Produce json
+// @Param status query string false "draft, published or archived"
+// @Param tag query string false "tag slug"
+// @Param limit query int false "page size, max 50"
+// @Param offset query int false "number of skipped posts"
+// @Success 200 {object} response.Success{data=BlogsOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/blog [GET]
+func (o *SiteBlog) ListAllBlogs(c *gin.Context) {
+	o.listBlogs(c, c.Query("status"))
+}
+
+// @Summary Show any Blog
+// @Tags blog
+// @Description Show Blog by ID with any status, for editors
+// @ID protected-show-blog
+// @Produce json
+// @Param id path int true "blog_id"
+// @Success 200 {object} response.Success{data=BlogOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/blog/{id} [GET]
+func (o *SiteBlog) ShowAnyBlog(c *gin.Context) {
+	id, ok := o.getBlogID(c)
+	if !ok {
+		return
+	}
+
+	blog, err := o.blog.Read(c.Request.Context(), id)
+	o.showBlog(c, blog, err)
+}
+
+// @Summary UpdateBlog
+// @Tags blog
+// @Description Replace name, slug and description of the Blog
+// @ID protected-update-blog
+// @Accept json
+// @Produce json
+// @Param id path int true "blog_id"
+// @Param input body CreateBlogInput true "new post"
+// @Success 200 {object} response.Success{data=BlogOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/blog/{id} [PUT]
+func (o *SiteBlog) UpdateBlog(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := o.getBlogID(c)
+	if !ok {
+		return
+	}
+
+	var inputData CreateBlogInput
+
+	if !bindJSON(c, &inputData) {
+		return
+	}
+
+	blog, err := o.blog.Update(ctx, id, newBlogInput(inputData))
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, newBlogOutput(*blog), "Blog updated")
+}
+
+// @Summary PublishBlog
+// @Tags blog
+// @Description Publish Blog, it becomes visible to everyone
+// @ID protected-publish-blog
+// @Produce json
+// @Param id path int true "blog_id"
+// @Success 200 {object} response.Success{data=BlogOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/blog/{id}/publish [POST]
+func (o *SiteBlog) PublishBlog(c *gin.Context) {
+	o.setBlogStatus(c, consts.BlogStatusPublished, "Blog published")
+}
+
+// @Summary ArchiveBlog
+// @Tags blog
+// @Description Archive Blog, it is hidden from everyone except editors
+// @ID protected-archive-blog
+// @Produce json
+// @Param id path int true "blog_id"
+// @Success 200 {object} response.Success{data=BlogOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/blog/{id}/archive [POST]
+func (o *SiteBlog) ArchiveBlog(c *gin.Context) {
+	o.setBlogStatus(c, consts.BlogStatusArchived, "Blog archived")
+}
+
+// @Summary UnpublishBlog
+// @Tags blog
+// @Description Return Blog to drafts
+// @ID protected-unpublish-blog
+// @Produce json
+// @Param id path int true "blog_id"
+// @Success 200 {object} response.Success{data=BlogOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/blog/{id}/unpublish [POST]
+func (o *SiteBlog) UnpublishBlog(c *gin.Context) {
+	o.setBlogStatus(c, consts.BlogStatusDraft, "Blog moved to drafts")
+}
+
+// @Summary DeleteBlog
+// @Tags blog
+// @Description Delete Blog
+// @ID protected-delete-blog
+// @Produce json
+// @Param id path int true "blog_id"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/blog/{id} [DELETE]
+func (o *SiteBlog) DeleteBlog(c *gin.Context) {
+	id, ok := o.getBlogID(c)
+	if !ok {
+		return
+	}
+
+	if err := o.blog.Delete(c.Request.Context(), id); err != nil {
+		response.GinAppError(c, err)
+		return
 	}
 
-	id, err := strconv.Atoi(blogId)
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, "", "Blog deleted")
+}
+
+// @Summary ListTags
+// @Tags blog
+// @Description Show all tags with number of published posts
+// @ID list-tags
+// @Produce json
+// @Success 200 {object} response.Success{data=[]TagOutput}
+// @Failure 400 {object} response.Error
+// @Router /tags [GET]
+func (o *SiteBlog) ListTags(c *gin.Context) {
+	tags, err := o.blog.ListTags(c.Request.Context())
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, newTagOutputs(tags), "")
+}
+
+func (o *SiteBlog) listBlogs(c *gin.Context, status string) {
+	ctx := c.Request.Context()
+
+	limit, offset, err := parsePage(c, defaultBlogsLimit, maxBlogsLimit)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	blogs, total, err := o.blog.List(ctx, status, c.Query("tag"), limit, offset)
 	if err != nil {
-		err = fmt.Errorf("blog not selected")
-		response.GinError(c, http.StatusBadRequest, response.CodeBadRequest, "input content number", err)
+		response.GinAppError(c, err)
+		return
+	}
+
+	output := BlogsOutput{Blogs: make([]BlogOutput, 0, len(blogs)), Total: total}
+	for _, blog := range blogs {
+		output.Blogs = append(output.Blogs, newBlogOutput(blog))
 	}
 
-	blog, err := o.db.ReadBlog(int32(id))
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, output, "")
+}
+
+func (o *SiteBlog) showBlog(c *gin.Context, blog *models.Blog, err error) {
 	if err != nil {
-		response.GinError(c, http.StatusBadRequest, response.CodeDBError, err.Error(), err)
+		response.GinAppError(c, err)
 		return
 	}
 
-	response.GinSuccess(c, http.StatusOK, response.CodeOk, blog, "")
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, newBlogOutput(*blog), "")
+}
+
+func (o *SiteBlog) setBlogStatus(c *gin.Context, status, desc string) {
+	id, ok := o.getBlogID(c)
+	if !ok {
+		return
+	}
+
+	blog, err := o.blog.SetStatus(c.Request.Context(), id, status)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, newBlogOutput(*blog), desc)
+}
+
+// getBlogID return blog id from path.
+func (o *SiteBlog) getBlogID(c *gin.Context) (int32, bool) {
+	return getPathID(c, "id")
+}
+
+func newBlogInput(inputData CreateBlogInput) service.BlogInput {
+	return service.BlogInput{
+		Name:        inputData.Name,
+		Slug:        inputData.Slug,
+		Description: inputData.Description,
+		Tags:        inputData.Tags,
+	}
+}
+
+func newBlogOutput(blog models.Blog) BlogOutput {
+	return BlogOutput{
+		ID:          blog.ID,
+		Name:        blog.Name,
+		Slug:        blog.Slug,
+		Description: blog.Description,
+		Status:      blog.Status,
+		Author:      blog.AuthorEmail,
+		CreatedAt:   blog.CreatedAt,
+		UpdatedAt:   blog.UpdatedAt,
+		PublishedAt: blog.PublishedAt,
+		Tags:        newTagOutputs(blog.Tags),
+	}
+}
+
+func newTagOutputs(tags []models.Tag) []TagOutput {
+	output := make([]TagOutput, 0, len(tags))
+	for _, tag := range tags {
+		output = append(output, TagOutput{Name: tag.Name, Slug: tag.Slug, Posts: tag.Posts})
+	}
+
+	return output
 }
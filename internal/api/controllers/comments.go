@@ -0,0 +1,389 @@
+package controllers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Dsmit05/metida/internal/api/response"
+	"github.com/Dsmit05/metida/internal/consts"
+	"github.com/Dsmit05/metida/internal/models"
+	"github.com/Dsmit05/metida/internal/validation"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultCommentsLimit = 20
+	maxCommentsLimit     = 100
+	maxCommentSize       = 2000
+)
+
+type commentsServiceI interface {
+	ListComments(ctx context.Context, blogID, limit, offset int32) ([]models.Comment, int64, error)
+	CreateComment(ctx context.Context, blogID, parentID int32, authorEmail, body string) (*models.Comment, error)
+	UpdateComment(ctx context.Context, id int32, authorEmail, body string) (*models.Comment, error)
+	DeleteComment(ctx context.Context, id int32, authorEmail string) error
+	ListCommentsByStatus(ctx context.Context, status string, limit, offset int32) ([]models.Comment, int64, error)
+	SetCommentStatus(ctx context.Context, id int32, status string) (*models.Comment, error)
+}
+
+// BlogComments defines the comments controller methods
+type BlogComments struct {
+	blog commentsServiceI
+}
+
+func NewBlogComments(blog commentsServiceI) *BlogComments {
+	return &BlogComments{blog}
+}
+
+// CreateCommentInput parentId is set for replies.
+type CreateCommentInput struct {
+	Body     string `json:"body" binding:"required" example:"Nice post!"`
+	ParentID int32  `json:"parentId" example:"0"`
+}
+
+func (o CreateCommentInput) Validate(v *validation.Validator) {
+	validateCommentBody(v, o.Body)
+	v.Range("parentId", int64(o.ParentID), 0, math.MaxInt32)
+}
+
+type UpdateCommentInput struct {
+	Body string `json:"body" binding:"required" example:"Nice post!"`
+}
+
+func (o UpdateCommentInput) Validate(v *validation.Validator) {
+	validateCommentBody(v, o.Body)
+}
+
+// CommentOutput comment of the public thread, body of deleted comments is empty.
+type CommentOutput struct {
+	ID        int32           `json:"id" example:"1"`
+	ParentID  int32           `json:"parentId,omitempty" example:"0"`
+	Author    string          `json:"author" example:"admin"`
+	Body      string          `json:"body" example:"Nice post!"`
+	IsDeleted bool            `json:"isDeleted,omitempty" example:"false"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+	Replies   []CommentOutput `json:"replies,omitempty"`
+}
+
+// CommentsOutput page of top-level comments with replies.
+type CommentsOutput struct {
+	Comments []CommentOutput `json:"comments"`
+	Total    int64           `json:"total" example:"1"`
+}
+
+// ModerationCommentOutput comment in the moderation queue.
+type ModerationCommentOutput struct {
+	ID          int32     `json:"id" example:"1"`
+	BlogID      int32     `json:"blogId" example:"1"`
+	ParentID    int32     `json:"parentId,omitempty" example:"0"`
+	AuthorEmail string    `json:"authorEmail" example:"user@email.com"`
+	Body        string    `json:"body" example:"Nice post!"`
+	Status      string    `json:"status" example:"pending"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// ModerationCommentsOutput page of the moderation queue.
+type ModerationCommentsOutput struct {
+	Comments []ModerationCommentOutput `json:"comments"`
+	Total    int64                     `json:"total" example:"1"`
+}
+
+// @Summary ListComments
+// @Tags comments
+// @Description Show approved comments of the published post, oldest first, replies are nested
+// @ID list-comments
+// @Produce json
+// @Param id path int true "blog_id"
+// @Param limit query int false "number of top-level comments, max 100"
+// @Param offset query int false "number of skipped top-level comments"
+// @Success 200 {object} response.Success{data=CommentsOutput}
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Router /blog/{id}/comments [GET]
+func (o *BlogComments) ListComments(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	blogID, ok := o.getBlogID(c)
+	if !ok {
+		return
+	}
+
+	limit, offset, err := parsePage(c, defaultCommentsLimit, maxCommentsLimit)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	comments, total, err := o.blog.ListComments(ctx, blogID, limit, offset)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	output := CommentsOutput{Comments: make([]CommentOutput, 0, len(comments)), Total: total}
+	for _, comment := range comments {
+		output.Comments = append(output.Comments, newCommentOutput(comment))
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, output, "")
+}
+
+// @Summary CreateComment
+// @Tags comments
+// @Description Comment the published post or reply to a top-level comment, the comment waits for moderation
+// @ID protected-create-comment
+// @Accept json
+// @Produce json
+// @Param id path int true "blog_id"
+// @Param input body CreateCommentInput true "comment"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/blog/{id}/comments [POST]
+func (o *BlogComments) CreateComment(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	blogID, ok := o.getBlogID(c)
+	if !ok {
+		return
+	}
+
+	var inputData CreateCommentInput
+
+	if !bindJSON(c, &inputData) {
+		return
+	}
+
+	comment, err := o.blog.CreateComment(ctx, blogID, inputData.ParentID, c.GetString("email"), inputData.Body)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk,
+		gin.H{"id": comment.ID, "status": comment.Status}, "Comment sent to moderation")
+}
+
+// @Summary UpdateComment
+// @Tags comments
+// @Description Change text of own comment, the comment goes back to moderation
+// @ID protected-update-comment
+// @Accept json
+// @Produce json
+// @Param id path int true "comment_id"
+// @Param input body UpdateCommentInput true "new text"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/comments/{id} [PUT]
+func (o *BlogComments) UpdateComment(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := o.getCommentID(c)
+	if !ok {
+		return
+	}
+
+	var inputData UpdateCommentInput
+
+	if !bindJSON(c, &inputData) {
+		return
+	}
+
+	comment, err := o.blog.UpdateComment(ctx, id, c.GetString("email"), inputData.Body)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk,
+		gin.H{"id": comment.ID, "status": comment.Status}, "Comment updated")
+}
+
+// @Summary DeleteComment
+// @Tags comments
+// @Description Delete own comment, replies to it are kept
+// @ID protected-delete-comment
+// @Produce json
+// @Param id path int true "comment_id"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/comments/{id} [DELETE]
+func (o *BlogComments) DeleteComment(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := o.getCommentID(c)
+	if !ok {
+		return
+	}
+
+	if err := o.blog.DeleteComment(ctx, id, c.GetString("email")); err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, "", "Comment deleted")
+}
+
+// @Summary Moderation queue
+// @Tags comments
+// @Description Show comments with status, oldest first
+// @ID admin-list-comments
+// @Produce json
+// @Param status query string false "pending, approved, rejected or hidden" default(pending)
+// @Param limit query int false "page size, max 100"
+// @Param offset query int false "number of skipped comments"
+// @Success 200 {object} response.Success{data=ModerationCommentsOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /admin/comments [GET]
+func (o *BlogComments) ModerationQueue(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	limit, offset, err := parsePage(c, defaultCommentsLimit, maxCommentsLimit)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	status := c.DefaultQuery("status", consts.CommentStatusPending)
+
+	comments, total, err := o.blog.ListCommentsByStatus(ctx, status, limit, offset)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	output := ModerationCommentsOutput{Comments: make([]ModerationCommentOutput, 0, len(comments)), Total: total}
+	for _, comment := range comments {
+		output.Comments = append(output.Comments, newModerationCommentOutput(comment))
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, output, "")
+}
+
+// @Summary ApproveComment
+// @Tags comments
+// @Description Approve comment, it becomes visible to everyone
+// @ID admin-approve-comment
+// @Produce json
+// @Param id path int true "comment_id"
+// @Success 200 {object} response.Success{data=ModerationCommentOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /admin/comments/{id}/approve [POST]
+func (o *BlogComments) ApproveComment(c *gin.Context) {
+	o.setCommentStatus(c, consts.CommentStatusApproved, "Comment approved")
+}
+
+// @Summary RejectComment
+// @Tags comments
+// @Description Reject comment, the author can edit it and send to moderation again
+// @ID admin-reject-comment
+// @Produce json
+// @Param id path int true "comment_id"
+// @Success 200 {object} response.Success{data=ModerationCommentOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /admin/comments/{id}/reject [POST]
+func (o *BlogComments) RejectComment(c *gin.Context) {
+	o.setCommentStatus(c, consts.CommentStatusRejected, "Comment rejected")
+}
+
+// @Summary HideComment
+// @Tags comments
+// @Description Hide comment, editing by the author does not return it to moderation
+// @ID admin-hide-comment
+// @Produce json
+// @Param id path int true "comment_id"
+// @Success 200 {object} response.Success{data=ModerationCommentOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /admin/comments/{id}/hide [POST]
+func (o *BlogComments) HideComment(c *gin.Context) {
+	o.setCommentStatus(c, consts.CommentStatusHidden, "Comment hidden")
+}
+
+func (o *BlogComments) setCommentStatus(c *gin.Context, status, desc string) {
+	ctx := c.Request.Context()
+
+	id, ok := o.getCommentID(c)
+	if !ok {
+		return
+	}
+
+	comment, err := o.blog.SetCommentStatus(ctx, id, status)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, newModerationCommentOutput(*comment), desc)
+}
+
+// getBlogID return id of the post from path.
+func (o *BlogComments) getBlogID(c *gin.Context) (int32, bool) {
+	return getPathID(c, "id")
+}
+
+// getCommentID return comment id from path.
+func (o *BlogComments) getCommentID(c *gin.Context) (int32, bool) {
+	return getPathID(c, "id")
+}
+
+// validateCommentBody the text is trimmed before it is saved, so it is checked trimmed.
+func validateCommentBody(v *validation.Validator, body string) {
+	body = strings.TrimSpace(body)
+	v.Required("body", body)
+	v.Length("body", body, 1, maxCommentSize)
+}
+
+func newCommentOutput(comment models.Comment) CommentOutput {
+	output := CommentOutput{
+		ID:        comment.ID,
+		ParentID:  comment.ParentID,
+		Author:    comment.AuthorName,
+		Body:      comment.Body,
+		IsDeleted: comment.IsDeleted,
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+	}
+
+	for _, reply := range comment.Replies {
+		output.Replies = append(output.Replies, newCommentOutput(reply))
+	}
+
+	return output
+}
+
+func newModerationCommentOutput(comment models.Comment) ModerationCommentOutput {
+	return ModerationCommentOutput{
+		ID:          comment.ID,
+		BlogID:      comment.BlogID,
+		ParentID:    comment.ParentID,
+		AuthorEmail: comment.AuthorEmail,
+		Body:        comment.Body,
+		Status:      comment.Status,
+		CreatedAt:   comment.CreatedAt,
+		UpdatedAt:   comment.UpdatedAt,
+	}
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Dsmit05/metida/internal/validation"
+)
+
+func TestValidateCommentBody(t *testing.T) {
+	var tests = []struct {
+		name     string
+		body     string
+		wantRule string
+	}{
+		{name: "Case-1: text with spaces", body: "  Nice post!\n"},
+		{name: "Case-2: empty text", body: " \n ", wantRule: validation.RuleRequired},
+		{name: "Case-3: too long text", body: strings.Repeat("я", maxCommentSize+1), wantRule: validation.RuleLength},
+		{name: "Case-4: spaces are not counted", body: strings.Repeat("я", maxCommentSize) + "  "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := new(validation.Validator)
+			validateCommentBody(v, tt.body)
+
+			violations := v.Violations()
+			if tt.wantRule == "" {
+				if len(violations) != 0 {
+					t.Errorf("validateCommentBody() = %+v, want no violations", violations)
+				}
+				return
+			}
+
+			if len(violations) != 1 || violations[0].Field != "body" || violations[0].Rule != tt.wantRule {
+				t.Errorf("validateCommentBody() = %+v, want rule %v", violations, tt.wantRule)
+			}
+		})
+	}
+}
@@ -1,28 +1,43 @@
 package controllers
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
-	"strconv"
+	"time"
 
 	"github.com/Dsmit05/metida/internal/models"
+	"github.com/Dsmit05/metida/internal/service"
 
 	"github.com/Dsmit05/metida/internal/api/response"
+	"github.com/Dsmit05/metida/internal/validation"
 	"github.com/gin-gonic/gin"
 )
 
-type contentRepositoryI interface {
-	CreatContent(email string, name string, description string) error
-	ReadContent(email string, id int32) (*models.Content, error)
+const (
+	defaultContentLimit = 20
+	maxContentLimit     = 100
+)
+
+type contentServiceI interface {
+	Create(ctx context.Context, email, name, description string) (int32, error)
+	List(ctx context.Context, email string, filter models.ContentFilter) ([]models.Content, bool, error)
+	Search(ctx context.Context, email, query string, limit, offset int32) ([]models.ContentSearchResult, int64, error)
+	Read(ctx context.Context, email string, id int32) (*models.Content, error)
+	Update(ctx context.Context, email string, id int32, name, description string) (*models.Content, error)
+	Patch(ctx context.Context, email string, id int32, patch service.ContentPatch) (*models.Content, error)
+	Delete(ctx context.Context, email string, id int32) error
 }
 
 // UserContent defines the content controller methods
 type UserContent struct {
-	db contentRepositoryI
+	content contentServiceI
 }
 
-func NewWallEditorials(db contentRepositoryI) *UserContent {
-	return &UserContent{db}
+func NewWallEditorials(content contentServiceI) *UserContent {
+	return &UserContent{content}
 }
 
 type CreateContentInput struct {
@@ -30,6 +45,53 @@ type CreateContentInput struct {
 	Description string `json:"description" binding:"required" example:"New content..."`
 }
 
+func (o CreateContentInput) Validate(v *validation.Validator) {
+	v.Required("name", o.Name)
+	v.Required("description", o.Description)
+}
+
+// PatchContentInput only passed fields are changed.
+type PatchContentInput struct {
+	Name        *string `json:"name" example:"My First Content"`
+	Description *string `json:"description" example:"New content..."`
+}
+
+// Validate only passed fields are checked.
+func (o PatchContentInput) Validate(v *validation.Validator) {
+	if o.Name != nil {
+		v.Required("name", *o.Name)
+	}
+}
+
+// ContentOutput information about user content.
+type ContentOutput struct {
+	ID          int32     `json:"id" example:"1"`
+	Name        string    `json:"name" example:"My First Content"`
+	Description string    `json:"description" example:"New content..."`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	Access      string    `json:"access,omitempty" example:"owner"`
+}
+
+// ContentPageOutput page of content, nextCursor is empty on the last page.
+type ContentPageOutput struct {
+	Items      []ContentOutput `json:"items"`
+	NextCursor string          `json:"nextCursor" example:"eyJpZCI6MX0"`
+}
+
+// ContentSearchOutput found content, snippet is escaped html with found words in <mark> tags.
+type ContentSearchOutput struct {
+	ContentOutput
+	Rank    float32 `json:"rank" example:"0.6"`
+	Snippet string  `json:"snippet" example:"New <mark>content</mark>..."`
+}
+
+// ContentSearchPageOutput page of found content.
+type ContentSearchPageOutput struct {
+	Items []ContentSearchOutput `json:"items"`
+	Total int64                 `json:"total" example:"1"`
+}
+
 // @Summary CreateContent
 // @Tags content
 // @Description Create Content
@@ -39,65 +101,320 @@ type CreateContentInput struct {
 // @Param input body CreateContentInput true "credentials"
 // @Success 200 {object} response.Success
 // @Failure 400 {string} string "error"
-// @Failure 417 {object} response.Error
+// @Failure 401 {object} response.Error
 // @Security ApiKeyAuth
 // @Router /lk/content [POST]
 func (o *UserContent) CreateContent(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	var inputData CreateContentInput
 
-	if err := c.ShouldBindJSON(&inputData); err != nil {
-		response.GinError(c, http.StatusBadRequest, response.CodeInvalidParams, "bad data, try again", nil)
+	if !bindJSON(c, &inputData) {
 		return
 	}
 
-	email, ok := c.Get("email")
-	if !ok {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "user without email"})
+	id, err := o.content.Create(ctx, c.GetString("email"), inputData.Name, inputData.Description)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, gin.H{"id": id}, "Content created")
+}
+
+// @Summary List Content
+// @Tags content
+// @Description Show content of the user page by page
+// @ID protected-list-content
+// @Produce json
+// @Param name query string false "part of the content name"
+// @Param sort query string false "created_at, updated_at or name" default(created_at)
+// @Param order query string false "asc or desc" default(desc)
+// @Param limit query int false "page size, max 100"
+// @Param cursor query string false "nextCursor from the previous page"
+// @Success 200 {object} response.Success{data=ContentPageOutput}
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/content [GET]
+func (o *UserContent) ListContent(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	filter, err := parseContentFilter(c)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
 	}
 
-	if err := o.db.CreatContent(email.(string), inputData.Name, inputData.Description); err != nil {
-		response.GinError(c, http.StatusBadRequest, response.CodeBadRequest, err.Error(), err)
+	contents, more, err := o.content.List(ctx, c.GetString("email"), filter)
+	if err != nil {
+		response.GinAppError(c, err)
 		return
 	}
 
-	response.GinSuccess(c, http.StatusOK, response.CodeOk, "", "Content created")
+	output := ContentPageOutput{Items: make([]ContentOutput, 0, len(contents))}
+
+	if more {
+		output.NextCursor = encodeContentCursor(contents[len(contents)-1], filter.SortBy)
+	}
+
+	for _, content := range contents {
+		output.Items = append(output.Items, newContentOutput(content))
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, output, "")
+}
+
+// @Summary Search Content
+// @Tags content
+// @Description Full-text search in content of the user, most relevant first
+// @ID protected-search-content
+// @Produce json
+// @Param q query string true "search query, supports quotes, or and -word"
+// @Param limit query int false "page size, max 50"
+// @Param offset query int false "number of skipped items"
+// @Success 200 {object} response.Success{data=ContentSearchPageOutput}
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/content/search [GET]
+func (o *UserContent) SearchContent(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	params, err := parseSearchParams(c)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	contents, total, err := o.content.Search(ctx, c.GetString("email"), params.query, params.limit, params.offset)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	output := ContentSearchPageOutput{Items: make([]ContentSearchOutput, 0, len(contents)), Total: total}
+	for _, content := range contents {
+		output.Items = append(output.Items, ContentSearchOutput{
+			ContentOutput: newContentOutput(content.Content),
+			Rank:          content.Rank,
+			Snippet:       content.Snippet,
+		})
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, output, "")
 }
 
 // @Summary Show Content
 // @Tags content
-// @Description Show Content
+// @Description Show own or shared Content
 // @ID protected-show-content
 // @Accept json
 // @Produce json
 // @Param id path int true "id"
-// @Success 200 {object} models.Content
+// @Success 200 {object} response.Success{data=ContentOutput}
 // @Failure 400 {object} response.Error
-// @Failure 417 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 404 {object} response.Error
 // @Security ApiKeyAuth
 // @Router /lk/content/{id} [GET]
 func (o *UserContent) ShowContent(c *gin.Context) {
-	contentId := c.Param("id")
-	if contentId == "" {
-		err := fmt.Errorf("blog not selected")
-		response.GinError(c, http.StatusBadRequest, response.CodeBadRequest, "you need to select the content", err)
+	ctx := c.Request.Context()
+
+	id, ok := getContentID(c)
+	if !ok {
+		return
 	}
 
-	email, ok := c.Get("email")
+	content, err := o.content.Read(ctx, c.GetString("email"), id)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, newContentOutput(*content), "")
+}
+
+// @Summary Update Content
+// @Tags content
+// @Description Replace name and description of own or shared for edit content
+// @ID protected-update-content
+// @Accept json
+// @Produce json
+// @Param id path int true "id"
+// @Param input body CreateContentInput true "new content"
+// @Success 200 {object} response.Success{data=ContentOutput}
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/content/{id} [PUT]
+func (o *UserContent) UpdateContent(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := getContentID(c)
 	if !ok {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "user without email"})
+		return
 	}
 
-	id, err := strconv.Atoi(contentId)
+	var inputData CreateContentInput
+
+	if !bindJSON(c, &inputData) {
+		return
+	}
+
+	content, err := o.content.Update(ctx, c.GetString("email"), id, inputData.Name, inputData.Description)
 	if err != nil {
-		err = fmt.Errorf("blog not selected")
-		response.GinError(c, http.StatusBadRequest, response.CodeBadRequest, "input content number", err)
+		response.GinAppError(c, err)
+		return
 	}
 
-	content, err := o.db.ReadContent(email.(string), int32(id))
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, newContentOutput(*content), "Content updated")
+}
+
+// @Summary Patch Content
+// @Tags content
+// @Description Change only passed fields of own or shared for edit content
+// @ID protected-patch-content
+// @Accept json
+// @Produce json
+// @Param id path int true "id"
+// @Param input body PatchContentInput true "changed fields"
+// @Success 200 {object} response.Success{data=ContentOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/content/{id} [PATCH]
+func (o *UserContent) PatchContent(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := getContentID(c)
+	if !ok {
+		return
+	}
+
+	var inputData PatchContentInput
+
+	if !bindJSON(c, &inputData) {
+		return
+	}
+
+	content, err := o.content.Patch(ctx, c.GetString("email"), id,
+		service.ContentPatch{Name: inputData.Name, Description: inputData.Description})
 	if err != nil {
-		response.GinError(c, http.StatusBadRequest, response.CodeDBError, err.Error(), err)
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, newContentOutput(*content), "Content updated")
+}
+
+// @Summary Delete Content
+// @Tags content
+// @Description Delete Content
+// @ID protected-delete-content
+// @Produce json
+// @Param id path int true "id"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/content/{id} [DELETE]
+func (o *UserContent) DeleteContent(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := getContentID(c)
+	if !ok {
+		return
+	}
+
+	if err := o.content.Delete(ctx, c.GetString("email"), id); err != nil {
+		response.GinAppError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, content)
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, "", "Content deleted")
+}
+
+// getContentID return content id from path.
+func getContentID(c *gin.Context) (int32, bool) {
+	return getPathID(c, "id")
+}
+
+// parseContentFilter read filter of the content list from query.
+func parseContentFilter(c *gin.Context) (models.ContentFilter, error) {
+	v := new(validation.Validator)
+
+	filter := models.ContentFilter{
+		Name:   c.Query("name"),
+		SortBy: c.DefaultQuery("sort", "created_at"),
+	}
+
+	v.OneOf("sort", filter.SortBy, "created_at", "updated_at", "name")
+
+	order := c.DefaultQuery("order", "desc")
+	v.OneOf("order", order, "asc", "desc")
+	filter.SortDesc = order == "desc"
+
+	filter.Limit = int32(queryInt(c, v, "limit", defaultContentLimit, 1, maxContentLimit))
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		var err error
+		if filter.Cursor, err = decodeContentCursor(cursor); err != nil {
+			v.Add("cursor", validation.RuleType, "must be the cursor of the previous page", nil)
+		}
+	}
+
+	return filter, v.Err()
+}
+
+// encodeContentCursor the cursor is opaque for clients, it holds sort key of the last item.
+func encodeContentCursor(content models.Content, sortBy string) string {
+	cursor := models.ContentCursor{ID: content.ID}
+
+	switch sortBy {
+	case "name":
+		cursor.Name = content.Name
+	case "updated_at":
+		cursor.Time = content.UpdatedAt
+	default:
+		cursor.Time = content.CreatedAt
+	}
+
+	data, _ := json.Marshal(cursor)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeContentCursor(cursor string) (*models.ContentCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var contentCursor models.ContentCursor
+	if err = json.Unmarshal(data, &contentCursor); err != nil {
+		return nil, err
+	}
+
+	if contentCursor.ID <= 0 {
+		return nil, fmt.Errorf("cursor without id")
+	}
+
+	return &contentCursor, nil
+}
+
+func newContentOutput(content models.Content) ContentOutput {
+	return ContentOutput{
+		ID:          content.ID,
+		Name:        content.Name,
+		Description: content.Description,
+		CreatedAt:   content.CreatedAt,
+		UpdatedAt:   content.UpdatedAt,
+		Access:      content.Access,
+	}
 }
@@ -0,0 +1,401 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Dsmit05/metida/internal/api/response"
+	"github.com/Dsmit05/metida/internal/apperr"
+	"github.com/Dsmit05/metida/internal/consts"
+	"github.com/Dsmit05/metida/internal/logger"
+	"github.com/Dsmit05/metida/internal/models"
+	"github.com/Dsmit05/metida/internal/storage"
+	"github.com/Dsmit05/metida/internal/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	maxFileNameSize = 255
+	// multipartOverhead space for boundaries and headers of the multipart form around the file.
+	multipartOverhead = 1 << 20
+	// sniffSize bytes used by http.DetectContentType.
+	sniffSize = 512
+)
+
+var (
+	errFileTooLarge = apperr.New(apperr.TooLarge, "file is too large")
+	errFileIsEmpty  = apperr.Invalid(apperr.FieldError{Field: "file", Rule: validation.RuleRequired, Message: "file is empty"})
+	// errFileNotUploaded the form has no readable file.
+	errFileNotUploaded = apperr.Invalid(
+		apperr.FieldError{Field: "file", Rule: validation.RuleRequired, Message: "file is not uploaded"})
+)
+
+type filesRepositoryI interface {
+	CreateAttachment(ctx context.Context, contentID int32, email string,
+		fileName, contentType string, size int64, storageKey string) (*models.Attachment, error)
+	ReadAttachment(ctx context.Context, contentID, id int32) (*models.Attachment, error)
+	ListAttachments(ctx context.Context, contentID int32) ([]models.Attachment, error)
+	DeleteAttachment(ctx context.Context, contentID, id int32) (string, error)
+}
+
+type filesContentI interface {
+	contentAccessI
+	ReadSharedAttachment(ctx context.Context, token string, fileID int32) (*models.Attachment, error)
+}
+
+type fileStorageI interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type configFilesI interface {
+	GetAttachmentMaxSize() int64
+	GetAttachmentAllowedTypes() []string
+}
+
+// ContentFiles defines the controller methods of files attached to content
+type ContentFiles struct {
+	db      filesRepositoryI
+	content filesContentI
+	storage fileStorageI
+	cfg     configFilesI
+}
+
+func NewContentFiles(
+	db filesRepositoryI, content filesContentI, storage fileStorageI, cfg configFilesI) *ContentFiles {
+	return &ContentFiles{db: db, content: content, storage: storage, cfg: cfg}
+}
+
+// AttachmentOutput information about attached file.
+type AttachmentOutput struct {
+	ID          int32     `json:"id" example:"1"`
+	ContentID   int32     `json:"contentId" example:"1"`
+	FileName    string    `json:"fileName" example:"photo.png"`
+	ContentType string    `json:"contentType" example:"image/png"`
+	Size        int64     `json:"size" example:"1024"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// @Summary Upload File
+// @Tags files
+// @Description Attach file to own or shared for edit content, the type of the file is detected by its data
+// @ID protected-upload-file
+// @Accept mpfd
+// @Produce json
+// @Param id path int true "content_id"
+// @Param file formData file true "file"
+// @Success 200 {object} response.Success{data=AttachmentOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 413 {object} response.Error
+// @Failure 415 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/content/{id}/files [POST]
+func (o *ContentFiles) UploadFile(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	content, ok := readContentWithAccess(c, o.content, consts.ContentAccessEdit)
+	if !ok {
+		return
+	}
+
+	maxSize := o.cfg.GetAttachmentMaxSize()
+	if c.Request.ContentLength > maxSize+multipartOverhead {
+		response.GinAppError(c, errFileTooLarge)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.GinAppError(c, errFileNotUploaded)
+		return
+	}
+
+	if fileHeader.Size > maxSize {
+		response.GinAppError(c, errFileTooLarge)
+		return
+	}
+
+	if fileHeader.Size == 0 {
+		response.GinAppError(c, errFileIsEmpty)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.GinAppError(c, errFileNotUploaded)
+		return
+	}
+	defer file.Close()
+
+	contentType, err := detectContentType(file, o.cfg.GetAttachmentAllowedTypes())
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	key := fmt.Sprintf("content/%d/%s", content.ID, uuid.NewString())
+	if err = o.storage.Put(ctx, key, file, fileHeader.Size, contentType); err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	attachment, err := o.db.CreateAttachment(ctx, content.ID, c.GetString("email"),
+		cleanFileName(fileHeader.Filename), contentType, fileHeader.Size, key)
+	if err != nil {
+		o.deleteObject(ctx, key)
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, newAttachmentOutput(*attachment), "File uploaded")
+}
+
+// @Summary List Files
+// @Tags files
+// @Description Show files attached to own or shared content
+// @ID protected-list-files
+// @Produce json
+// @Param id path int true "content_id"
+// @Success 200 {object} response.Success{data=[]AttachmentOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/content/{id}/files [GET]
+func (o *ContentFiles) ListFiles(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	content, ok := readContentWithAccess(c, o.content, consts.ContentAccessRead)
+	if !ok {
+		return
+	}
+
+	attachments, err := o.db.ListAttachments(ctx, content.ID)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	outputs := make([]AttachmentOutput, 0, len(attachments))
+	for _, attachment := range attachments {
+		outputs = append(outputs, newAttachmentOutput(attachment))
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, outputs, "Files of the content")
+}
+
+// @Summary Download File
+// @Tags files
+// @Description Download file attached to own or shared content
+// @ID protected-download-file
+// @Produce octet-stream
+// @Param id path int true "content_id"
+// @Param fileId path int true "file_id"
+// @Success 200 {file} file
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/content/{id}/files/{fileId} [GET]
+func (o *ContentFiles) DownloadFile(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	content, ok := readContentWithAccess(c, o.content, consts.ContentAccessRead)
+	if !ok {
+		return
+	}
+
+	fileID, ok := getFileID(c)
+	if !ok {
+		return
+	}
+
+	attachment, err := o.db.ReadAttachment(ctx, content.ID, fileID)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	o.sendFile(c, attachment)
+}
+
+// @Summary Download Shared File
+// @Tags files
+// @Description Download file of the content by the public link, authorization is not needed
+// @ID download-shared-file
+// @Produce octet-stream
+// @Param token path string true "token of the link"
+// @Param fileId path int true "file_id"
+// @Success 200 {file} file
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /shared/{token}/files/{fileId} [GET]
+func (o *ContentFiles) DownloadSharedFile(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	fileID, ok := getFileID(c)
+	if !ok {
+		return
+	}
+
+	attachment, err := o.content.ReadSharedAttachment(ctx, c.Param("token"), fileID)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	o.sendFile(c, attachment)
+}
+
+// sendFile writes the file from the storage to the response as attachment.
+func (o *ContentFiles) sendFile(c *gin.Context, attachment *models.Attachment) {
+	ctx := c.Request.Context()
+
+	reader, err := o.storage.Get(ctx, attachment.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		response.GinAppError(c, apperr.Wrap(apperr.NotFound, "file is missing in the storage", err))
+		return
+	}
+
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+	defer reader.Close()
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, reader, map[string]string{
+		"Content-Disposition":    disposition,
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// @Summary Delete File
+// @Tags files
+// @Description Delete file attached to own or shared for edit content
+// @ID protected-delete-file
+// @Produce json
+// @Param id path int true "content_id"
+// @Param fileId path int true "file_id"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/content/{id}/files/{fileId} [DELETE]
+func (o *ContentFiles) DeleteFile(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	content, ok := readContentWithAccess(c, o.content, consts.ContentAccessEdit)
+	if !ok {
+		return
+	}
+
+	fileID, ok := getFileID(c)
+	if !ok {
+		return
+	}
+
+	key, err := o.db.DeleteAttachment(ctx, content.ID, fileID)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	o.deleteObject(ctx, key)
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, "", "File deleted")
+}
+
+// deleteObject removes the file from the storage, the error is only logged, metadata is already removed.
+func (o *ContentFiles) deleteObject(ctx context.Context, key string) {
+	if err := o.storage.Delete(ctx, key); err != nil {
+		logger.Error("ContentFiles.storage.Delete()", err)
+	}
+}
+
+// getFileID return file id from path.
+func getFileID(c *gin.Context) (int32, bool) {
+	return getPathID(c, "fileId")
+}
+
+// detectContentType sniffs the type by the first bytes of the file, the type from the request is not trusted.
+func detectContentType(file io.ReadSeeker, allowedTypes []string) (string, error) {
+	buf := make([]byte, sniffSize)
+
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(buf[:n]))
+	if err != nil {
+		return "", apperr.Wrap(apperr.UnsupportedType, "file type is not recognized", err)
+	}
+
+	for _, allowedType := range allowedTypes {
+		if strings.EqualFold(allowedType, mediaType) {
+			return mediaType, nil
+		}
+	}
+
+	return "", apperr.New(apperr.UnsupportedType, fmt.Sprintf("file type %v is not allowed", mediaType))
+}
+
+// cleanFileName keeps only the base name without control symbols.
+func cleanFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name))
+
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+
+	if utf8.RuneCountInString(name) > maxFileNameSize {
+		name = string([]rune(name)[:maxFileNameSize])
+	}
+
+	return name
+}
+
+func newAttachmentOutput(attachment models.Attachment) AttachmentOutput {
+	return AttachmentOutput{
+		ID:          attachment.ID,
+		ContentID:   attachment.ContentID,
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		CreatedAt:   attachment.CreatedAt,
+	}
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDetectContentType(t *testing.T) {
+	allowedTypes := []string{"image/png", "text/plain"}
+
+	var tests = []struct {
+		name    string
+		data    []byte
+		want    string
+		wantErr bool
+	}{
+		{name: "Case-1: png image",
+			data: []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR"),
+			want: "image/png",
+		},
+		{name: "Case-2: text without parameters",
+			data: []byte("hello, world"),
+			want: "text/plain",
+		},
+		{name: "Case-3: html is not allowed",
+			data:    []byte("<html><body>hi</body></html>"),
+			wantErr: true,
+		},
+		{name: "Case-4: pdf is not allowed",
+			data:    []byte("%PDF-1.4 ..."),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := bytes.NewReader(tt.data)
+
+			got, err := detectContentType(file, allowedTypes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("detectContentType() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("detectContentType() = %v, want %v", got, tt.want)
+			}
+
+			if !tt.wantErr && file.Len() != len(tt.data) {
+				t.Errorf("file is not rewound, unread %v of %v", file.Len(), len(tt.data))
+			}
+		})
+	}
+}
+
+func TestCleanFileName(t *testing.T) {
+	var tests = []struct {
+		name     string
+		fileName string
+		want     string
+	}{
+		{name: "Case-1: plain name", fileName: "photo.png", want: "photo.png"},
+		{name: "Case-2: unix path", fileName: "../../etc/passwd", want: "passwd"},
+		{name: "Case-3: windows path", fileName: `C:\Users\me\отчет.pdf`, want: "отчет.pdf"},
+		{name: "Case-4: control symbols", fileName: "a\r\nb.txt", want: "ab.txt"},
+		{name: "Case-5: empty name", fileName: "  ", want: "file"},
+		{name: "Case-6: long name", fileName: strings.Repeat("я", 300), want: strings.Repeat("я", maxFileNameSize)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cleanFileName(tt.fileName); got != tt.want {
+				t.Errorf("cleanFileName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"github.com/Dsmit05/metida/internal/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// parsePage read page from url query: limit and offset.
+func parsePage(c *gin.Context, defaultLimit, maxLimit int) (limit, offset int32, err error) {
+	v := new(validation.Validator)
+	limit, offset = readPage(c, v, defaultLimit, maxLimit)
+
+	return limit, offset, v.Err()
+}
+
+// readPage is parsePage for parsers of several query params, violations are added to v.
+func readPage(c *gin.Context, v *validation.Validator, defaultLimit, maxLimit int) (limit, offset int32) {
+	limit = int32(queryInt(c, v, "limit", int64(defaultLimit), 1, int64(maxLimit)))
+	offset = int32(queryInt(c, v, "offset", 0, 0, maxOffset))
+
+	return limit, offset
+}
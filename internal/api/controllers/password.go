@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Dsmit05/metida/internal/api/response"
+	"github.com/Dsmit05/metida/internal/service"
+	"github.com/Dsmit05/metida/internal/validation"
+	"github.com/gin-gonic/gin"
+)
+
+type passwordServiceI interface {
+	ChangePassword(ctx context.Context, input service.PasswordChange, client service.Client) (int, error)
+	ForgotPassword(email string)
+	ResetPassword(ctx context.Context, token, newPassword string) error
+}
+
+// UserPassword defines the password controller methods
+type UserPassword struct {
+	auth   passwordServiceI
+	policy validation.PasswordPolicy
+}
+
+func NewUserPassword(auth passwordServiceI, policy validation.PasswordPolicy) *UserPassword {
+	return &UserPassword{auth: auth, policy: policy}
+}
+
+type ChangePasswordInput struct {
+	OldPassword string `json:"oldPassword" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required"`
+}
+
+func (o ChangePasswordInput) Validate(v *validation.Validator) {
+	v.Required("oldPassword", o.OldPassword)
+	v.Password("newPassword", o.NewPassword)
+}
+
+type ForgotPasswordInput struct {
+	Email string `json:"email" binding:"required"`
+}
+
+func (o ForgotPasswordInput) Validate(v *validation.Validator) {
+	v.Email("email", o.Email)
+}
+
+type ResetPasswordInput struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required"`
+}
+
+func (o ResetPasswordInput) Validate(v *validation.Validator) {
+	v.Required("token", o.Token)
+	v.Password("newPassword", o.NewPassword)
+}
+
+// @Summary Change password
+// @Tags password
+// @Description Change password of the user, other sessions are revoked
+// @ID protected-change-password
+// @Accept json
+// @Produce json
+// @Param input body ChangePasswordInput true "old and new password"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 429 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/password [PUT]
+func (o *UserPassword) ChangePassword(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var inputData ChangePasswordInput
+
+	if !bindJSONWith(c, &inputData, validation.NewValidator(o.policy)) {
+		return
+	}
+
+	value, _ := c.Get("sessionID")
+	sessionID, _ := value.(int32)
+
+	revoked, err := o.auth.ChangePassword(ctx, service.PasswordChange{
+		Email:       c.GetString("email"),
+		SessionID:   sessionID,
+		OldPassword: inputData.OldPassword,
+		NewPassword: inputData.NewPassword,
+	}, service.Client{IP: c.ClientIP()})
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, gin.H{"revokedSessions": revoked}, "Password changed")
+}
+
+// @Summary Forgot password
+// @Tags password
+// @Description Send letter with link for password reset, the answer does not depend on the email existence
+// @ID forgot-password
+// @Accept json
+// @Produce json
+// @Param input body ForgotPasswordInput true "user email"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Router /auth/password/forgot [post]
+func (o *UserPassword) ForgotPassword(c *gin.Context) {
+	var inputData ForgotPasswordInput
+
+	if !bindJSON(c, &inputData) {
+		return
+	}
+
+	o.auth.ForgotPassword(inputData.Email)
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, "",
+		"If the account exists, a letter with instructions will be sent")
+}
+
+// @Summary Reset password
+// @Tags password
+// @Description Set new password with token from the letter, all sessions are revoked
+// @ID reset-password
+// @Accept json
+// @Produce json
+// @Param input body ResetPasswordInput true "reset token and new password"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Router /auth/password/reset [post]
+func (o *UserPassword) ResetPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var inputData ResetPasswordInput
+
+	if !bindJSONWith(c, &inputData, validation.NewValidator(o.policy)) {
+		return
+	}
+
+	if err := o.auth.ResetPassword(ctx, inputData.Token, inputData.NewPassword); err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, "", "Password changed, please sign in")
+}
@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/Dsmit05/metida/internal/api/response"
+	"github.com/Dsmit05/metida/internal/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// maxOffset offset of pages is int32 in queries.
+const maxOffset = math.MaxInt32
+
+// queryInt read integer from url query, an empty value is replaced by default.
+func queryInt(c *gin.Context, v *validation.Validator, field string, defaultValue, min, max int64) int64 {
+	value := c.Query(field)
+	if value == "" {
+		return defaultValue
+	}
+
+	return parseInt(v, field, value, min, max)
+}
+
+// getPathID return positive id from path, a wrong id is answered as violation of the param.
+func getPathID(c *gin.Context, param string) (int32, bool) {
+	v := new(validation.Validator)
+
+	id := parseInt(v, param, c.Param(param), 1, math.MaxInt32)
+	if err := v.Err(); err != nil {
+		response.GinAppError(c, err)
+		return 0, false
+	}
+
+	return int32(id), true
+}
+
+// parseInt checks that value is integer from min to max, violations are added to v.
+func parseInt(v *validation.Validator, field, value string, min, max int64) int64 {
+	v.Required(field, value)
+	if v.Has(field) {
+		return 0
+	}
+
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		v.Add(field, validation.RuleType, "must be integer", map[string]interface{}{"type": "integer", "value": value})
+		return 0
+	}
+
+	v.Range(field, number, min, max)
+
+	return number
+}
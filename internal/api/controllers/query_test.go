@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Dsmit05/metida/internal/validation"
+)
+
+func TestParseInt(t *testing.T) {
+	var tests = []struct {
+		name     string
+		value    string
+		want     int64
+		wantRule string
+	}{
+		{name: "Case-1: first revision", value: "1", want: 1},
+		{name: "Case-2: zero", value: "0", wantRule: validation.RuleRange},
+		{name: "Case-3: negative", value: "-3", wantRule: validation.RuleRange},
+		{name: "Case-4: not a number", value: "latest", wantRule: validation.RuleType},
+		{name: "Case-5: empty", value: "", wantRule: validation.RuleRequired},
+		{name: "Case-6: overflow", value: "4294967296", wantRule: validation.RuleRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := new(validation.Validator)
+
+			got := parseInt(v, "rev", tt.value, 1, math.MaxInt32)
+
+			violations := v.Violations()
+			if tt.wantRule == "" {
+				if len(violations) != 0 || got != tt.want {
+					t.Errorf("parseInt() = %v, %+v, want %v", got, violations, tt.want)
+				}
+				return
+			}
+
+			if len(violations) != 1 || violations[0].Field != "rev" || violations[0].Rule != tt.wantRule {
+				t.Errorf("parseInt() violations = %+v, want rule %v", violations, tt.wantRule)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package controllers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/Dsmit05/metida/internal/api/response"
+	"github.com/Dsmit05/metida/internal/consts"
+	"github.com/Dsmit05/metida/internal/models"
+	"github.com/Dsmit05/metida/internal/validation"
+	"github.com/Dsmit05/metida/pkg/diff"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultRevisionsLimit = 20
+	maxRevisionsLimit     = 100
+)
+
+type revisionsRepositoryI interface {
+	ReadContentRevision(ctx context.Context, contentID, revision int32) (*models.ContentRevision, error)
+	ListContentRevisions(ctx context.Context, contentID, limit, offset int32) ([]models.ContentRevision, error)
+	CountContentRevisions(ctx context.Context, contentID int32) (int64, error)
+	RestoreContentRevision(ctx context.Context, email string, id, revision int32) (*models.Content, error)
+}
+
+// ContentRevisions defines the controller methods of the content history
+type ContentRevisions struct {
+	db      revisionsRepositoryI
+	content contentAccessI
+}
+
+func NewContentRevisions(db revisionsRepositoryI, content contentAccessI) *ContentRevisions {
+	return &ContentRevisions{db: db, content: content}
+}
+
+// RevisionOutput revision of the content without description.
+type RevisionOutput struct {
+	Revision  int32     `json:"revision" example:"2"`
+	Author    string    `json:"author" example:"user@gmail.com"`
+	Summary   string    `json:"summary" example:"description +2 -1 lines"`
+	Name      string    `json:"name" example:"My First Content"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// RevisionSnapshotOutput full snapshot of the content.
+type RevisionSnapshotOutput struct {
+	RevisionOutput
+	Description string `json:"description" example:"New content..."`
+}
+
+// RevisionPageOutput page of revisions, the latest first.
+type RevisionPageOutput struct {
+	Items []RevisionOutput `json:"items"`
+	Total int64            `json:"total" example:"1"`
+}
+
+// DiffLineOutput op is "+" for inserted, "-" for deleted and " " for unchanged lines.
+type DiffLineOutput struct {
+	Op   string `json:"op" example:"+"`
+	Text string `json:"text" example:"New line"`
+}
+
+// DiffOutput changes from one revision to another, description is compared by lines.
+type DiffOutput struct {
+	From     int32            `json:"from" example:"1"`
+	To       int32            `json:"to" example:"2"`
+	NameFrom string           `json:"nameFrom" example:"My First Content"`
+	NameTo   string           `json:"nameTo" example:"My Content"`
+	Inserted int              `json:"inserted" example:"2"`
+	Deleted  int              `json:"deleted" example:"1"`
+	Lines    []DiffLineOutput `json:"lines"`
+}
+
+// @Summary List Revisions
+// @Tags revisions
+// @Description Show history of own or shared content, the latest revision first
+// @ID protected-list-revisions
+// @Produce json
+// @Param id path int true "content_id"
+// @Param limit query int false "page size, max 100"
+// @Param offset query int false "offset"
+// @Success 200 {object} response.Success{data=RevisionPageOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/content/{id}/revisions [GET]
+func (o *ContentRevisions) ListRevisions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	content, ok := readContentWithAccess(c, o.content, consts.ContentAccessRead)
+	if !ok {
+		return
+	}
+
+	limit, offset, err := parsePage(c, defaultRevisionsLimit, maxRevisionsLimit)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	revisions, err := o.db.ListContentRevisions(ctx, content.ID, limit, offset)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	total, err := o.db.CountContentRevisions(ctx, content.ID)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	output := RevisionPageOutput{Items: make([]RevisionOutput, 0, len(revisions)), Total: total}
+	for _, revision := range revisions {
+		output.Items = append(output.Items, newRevisionOutput(revision))
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, output, "")
+}
+
+// @Summary Show Revision
+// @Tags revisions
+// @Description Show snapshot of own or shared content at the revision
+// @ID protected-show-revision
+// @Produce json
+// @Param id path int true "content_id"
+// @Param rev path int true "revision"
+// @Success 200 {object} response.Success{data=RevisionSnapshotOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/content/{id}/revisions/{rev} [GET]
+func (o *ContentRevisions) ShowRevision(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	content, ok := readContentWithAccess(c, o.content, consts.ContentAccessRead)
+	if !ok {
+		return
+	}
+
+	number, ok := getPathID(c, "rev")
+	if !ok {
+		return
+	}
+
+	revision, err := o.db.ReadContentRevision(ctx, content.ID, number)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	output := RevisionSnapshotOutput{
+		RevisionOutput: newRevisionOutput(*revision),
+		Description:    revision.Description,
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, output, "")
+}
+
+// @Summary Diff Revisions
+// @Tags revisions
+// @Description Compare two revisions of own or shared content
+// @ID protected-diff-revisions
+// @Produce json
+// @Param id path int true "content_id"
+// @Param from query int true "old revision"
+// @Param to query int true "new revision"
+// @Success 200 {object} response.Success{data=DiffOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/content/{id}/revisions/diff [GET]
+func (o *ContentRevisions) DiffRevisions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	content, ok := readContentWithAccess(c, o.content, consts.ContentAccessRead)
+	if !ok {
+		return
+	}
+
+	from, to, err := parseDiffRange(c)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	fromRevision, err := o.db.ReadContentRevision(ctx, content.ID, from)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	toRevision, err := o.db.ReadContentRevision(ctx, content.ID, to)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, newDiffOutput(*fromRevision, *toRevision), "")
+}
+
+// @Summary Restore Revision
+// @Tags revisions
+// @Description Replace own or shared for edit content by the snapshot of the revision, the restore is saved as a new revision
+// @ID protected-restore-revision
+// @Produce json
+// @Param id path int true "content_id"
+// @Param rev path int true "revision"
+// @Success 200 {object} response.Success{data=ContentOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/content/{id}/revisions/{rev}/restore [POST]
+func (o *ContentRevisions) RestoreRevision(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	content, ok := readContentWithAccess(c, o.content, consts.ContentAccessEdit)
+	if !ok {
+		return
+	}
+
+	number, ok := getPathID(c, "rev")
+	if !ok {
+		return
+	}
+
+	content, err := o.db.RestoreContentRevision(ctx, c.GetString("email"), content.ID, number)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, newContentOutput(*content), "Revision restored")
+}
+
+// parseDiffRange read old and new revisions from url query, revisions are numbered from 1.
+func parseDiffRange(c *gin.Context) (from, to int32, err error) {
+	v := new(validation.Validator)
+	from = int32(parseInt(v, "from", c.Query("from"), 1, math.MaxInt32))
+	to = int32(parseInt(v, "to", c.Query("to"), 1, math.MaxInt32))
+
+	return from, to, v.Err()
+}
+
+func newRevisionOutput(revision models.ContentRevision) RevisionOutput {
+	return RevisionOutput{
+		Revision:  revision.Revision,
+		Author:    revision.AuthorEmail,
+		Summary:   revision.Summary,
+		Name:      revision.Name,
+		CreatedAt: revision.CreatedAt,
+	}
+}
+
+func newDiffOutput(from, to models.ContentRevision) DiffOutput {
+	lines := diff.Lines(from.Description, to.Description)
+	inserted, deleted := diff.Stat(lines)
+
+	output := DiffOutput{
+		From:     from.Revision,
+		To:       to.Revision,
+		NameFrom: from.Name,
+		NameTo:   to.Name,
+		Inserted: inserted,
+		Deleted:  deleted,
+		Lines:    make([]DiffLineOutput, 0, len(lines)),
+	}
+
+	for _, line := range lines {
+		output.Lines = append(output.Lines, DiffLineOutput{Op: string(line.Op), Text: line.Text})
+	}
+
+	return output
+}
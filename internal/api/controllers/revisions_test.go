@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/Dsmit05/metida/internal/models"
+)
+
+func TestNewDiffOutput(t *testing.T) {
+	from := models.ContentRevision{Revision: 1, Name: "old", Description: "one\ntwo"}
+	to := models.ContentRevision{Revision: 2, Name: "new", Description: "one\nthree"}
+
+	got := newDiffOutput(from, to)
+	if got.From != 1 || got.To != 2 || got.NameFrom != "old" || got.NameTo != "new" {
+		t.Fatalf("newDiffOutput() header = %+v", got)
+	}
+	if got.Inserted != 1 || got.Deleted != 1 {
+		t.Errorf("newDiffOutput() stat = +%d -%d, want +1 -1", got.Inserted, got.Deleted)
+	}
+
+	want := []DiffLineOutput{{Op: " ", Text: "one"}, {Op: "-", Text: "two"}, {Op: "+", Text: "three"}}
+	if len(got.Lines) != len(want) {
+		t.Fatalf("newDiffOutput() lines = %+v, want %+v", got.Lines, want)
+	}
+	for i := range want {
+		if got.Lines[i] != want[i] {
+			t.Errorf("newDiffOutput() line %d = %+v, want %+v", i, got.Lines[i], want[i])
+		}
+	}
+}
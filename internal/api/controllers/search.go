@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/Dsmit05/metida/internal/validation"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+	maxSearchQueryLen  = 200
+)
+
+// searchParams query and page of the full-text search.
+type searchParams struct {
+	query  string
+	limit  int32
+	offset int32
+}
+
+// parseSearchParams read search query and page from url query: q, limit, offset.
+func parseSearchParams(c *gin.Context) (searchParams, error) {
+	v := new(validation.Validator)
+
+	params := searchParams{query: strings.TrimSpace(c.Query("q"))}
+	v.Required("q", params.query)
+	v.Length("q", params.query, 1, maxSearchQueryLen)
+
+	params.limit, params.offset = readPage(c, v, defaultSearchLimit, maxSearchLimit)
+
+	return params, v.Err()
+}
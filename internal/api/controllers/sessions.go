@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Dsmit05/metida/internal/api/response"
+	"github.com/Dsmit05/metida/internal/apperr"
+	"github.com/Dsmit05/metida/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+type sessionServiceI interface {
+	ListSessions(ctx context.Context, email string) ([]models.Session, error)
+	RevokeSession(ctx context.Context, email string, id int32) error
+	Logout(ctx context.Context, email string, sessionID int32, tokenID string, tokenExpiresAt int64) error
+	RevokeOtherSessions(ctx context.Context, email string, currentID int32) (int, error)
+}
+
+// UserSessions defines the session controller methods
+type UserSessions struct {
+	auth sessionServiceI
+}
+
+func NewUserSessions(auth sessionServiceI) *UserSessions {
+	return &UserSessions{auth}
+}
+
+// SessionOutput information about user device, refresh token is not shown.
+type SessionOutput struct {
+	ID        int32     `json:"id" example:"1"`
+	UserAgent string    `json:"userAgent" example:"Mozilla/5.0"`
+	IP        string    `json:"ip" example:"127.0.0.1"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresIn int64     `json:"expiresIn" example:"1656513112"`
+	Current   bool      `json:"current" example:"true"`
+}
+
+// @Summary List sessions
+// @Tags sessions
+// @Description Show active sessions of the user
+// @ID protected-list-sessions
+// @Produce json
+// @Success 200 {object} response.Success{data=[]SessionOutput}
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/sessions [GET]
+func (o *UserSessions) ListSessions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	email, currentID, ok := o.getSessionOwner(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := o.auth.ListSessions(ctx, email)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	output := make([]SessionOutput, 0, len(sessions))
+	for _, session := range sessions {
+		output = append(output, SessionOutput{
+			ID:        session.ID,
+			UserAgent: session.UserAgent,
+			IP:        session.IP,
+			CreatedAt: session.CreatedAt,
+			ExpiresIn: session.ExpiresIn,
+			Current:   session.ID == currentID,
+		})
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, output, "")
+}
+
+// @Summary Revoke session
+// @Tags sessions
+// @Description Log out the device with selected session
+// @ID protected-revoke-session
+// @Produce json
+// @Param id path int true "session_id"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/sessions/{id} [DELETE]
+func (o *UserSessions) RevokeSession(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	email, _, ok := o.getSessionOwner(c)
+	if !ok {
+		return
+	}
+
+	id, ok := getPathID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := o.auth.RevokeSession(ctx, email, id); err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, "", "Session revoked")
+}
+
+// @Summary Log out
+// @Tags sessions
+// @Description Log out the current session
+// @ID protected-logout
+// @Produce json
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/logout [POST]
+func (o *UserSessions) Logout(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	email, currentID, ok := o.getSessionOwner(c)
+	if !ok {
+		return
+	}
+
+	err := o.auth.Logout(ctx, email, currentID, c.GetString("tokenID"), c.GetInt64("tokenExpiresAt"))
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, "", "Logged out")
+}
+
+// @Summary Revoke other sessions
+// @Tags sessions
+// @Description Log out everywhere except the current session
+// @ID protected-revoke-other-sessions
+// @Produce json
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/sessions [DELETE]
+func (o *UserSessions) RevokeOtherSessions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	email, currentID, ok := o.getSessionOwner(c)
+	if !ok {
+		return
+	}
+
+	revoked, err := o.auth.RevokeOtherSessions(ctx, email, currentID)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, gin.H{"revoked": revoked}, "Other sessions revoked")
+}
+
+// getSessionOwner return email and session id set by AuthMidleware.
+func (o *UserSessions) getSessionOwner(c *gin.Context) (email string, sessionID int32, ok bool) {
+	email = c.GetString("email")
+	if email == "" {
+		response.GinAppError(c, apperr.New(apperr.Unauthorized, "user without email"))
+		return "", 0, false
+	}
+
+	value, _ := c.Get("sessionID")
+	sessionID, _ = value.(int32)
+
+	return email, sessionID, true
+}
@@ -0,0 +1,416 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Dsmit05/metida/internal/api/response"
+	"github.com/Dsmit05/metida/internal/consts"
+	"github.com/Dsmit05/metida/internal/models"
+	"github.com/Dsmit05/metida/internal/validation"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSharedLimit = 20
+	maxSharedLimit     = 100
+)
+
+// contentAccessI checks rights of the user to the content, it is implemented by service.ContentService.
+type contentAccessI interface {
+	ReadWithAccess(ctx context.Context, email string, id int32, need string) (*models.Content, error)
+}
+
+type sharesServiceI interface {
+	Share(ctx context.Context, email string, id int32, userEmail, permission string) (*models.ContentShare, error)
+	ListShares(ctx context.Context, email string, id int32) ([]models.ContentShare, error)
+	Unshare(ctx context.Context, email string, id int32, userEmail string) error
+	ListShared(ctx context.Context, email string, limit, offset int32) ([]models.Content, int64, error)
+	CreateLink(ctx context.Context, email string, id int32, ttl time.Duration) (*models.ContentLink, string, error)
+	ListLinks(ctx context.Context, email string, id int32) ([]models.ContentLink, error)
+	DeleteLink(ctx context.Context, email string, id, linkID int32) error
+	ReadByLink(ctx context.Context, token string) (*models.Content, []models.Attachment, error)
+}
+
+// ContentShares defines the controller methods of sharing content
+type ContentShares struct {
+	content sharesServiceI
+}
+
+func NewContentShares(content sharesServiceI) *ContentShares {
+	return &ContentShares{content: content}
+}
+
+type ShareContentInput struct {
+	Email      string `json:"email" binding:"required" example:"friend@gmail.com"`
+	Permission string `json:"permission" binding:"required" example:"read"`
+}
+
+// Validate the email is trimmed before the check.
+func (o ShareContentInput) Validate(v *validation.Validator) {
+	v.Email("email", strings.TrimSpace(o.Email))
+	v.OneOf("permission", o.Permission, consts.ContentAccessRead, consts.ContentAccessEdit)
+}
+
+// CreateLinkInput ttl of the link in second, by default 7 days, max 30 days.
+type CreateLinkInput struct {
+	TTL int64 `json:"ttl" example:"86400"`
+}
+
+// Validate zero ttl means default.
+func (o CreateLinkInput) Validate(v *validation.Validator) {
+	v.Range("ttl", o.TTL, 0, int64(consts.ContentLinkMaxTTL/time.Second))
+}
+
+// ContentShareOutput user having access to the content.
+type ContentShareOutput struct {
+	Email      string    `json:"email" example:"friend@gmail.com"`
+	Permission string    `json:"permission" example:"read"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// SharedContentOutput content of another user.
+type SharedContentOutput struct {
+	ContentOutput
+	Owner string `json:"owner" example:"owner@gmail.com"`
+}
+
+// SharedContentPageOutput page of content shared with the user.
+type SharedContentPageOutput struct {
+	Items []SharedContentOutput `json:"items"`
+	Total int64                 `json:"total" example:"1"`
+}
+
+// ContentLinkOutput public link, the token is shown only once after creation.
+type ContentLinkOutput struct {
+	ID        int32     `json:"id" example:"1"`
+	Token     string    `json:"token,omitempty" example:"q8CqGzvM0x..."`
+	ExpiresAt time.Time `json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// PublicContentOutput content opened by the public link.
+type PublicContentOutput struct {
+	ContentOutput
+	Files []AttachmentOutput `json:"files"`
+}
+
+// @Summary Share Content
+// @Tags shares
+// @Description Grant another user read or edit access to own content, existing access is replaced
+// @ID protected-share-content
+// @Accept json
+// @Produce json
+// @Param id path int true "content_id"
+// @Param input body ShareContentInput true "user and permission"
+// @Success 200 {object} response.Success{data=ContentShareOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/content/{id}/shares [PUT]
+func (o *ContentShares) ShareContent(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := getContentID(c)
+	if !ok {
+		return
+	}
+
+	var inputData ShareContentInput
+
+	if !bindJSON(c, &inputData) {
+		return
+	}
+
+	share, err := o.content.Share(ctx, c.GetString("email"), id,
+		strings.TrimSpace(inputData.Email), inputData.Permission)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, newContentShareOutput(*share), "Content shared")
+}
+
+// @Summary List Shares
+// @Tags shares
+// @Description Show users having access to own content
+// @ID protected-list-shares
+// @Produce json
+// @Param id path int true "content_id"
+// @Success 200 {object} response.Success{data=[]ContentShareOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/content/{id}/shares [GET]
+func (o *ContentShares) ListShares(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := getContentID(c)
+	if !ok {
+		return
+	}
+
+	shares, err := o.content.ListShares(ctx, c.GetString("email"), id)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	outputs := make([]ContentShareOutput, 0, len(shares))
+	for _, share := range shares {
+		outputs = append(outputs, newContentShareOutput(share))
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, outputs, "")
+}
+
+// @Summary Unshare Content
+// @Tags shares
+// @Description Revoke access of the user to own content
+// @ID protected-unshare-content
+// @Produce json
+// @Param id path int true "content_id"
+// @Param email path string true "email of the user"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/content/{id}/shares/{email} [DELETE]
+func (o *ContentShares) UnshareContent(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := getContentID(c)
+	if !ok {
+		return
+	}
+
+	if err := o.content.Unshare(ctx, c.GetString("email"), id, c.Param("email")); err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, "", "Access revoked")
+}
+
+// @Summary List Shared Content
+// @Tags shares
+// @Description Show content of other users shared with the user, recently shared first
+// @ID protected-list-shared-content
+// @Produce json
+// @Param limit query int false "page size, max 100"
+// @Param offset query int false "offset"
+// @Success 200 {object} response.Success{data=SharedContentPageOutput}
+// @Failure 400 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/content/shared [GET]
+func (o *ContentShares) ListSharedContent(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	limit, offset, err := parsePage(c, defaultSharedLimit, maxSharedLimit)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	contents, total, err := o.content.ListShared(ctx, c.GetString("email"), limit, offset)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	output := SharedContentPageOutput{Items: make([]SharedContentOutput, 0, len(contents)), Total: total}
+	for _, content := range contents {
+		output.Items = append(output.Items, SharedContentOutput{
+			ContentOutput: newContentOutput(content),
+			Owner:         content.UserEmail,
+		})
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, output, "")
+}
+
+// @Summary Create Link
+// @Tags shares
+// @Description Create expiring public link to own content, the token is shown only once
+// @ID protected-create-link
+// @Accept json
+// @Produce json
+// @Param id path int true "content_id"
+// @Param input body CreateLinkInput false "lifetime of the link"
+// @Success 200 {object} response.Success{data=ContentLinkOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/content/{id}/links [POST]
+func (o *ContentShares) CreateLink(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := getContentID(c)
+	if !ok {
+		return
+	}
+
+	var inputData CreateLinkInput
+
+	if c.Request.ContentLength != 0 {
+		if !bindJSON(c, &inputData) {
+			return
+		}
+	}
+
+	link, token, err := o.content.CreateLink(ctx, c.GetString("email"), id, linkTTL(inputData.TTL))
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	output := newContentLinkOutput(*link)
+	output.Token = token
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, output, "Link created")
+}
+
+// @Summary List Links
+// @Tags shares
+// @Description Show unexpired public links to own content
+// @ID protected-list-links
+// @Produce json
+// @Param id path int true "content_id"
+// @Success 200 {object} response.Success{data=[]ContentLinkOutput}
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/content/{id}/links [GET]
+func (o *ContentShares) ListLinks(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := getContentID(c)
+	if !ok {
+		return
+	}
+
+	links, err := o.content.ListLinks(ctx, c.GetString("email"), id)
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	outputs := make([]ContentLinkOutput, 0, len(links))
+	for _, link := range links {
+		outputs = append(outputs, newContentLinkOutput(link))
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, outputs, "")
+}
+
+// @Summary Delete Link
+// @Tags shares
+// @Description Revoke public link to own content
+// @ID protected-delete-link
+// @Produce json
+// @Param id path int true "content_id"
+// @Param linkId path int true "link_id"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /lk/content/{id}/links/{linkId} [DELETE]
+func (o *ContentShares) DeleteLink(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := getContentID(c)
+	if !ok {
+		return
+	}
+
+	linkID, ok := getPathID(c, "linkId")
+	if !ok {
+		return
+	}
+
+	if err := o.content.DeleteLink(ctx, c.GetString("email"), id, linkID); err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, "", "Link deleted")
+}
+
+// @Summary Show Shared Content
+// @Tags shares
+// @Description Show content and its files by the public link, authorization is not needed
+// @ID show-shared-content
+// @Produce json
+// @Param token path string true "token of the link"
+// @Success 200 {object} response.Success{data=PublicContentOutput}
+// @Failure 404 {object} response.Error
+// @Router /shared/{token} [GET]
+func (o *ContentShares) ShowSharedContent(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	content, attachments, err := o.content.ReadByLink(ctx, c.Param("token"))
+	if err != nil {
+		response.GinAppError(c, err)
+		return
+	}
+
+	output := PublicContentOutput{
+		ContentOutput: newContentOutput(*content),
+		Files:         make([]AttachmentOutput, 0, len(attachments)),
+	}
+	for _, attachment := range attachments {
+		output.Files = append(output.Files, newAttachmentOutput(attachment))
+	}
+
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, output, "")
+}
+
+// readContentWithAccess return content from path, rights of the user are checked by the content service.
+func readContentWithAccess(c *gin.Context, contents contentAccessI, need string) (*models.Content, bool) {
+	id, ok := getContentID(c)
+	if !ok {
+		return nil, false
+	}
+
+	content, err := contents.ReadWithAccess(c.Request.Context(), c.GetString("email"), id, need)
+	if err != nil {
+		response.GinAppError(c, err)
+		return nil, false
+	}
+
+	return content, true
+}
+
+// linkTTL return lifetime of the link, zero is replaced by default, the range is checked by CreateLinkInput.
+func linkTTL(seconds int64) time.Duration {
+	if seconds == 0 {
+		return consts.ContentLinkTTL
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+func newContentShareOutput(share models.ContentShare) ContentShareOutput {
+	return ContentShareOutput{
+		Email:      share.UserEmail,
+		Permission: share.Permission,
+		CreatedAt:  share.CreatedAt,
+	}
+}
+
+func newContentLinkOutput(link models.ContentLink) ContentLinkOutput {
+	return ContentLinkOutput{
+		ID:        link.ID,
+		ExpiresAt: time.Unix(link.ExpiresIn, 0).UTC(),
+		CreatedAt: link.CreatedAt,
+	}
+}
@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dsmit05/metida/internal/consts"
+)
+
+func TestLinkTTL(t *testing.T) {
+	var tests = []struct {
+		name    string
+		seconds int64
+		want    time.Duration
+	}{
+		{name: "Case-1: default ttl", seconds: 0, want: consts.ContentLinkTTL},
+		{name: "Case-2: one hour", seconds: 3600, want: time.Hour},
+		{name: "Case-3: max ttl", seconds: int64(consts.ContentLinkMaxTTL / time.Second), want: consts.ContentLinkMaxTTL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := linkTTL(tt.seconds); got != tt.want {
+				t.Errorf("linkTTL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,43 +1,32 @@
 package controllers
 
 import (
-	"fmt"
+	"context"
 	"net/http"
-	"time"
 
-	"github.com/Dsmit05/metida/internal/api/validation"
-	"github.com/Dsmit05/metida/internal/cryptography"
-	"github.com/Dsmit05/metida/internal/models"
+	"github.com/Dsmit05/metida/internal/service"
 
 	"github.com/Dsmit05/metida/internal/api/response"
-	"github.com/Dsmit05/metida/internal/consts"
 	"github.com/Dsmit05/metida/internal/logger"
+	"github.com/Dsmit05/metida/internal/validation"
 	"github.com/gin-gonic/gin"
 )
 
-type userRepositoryI interface {
-	CreateUser(name string, password string, email string, role string) error
-	ReadUser(email string) (*models.User, error)
-	CreateSession(email string, refreshToken string, userAgent string, ip string, expiresIn int64) error
-	ReadSession(email string, userAgent string, ip string) (*models.Session, error)
-	UpdateSessionTokenOnly(refreshToken string, newRefreshToken string, expiresIn int64) error
-	ReadEmailRoleWithRefreshToken(refreshToken string) (*models.UserEmailRole, error)
-}
-
-type tokensI interface {
-	CreateToken(email string, role string, ttl time.Duration) (string, error)
-	ParseToken(inputToken string) (email string, role string, err error)
-	CreateRefreshToken() (string, error)
+type authServiceI interface {
+	SignUp(ctx context.Context, input service.SignUpInput, client service.Client) (*service.Tokens, error)
+	SignIn(ctx context.Context, email, password string, client service.Client) (*service.Tokens, error)
+	Refresh(ctx context.Context, refreshToken string, client service.Client) (*service.Tokens, error)
+	VerifyEmail(ctx context.Context, token string) error
 }
 
 // UserAuth defines the user controller methods
 type UserAuth struct {
-	db    userRepositoryI
-	token tokensI
+	auth           authServiceI
+	passwordPolicy validation.PasswordPolicy
 }
 
-func NewUserAuth(db userRepositoryI, token tokensI) *UserAuth {
-	return &UserAuth{db: db, token: token}
+func NewUserAuth(auth authServiceI, passwordPolicy validation.PasswordPolicy) *UserAuth {
+	return &UserAuth{auth: auth, passwordPolicy: passwordPolicy}
 }
 
 type CreateUserInput struct {
@@ -46,6 +35,12 @@ type CreateUserInput struct {
 	Email    string `json:"email" binding:"required" example:"ivashka2015@gmail.com"`
 }
 
+func (o CreateUserInput) Validate(v *validation.Validator) {
+	v.UserName("username", o.Username)
+	v.Email("email", o.Email)
+	v.Password("password", o.Password)
+}
+
 // @Summary Sign Up
 // @Tags auth
 // @Description create account
@@ -55,60 +50,33 @@ type CreateUserInput struct {
 // @Param input body CreateUserInput true "credentials"
 // @Success 200 {object} response.Success
 // @Failure 400 {object} response.Error
+// @Failure 409 {object} response.Error
 // @Router /auth/sign-up [post]
 func (o *UserAuth) CreateUser(c *gin.Context) {
-	// Validate input
-	var inputData CreateUserInput
-	if err := c.ShouldBindJSON(&inputData); err != nil {
-		response.GinError(c, http.StatusBadRequest, response.CodeInvalidParams, "incorrect data, try again", nil)
-		return
-	}
-
-	if !o.validateUserData(c, inputData) {
-		return
-	}
+	ctx := c.Request.Context()
 
-	// hashing input password
-	passwordHash, err := cryptography.HashPassword(inputData.Password)
-	if err != nil {
-		response.GinError(c, http.StatusBadRequest, response.CodeInvalidParams, "incorrect password", nil)
-		return
-	}
-
-	if err = o.db.CreateUser(inputData.Username, passwordHash, inputData.Email, consts.RoleUser); err != nil {
-		response.GinError(c, http.StatusBadRequest, response.CodeBadRequest, err.Error(), err)
+	var inputData CreateUserInput
+	if !bindJSONWith(c, &inputData, validation.NewValidator(o.passwordPolicy)) {
 		return
 	}
 
-	// Здесь нужно возращать OK и предлагать подтвердить почту
-	// Дальше пользователь должен подтвердить почту, после чего создаем ему сессию
-	// Todo: данную реализацию можно сделать в следующих версиях апи
-	// Для примера сделаем сквозную, без транзакций
-
-	ip, agent := o.getIPandUserAgent(c)
-
-	// Create refresh Token
-	rToken, err := o.token.CreateRefreshToken()
-	if err != nil {
-		response.GinError(c, http.StatusInternalServerError, response.CodeCryptoError, "", nil)
-		return
-	}
-	err = o.db.CreateSession(inputData.Email, rToken, agent, ip, time.Now().Add(consts.RefreshTokenTTL).Unix())
+	tokens, err := o.auth.SignUp(ctx, service.SignUpInput{
+		Name: inputData.Username, Email: inputData.Email, Password: inputData.Password}, o.getClient(c))
 	if err != nil {
-		response.GinError(c, http.StatusBadRequest, response.CodeBadRequest, err.Error(), err)
+		response.GinAppError(c, err)
 		return
 	}
 
-	// Create access token
-	aToken, err := o.token.CreateToken(inputData.Email, consts.RoleUser, consts.AccessTokenTTL)
-	if err != nil {
-		response.GinError(c, http.StatusInternalServerError, response.CodeCryptoError, "", nil)
+	// Пока почта не подтверждена, токенов нет
+	if tokens == nil {
+		response.GinSuccess(c, http.StatusOK, response.CodeOk, "",
+			"Create New User, please confirm your email")
 		return
 	}
 
 	response.GinSuccess(c,
 		http.StatusOK, response.CodeOk,
-		gin.H{"aToken": aToken, "rToken": rToken}, "Create New User")
+		gin.H{"aToken": tokens.Access, "rToken": tokens.Refresh}, "Create New User")
 }
 
 type AuthenticationUserInput struct {
@@ -116,6 +84,12 @@ type AuthenticationUserInput struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// Validate rules of passwords are not checked, they could be changed after sign-up.
+func (o AuthenticationUserInput) Validate(v *validation.Validator) {
+	v.Required("email", o.Email)
+	v.Required("password", o.Password)
+}
+
 // @Summary Sign In
 // @Tags auth
 // @Description log in account
@@ -125,60 +99,37 @@ type AuthenticationUserInput struct {
 // @Param input body AuthenticationUserInput true "credentials"
 // @Success 200 {object} response.Success
 // @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 429 {object} response.Error
 // @Router /auth/sign-in [post]
 func (o *UserAuth) AuthenticationUser(c *gin.Context) {
-	var inputData AuthenticationUserInput
-
-	if err := c.ShouldBindJSON(&inputData); err != nil {
-		response.GinError(c, http.StatusBadRequest, response.CodeInvalidParams, "bad data, try again", nil)
-		return
-	}
-
-	user, err := o.db.ReadUser(inputData.Email)
-	if err != nil {
-		response.GinError(c, http.StatusBadRequest, response.CodeDBError, err.Error(), err)
-		return
-	}
-
-	// check password with hash
-	if !cryptography.CheckPassword(user.Password, inputData.Password) {
-		err = fmt.Errorf("wrong password")
-		response.GinError(c, http.StatusUnauthorized, response.CodeBadRequest, err.Error(), err)
-		return
-	}
-
-	newRefreshToken, err := o.token.CreateRefreshToken()
-	if err != nil {
-		response.GinError(c, http.StatusInternalServerError, response.CodeCryptoError, "", nil)
-		return
-	}
+	ctx := c.Request.Context()
 
-	ip, agent := o.getIPandUserAgent(c)
+	var inputData AuthenticationUserInput
 
-	// при каждом логине создаем новую сессию
-	err = o.db.CreateSession(
-		inputData.Email, newRefreshToken, agent, ip, time.Now().Add(consts.RefreshTokenTTL).Unix())
-	if err != nil {
-		response.GinError(c, http.StatusBadRequest, response.CodeBadRequest, err.Error(), err)
+	if !bindJSON(c, &inputData) {
 		return
 	}
 
-	// Create access token
-	aToken, err := o.token.CreateToken(inputData.Email, user.Role, consts.AccessTokenTTL)
+	tokens, err := o.auth.SignIn(ctx, inputData.Email, inputData.Password, o.getClient(c))
 	if err != nil {
-		response.GinError(c, http.StatusInternalServerError, response.CodeCryptoError, "", nil)
+		response.GinAppError(c, err)
 		return
 	}
 
 	response.GinSuccess(c, http.StatusOK, response.CodeOk,
-		gin.H{"aToken": aToken, "rToken": newRefreshToken}, "Authentication well")
-
+		gin.H{"aToken": tokens.Access, "rToken": tokens.Refresh}, "Authentication well")
 }
 
 type RefreshTokenInput struct {
 	RefreshToken string `json:"rtoken" binding:"required"`
 }
 
+func (o RefreshTokenInput) Validate(v *validation.Validator) {
+	v.Required("rtoken", o.RefreshToken)
+}
+
 // @Summary Refresh token
 // @Tags auth
 // @Description refresh access token
@@ -188,83 +139,57 @@ type RefreshTokenInput struct {
 // @Param input body RefreshTokenInput true "credentials"
 // @Success 200 {object} response.Success
 // @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
 // @Router /auth/refresh [post]
 func (o *UserAuth) RefreshTokenUser(c *gin.Context) {
-	var inputData RefreshTokenInput
+	ctx := c.Request.Context()
 
-	if err := c.ShouldBindJSON(&inputData); err != nil {
-		response.GinError(c, http.StatusBadRequest, response.CodeInvalidParams, "bad data, try again", err)
-		return
-	}
-
-	userData, err := o.db.ReadEmailRoleWithRefreshToken(inputData.RefreshToken)
-	if err != nil {
-		response.GinError(c, http.StatusUnauthorized, response.CodeBadRequest, err.Error(), err)
-		return
-	}
+	var inputData RefreshTokenInput
 
-	// Check ttl refresh token
-	if userData.ExpiresIn <= time.Now().Unix() {
-		response.GinError(c, http.StatusExpectationFailed, response.CodeUnknownUser, "Please log in", err)
+	if !bindJSON(c, &inputData) {
 		return
 	}
 
-	// Create access token
-	aToken, err := o.token.CreateToken(userData.Email, userData.Role, consts.AccessTokenTTL)
+	tokens, err := o.auth.Refresh(ctx, inputData.RefreshToken, o.getClient(c))
 	if err != nil {
-		response.GinError(c, http.StatusInternalServerError, response.CodeCryptoError, "", nil)
+		response.GinAppError(c, err)
 		return
 	}
 
-	rToken, err := o.token.CreateRefreshToken()
-	if err != nil {
-		response.GinError(c, http.StatusInternalServerError, response.CodeCryptoError, "", nil)
-		return
-	}
+	response.GinSuccess(c,
+		http.StatusOK, response.CodeOk,
+		gin.H{"aToken": tokens.Access, "rToken": tokens.Refresh}, "Token refresh")
+}
 
-	err = o.db.UpdateSessionTokenOnly(
-		inputData.RefreshToken, rToken, time.Now().Add(consts.RefreshTokenTTL).Unix())
-	if err != nil {
-		response.GinError(c, http.StatusBadRequest, response.CodeBadRequest, "", nil)
+// @Summary Verify email
+// @Tags auth
+// @Description confirm user email with token from the letter
+// @ID verify-email
+// @Produce json
+// @Param token query string true "verification token"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Router /auth/verify [get]
+func (o *UserAuth) VerifyEmail(c *gin.Context) {
+	if err := o.auth.VerifyEmail(c.Request.Context(), c.Query("token")); err != nil {
+		response.GinAppError(c, err)
 		return
 	}
 
-	response.GinSuccess(c,
-		http.StatusOK, response.CodeOk,
-		gin.H{"aToken": aToken, "rToken": rToken}, "Token refresh")
+	response.GinSuccess(c, http.StatusOK, response.CodeOk, "", "Email verified")
 }
 
-func (o *UserAuth) getIPandUserAgent(c *gin.Context) (IP, UserAgent string) {
-	val, ok := c.Request.Header["User-Agent"]
+// getClient return ip and User-Agent of the request.
+func (o *UserAuth) getClient(c *gin.Context) service.Client {
+	client := service.Client{IP: c.ClientIP()}
 
+	val, ok := c.Request.Header["User-Agent"]
 	if !ok {
 		logger.Debug("not have User-Agent Header", c.Request.Header)
 	} else {
 		// Todo: Здесь можно использовать готовые библиотеки для парсинга UserAgent
-		UserAgent = val[0]
-	}
-
-	IP = c.ClientIP()
-
-	return
-}
-
-// validateUserData check valid CreateUserInput.
-func (o *UserAuth) validateUserData(c *gin.Context, userData CreateUserInput) bool {
-	if err := validation.IsEmailValid(userData.Email); err != nil {
-		response.GinError(c, http.StatusBadRequest, response.CodeInvalidParams, err.Error(), err)
-		return false
-	}
-
-	if err := validation.IsUserNameValid(userData.Username); err != nil {
-		response.GinError(c, http.StatusBadRequest, response.CodeInvalidParams, err.Error(), err)
-		return false
-	}
-
-	if err := validation.IsPasswordValid(userData.Password); err != nil {
-		response.GinError(c, http.StatusBadRequest, response.CodeInvalidParams, err.Error(), err)
-		return false
+		client.UserAgent = val[0]
 	}
 
-	return true
+	return client
 }
@@ -6,28 +6,64 @@ import (
 	_ "github.com/Dsmit05/metida/docs"
 	"github.com/Dsmit05/metida/internal/api/controllers"
 	"github.com/Dsmit05/metida/internal/api/middlewares"
+	"github.com/Dsmit05/metida/internal/consts"
 	"github.com/Dsmit05/metida/internal/logger"
+	"github.com/Dsmit05/metida/internal/service"
+	"github.com/Dsmit05/metida/internal/validation"
 	"github.com/gin-gonic/gin"
 )
 
 type GinBuilder struct {
-	userAuth    *controllers.UserAuth
-	userContent *controllers.UserContent
-	siteBlog    *controllers.SiteBlog
+	userAuth         *controllers.UserAuth
+	userSessions     *controllers.UserSessions
+	userPassword     *controllers.UserPassword
+	adminUsers       *controllers.AdminUsers
+	userContent      *controllers.UserContent
+	contentFiles     *controllers.ContentFiles
+	contentShares    *controllers.ContentShares
+	contentRevisions *controllers.ContentRevisions
+	siteBlog         *controllers.SiteBlog
+	blogComments     *controllers.BlogComments
 	*middlewares.ProtectedMidleware
+	*middlewares.PermissionMidleware
+	*middlewares.RateLimitMidleware
 	r *gin.Engine
 }
 
 func NewGinBuilder(
 	db repositoryI,
 	managerToken cryptographyI,
+	mailSender mailSenderI,
+	permissions permissionsI,
+	loginGuard loginGuardI,
+	rateLimitStore rateLimitStoreI,
+	fileStorage fileStorageI,
 	cfg configGinBuilderI,
+	metric metricGinBuilderI,
 ) *GinBuilder {
 
-	userAuth := controllers.NewUserAuth(db, managerToken)
-	wallEditorialsHandler := controllers.NewWallEditorials(db)
-	siteBlog := controllers.NewSiteBlog(db)
+	passwordPolicy := validation.PasswordPolicy{
+		MinLength: cfg.GetPasswordMinLength(),
+		MaxLength: cfg.GetPasswordMaxLength(),
+	}
+
+	authService := service.NewAuthService(db, managerToken, mailSender, loginGuard, passwordPolicy, cfg)
+	contentService := service.NewContentService(db, fileStorage)
+	blogService := service.NewBlogService(db)
+
+	userAuth := controllers.NewUserAuth(authService, passwordPolicy)
+	userSessions := controllers.NewUserSessions(authService)
+	userPassword := controllers.NewUserPassword(authService, passwordPolicy)
+	adminUsers := controllers.NewAdminUsers(authService, permissions)
+	wallEditorialsHandler := controllers.NewWallEditorials(contentService)
+	contentFiles := controllers.NewContentFiles(db, contentService, fileStorage, cfg)
+	contentShares := controllers.NewContentShares(contentService)
+	contentRevisions := controllers.NewContentRevisions(db, contentService)
+	siteBlog := controllers.NewSiteBlog(blogService)
+	blogComments := controllers.NewBlogComments(blogService)
 	protectedMidleware := middlewares.NewProtectedMidleware(managerToken)
+	permissionMidleware := middlewares.NewPermissionMidleware(permissions)
+	rateLimitMidleware := middlewares.NewRateLimitMidleware(rateLimitStore, cfg.GetRateLimitRules(), metric)
 
 	var r *gin.Engine
 
@@ -38,11 +74,28 @@ func NewGinBuilder(
 		r = gin.New()
 	}
 
+	// ip of the client is used by rate limits and the lockout, so the headers are trusted only from own proxies
+	if err := r.SetTrustedProxies(cfg.GetTrustedProxies()); err != nil {
+		logger.Error("gin.SetTrustedProxies()", err)
+		_ = r.SetTrustedProxies(nil)
+	}
+
+	r.Use(middlewares.RequestID)
+
 	return &GinBuilder{
 		userAuth,
+		userSessions,
+		userPassword,
+		adminUsers,
 		wallEditorialsHandler,
+		contentFiles,
+		contentShares,
+		contentRevisions,
 		siteBlog,
+		blogComments,
 		protectedMidleware,
+		permissionMidleware,
+		rateLimitMidleware,
 		r,
 	}
 }
@@ -50,22 +103,98 @@ func NewGinBuilder(
 func (o *GinBuilder) AddV1(basePath string) *GinBuilder {
 	logger.Info("GinBuilder.AddV1()", "add api v1")
 	v1 := o.r.Group(basePath)
+	v1.Use(o.RateLimit(consts.RateLimitGlobal))
 
 	control := v1.Group("/auth")
+	control.Use(o.RateLimit(consts.RateLimitAuth))
 	{
 		control.POST("/sign-up", o.userAuth.CreateUser)
 		control.POST("/sign-in", o.userAuth.AuthenticationUser)
 		control.POST("/refresh", o.userAuth.RefreshTokenUser)
+		control.GET("/verify", o.userAuth.VerifyEmail)
+		control.POST("/password/forgot", o.userPassword.ForgotPassword)
+		control.POST("/password/reset", o.userPassword.ResetPassword)
 	}
 
 	lk := v1.Group("/lk")
-	lk.Use(o.AuthMidleware)
+	lk.Use(o.AuthMidleware, o.RateLimit(consts.RateLimitLk))
+	{
+		lk.GET("/content", o.RequirePermission(consts.PermissionContentRead), o.userContent.ListContent)
+		lk.GET("/content/search", o.RequirePermission(consts.PermissionContentRead), o.userContent.SearchContent)
+		lk.GET("/content/shared", o.RequirePermission(consts.PermissionContentRead), o.contentShares.ListSharedContent)
+		lk.GET("/content/:id", o.RequirePermission(consts.PermissionContentRead), o.userContent.ShowContent)
+		lk.POST("/content", o.RequirePermission(consts.PermissionContentWrite), o.userContent.CreateContent)
+		lk.PUT("/content/:id", o.RequirePermission(consts.PermissionContentWrite), o.userContent.UpdateContent)
+		lk.PATCH("/content/:id", o.RequirePermission(consts.PermissionContentWrite), o.userContent.PatchContent)
+		lk.DELETE("/content/:id", o.RequirePermission(consts.PermissionContentWrite), o.userContent.DeleteContent)
+		lk.GET("/content/:id/files", o.RequirePermission(consts.PermissionContentRead), o.contentFiles.ListFiles)
+		lk.GET("/content/:id/files/:fileId", o.RequirePermission(consts.PermissionContentRead), o.contentFiles.DownloadFile)
+		lk.POST("/content/:id/files", o.RequirePermission(consts.PermissionContentWrite), o.contentFiles.UploadFile)
+		lk.DELETE("/content/:id/files/:fileId", o.RequirePermission(consts.PermissionContentWrite), o.contentFiles.DeleteFile)
+		lk.GET("/content/:id/shares", o.RequirePermission(consts.PermissionContentWrite), o.contentShares.ListShares)
+		lk.PUT("/content/:id/shares", o.RequirePermission(consts.PermissionContentWrite), o.contentShares.ShareContent)
+		lk.DELETE("/content/:id/shares/:email", o.RequirePermission(consts.PermissionContentWrite), o.contentShares.UnshareContent)
+		lk.GET("/content/:id/revisions", o.RequirePermission(consts.PermissionContentRead), o.contentRevisions.ListRevisions)
+		lk.GET("/content/:id/revisions/diff", o.RequirePermission(consts.PermissionContentRead), o.contentRevisions.DiffRevisions)
+		lk.GET("/content/:id/revisions/:rev", o.RequirePermission(consts.PermissionContentRead), o.contentRevisions.ShowRevision)
+		lk.POST("/content/:id/revisions/:rev/restore", o.RequirePermission(consts.PermissionContentWrite), o.contentRevisions.RestoreRevision)
+		lk.GET("/content/:id/links", o.RequirePermission(consts.PermissionContentWrite), o.contentShares.ListLinks)
+		lk.POST("/content/:id/links", o.RequirePermission(consts.PermissionContentWrite), o.contentShares.CreateLink)
+		lk.DELETE("/content/:id/links/:linkId", o.RequirePermission(consts.PermissionContentWrite), o.contentShares.DeleteLink)
+		lk.POST("/blog", o.RequirePermission(consts.PermissionBlogCreate), o.siteBlog.CreateBlog)
+		lk.GET("/blog", o.RequirePermission(consts.PermissionBlogUpdate), o.siteBlog.ListAllBlogs)
+		lk.GET("/blog/:id", o.RequirePermission(consts.PermissionBlogUpdate), o.siteBlog.ShowAnyBlog)
+		lk.PUT("/blog/:id", o.RequirePermission(consts.PermissionBlogUpdate), o.siteBlog.UpdateBlog)
+		lk.DELETE("/blog/:id", o.RequirePermission(consts.PermissionBlogDelete), o.siteBlog.DeleteBlog)
+		lk.POST("/blog/:id/publish", o.RequirePermission(consts.PermissionBlogPublish), o.siteBlog.PublishBlog)
+		lk.POST("/blog/:id/archive", o.RequirePermission(consts.PermissionBlogPublish), o.siteBlog.ArchiveBlog)
+		lk.POST("/blog/:id/unpublish", o.RequirePermission(consts.PermissionBlogPublish), o.siteBlog.UnpublishBlog)
+		lk.POST("/blog/:id/comments", o.RequirePermission(consts.PermissionCommentsWrite), o.blogComments.CreateComment)
+		lk.PUT("/comments/:id", o.RequirePermission(consts.PermissionCommentsWrite), o.blogComments.UpdateComment)
+		lk.DELETE("/comments/:id", o.RequirePermission(consts.PermissionCommentsWrite), o.blogComments.DeleteComment)
+
+		lk.GET("/sessions", o.userSessions.ListSessions)
+		lk.DELETE("/sessions", o.userSessions.RevokeOtherSessions)
+		lk.DELETE("/sessions/:id", o.userSessions.RevokeSession)
+		lk.POST("/logout", o.userSessions.Logout)
+		lk.PUT("/password", o.userPassword.ChangePassword)
+	}
+
+	blog := v1.Group("/blog")
+	{
+		blog.GET("", o.siteBlog.ListBlogs)
+		blog.GET("/search", o.siteBlog.SearchBlogs)
+		blog.GET("/:id", o.siteBlog.ShowBlog)
+		blog.GET("/slug/:slug", o.siteBlog.ShowBlogBySlug)
+		blog.GET("/:id/comments", o.blogComments.ListComments)
+	}
+	v1.GET("/tags", o.siteBlog.ListTags)
+
+	shared := v1.Group("/shared")
+	{
+		shared.GET("/:token", o.contentShares.ShowSharedContent)
+		shared.GET("/:token/files/:fileId", o.contentFiles.DownloadSharedFile)
+	}
+
+	admin := v1.Group("/admin")
+	admin.Use(o.AuthMidleware, o.RateLimit(consts.RateLimitAdmin))
+
+	users := admin.Group("/users", o.RequirePermission(consts.PermissionUsersManage))
+	{
+		users.GET("", o.adminUsers.ListUsers)
+		users.GET("/:id", o.adminUsers.ShowUser)
+		users.PUT("/:id/role", o.adminUsers.UpdateUserRole)
+		users.DELETE("/:id", o.adminUsers.DeleteUser)
+		users.POST("/:id/restore", o.adminUsers.RestoreUser)
+	}
+
+	comments := admin.Group("/comments", o.RequirePermission(consts.PermissionCommentsModerate))
 	{
-		lk.GET("/content/:id", o.userContent.ShowContent)
-		lk.POST("/content", o.userContent.CreateContent)
-		lk.POST("/blog", o.siteBlog.CreateBlog)
+		comments.GET("", o.blogComments.ModerationQueue)
+		comments.POST("/:id/approve", o.blogComments.ApproveComment)
+		comments.POST("/:id/reject", o.blogComments.RejectComment)
+		comments.POST("/:id/hide", o.blogComments.HideComment)
 	}
-	v1.GET("/blog/:id", o.siteBlog.ShowBlog)
 
 	return o
 }
@@ -1,33 +1,132 @@
 package api
 
 import (
+	"context"
+	"io"
 	"net/http"
 	"time"
 
+	"github.com/Dsmit05/metida/internal/cryptography"
+	"github.com/Dsmit05/metida/internal/mail"
 	"github.com/Dsmit05/metida/internal/models"
+	"github.com/Dsmit05/metida/internal/ratelimit"
 )
 
 type repositoryI interface {
-	CreateUser(name string, password string, email string, role string) error
-	ReadUser(email string) (*models.User, error)
-	UpdateUser(email string, name string, password string, role string, isDeleted bool) error
-	DeleteUser(email string) error
-	CreateSession(email string, refreshToken string, userAgent string, ip string, expiresIn int64) error
-	ReadSession(email string, userAgent string, ip string) (*models.Session, error)
-	UpdateSession(email string, refreshToken string, newRefreshToken string, expiresIn int64) error
-	UpdateSessionTokenOnly(refreshToken string, newRefreshToken string, expiresIn int64) error
-	ReadEmailRoleWithRefreshToken(refreshToken string) (*models.UserEmailRole, error)
-	DeleteSession(email string, ip string, userAgent string) error
-	CreatContent(email string, name string, description string) error
-	ReadContent(email string, id int32) (*models.Content, error)
-	CreatBlog(name string, description string) error
-	ReadBlog(id int32) (*models.Blog, error)
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	WithinSerializableTx(ctx context.Context, fn func(ctx context.Context) error) error
+	CreateUser(ctx context.Context, name string, password string, email string, role string) (int32, error)
+	ReadUser(ctx context.Context, email string) (*models.User, error)
+	UpdateUser(ctx context.Context, email string, name string, password string, role string, isDeleted bool) error
+	DeleteUser(ctx context.Context, email string) error
+	VerifyUser(ctx context.Context, email string) error
+	UpdateUserPassword(ctx context.Context, email string, password string) error
+	CreatePasswordResetToken(ctx context.Context, email string, tokenHash string, expiresIn int64) error
+	UsePasswordResetToken(ctx context.Context, tokenHash string) (string, error)
+	RevokePasswordResetTokens(ctx context.Context, email string) error
+	ReadUserByID(ctx context.Context, id int32) (*models.User, error)
+	ListUsers(ctx context.Context, search string, withDeleted bool, limit, offset int32) ([]models.User, error)
+	CountUsers(ctx context.Context, search string, withDeleted bool) (int64, error)
+	UpdateUserRole(ctx context.Context, id int32, role string) (string, error)
+	DeleteUserByID(ctx context.Context, id int32) (string, error)
+	RestoreUser(ctx context.Context, id int32) error
+	CreateSession(ctx context.Context, email string, refreshToken string, userAgent string, ip string, expiresIn int64) (int32, error)
+	ReadSession(ctx context.Context, email string, userAgent string, ip string) (*models.Session, error)
+	UpdateSession(ctx context.Context, email string, refreshToken string, newRefreshToken string, expiresIn int64) error
+	UpdateSessionTokenOnly(ctx context.Context, refreshToken string, newRefreshToken string, expiresIn int64) error
+	RotateRefreshToken(ctx context.Context,
+		sessionID int32, email string, refreshToken string, newRefreshToken string, expiresIn int64) error
+	ReadRetiredRefreshToken(ctx context.Context, refreshToken string) (*models.RetiredRefreshToken, error)
+	ReadEmailRoleWithRefreshToken(ctx context.Context, refreshToken string) (*models.UserEmailRole, error)
+	DeleteSession(ctx context.Context, email string, ip string, userAgent string) error
+	ListSessions(ctx context.Context, email string) ([]models.Session, error)
+	UpdateSessionAccessToken(ctx context.Context, sessionID int32, tokenID string, expiresIn int64) error
+	DeleteSessionByID(ctx context.Context, email string, id int32) (*models.Session, error)
+	DeleteOtherSessions(ctx context.Context, email string, currentID int32) ([]models.Session, error)
+	DeleteUserSessions(ctx context.Context, email string) ([]models.Session, error)
+	CreatContent(ctx context.Context, email string, name string, description string) (int32, error)
+	ReadContent(ctx context.Context, email string, id int32) (*models.Content, error)
+	ListContent(ctx context.Context, email string, filter models.ContentFilter) ([]models.Content, error)
+	UpdateContent(ctx context.Context, email string, id int32, name, description string) (*models.Content, error)
+	DeleteContent(ctx context.Context, email string, id int32) error
+	SearchContent(ctx context.Context, email, query string, limit, offset int32) ([]models.ContentSearchResult, error)
+	CountSearchContent(ctx context.Context, email, query string) (int64, error)
+	ReadContentRevision(ctx context.Context, contentID, revision int32) (*models.ContentRevision, error)
+	ListContentRevisions(ctx context.Context, contentID, limit, offset int32) ([]models.ContentRevision, error)
+	CountContentRevisions(ctx context.Context, contentID int32) (int64, error)
+	RestoreContentRevision(ctx context.Context, email string, id, revision int32) (*models.Content, error)
+	ShareContent(ctx context.Context, contentID int32, email, permission string) (*models.ContentShare, error)
+	UnshareContent(ctx context.Context, contentID int32, email string) error
+	ListContentShares(ctx context.Context, contentID int32) ([]models.ContentShare, error)
+	ListSharedContent(ctx context.Context, email string, limit, offset int32) ([]models.Content, error)
+	CountSharedContent(ctx context.Context, email string) (int64, error)
+	CreateContentLink(ctx context.Context, contentID int32, tokenHash string, expiresIn int64) (*models.ContentLink, error)
+	ListContentLinks(ctx context.Context, contentID int32) ([]models.ContentLink, error)
+	DeleteContentLink(ctx context.Context, contentID, id int32) error
+	ReadContentByLink(ctx context.Context, tokenHash string) (*models.Content, int64, error)
+	CreateAttachment(ctx context.Context, contentID int32, email string,
+		fileName, contentType string, size int64, storageKey string) (*models.Attachment, error)
+	ReadAttachment(ctx context.Context, contentID, id int32) (*models.Attachment, error)
+	ListAttachments(ctx context.Context, contentID int32) ([]models.Attachment, error)
+	DeleteAttachment(ctx context.Context, contentID, id int32) (string, error)
+	ListAttachmentKeys(ctx context.Context, contentID int32) ([]string, error)
+	CreatBlog(ctx context.Context, authorEmail, name, slug, description string) (int32, error)
+	ReadBlog(ctx context.Context, id int32) (*models.Blog, error)
+	ReadBlogBySlug(ctx context.Context, slug string) (*models.Blog, error)
+	ListBlogs(ctx context.Context, status, tag string, limit, offset int32) ([]models.Blog, error)
+	CountBlogs(ctx context.Context, status, tag string) (int64, error)
+	UpdateBlog(ctx context.Context, id int32, name, slug, description string) (*models.Blog, error)
+	UpdateBlogStatus(ctx context.Context, id int32, status string) (*models.Blog, error)
+	DeleteBlog(ctx context.Context, id int32) error
+	SearchBlogs(ctx context.Context, query string, limit, offset int32) ([]models.BlogSearchResult, error)
+	CountSearchBlogs(ctx context.Context, query string) (int64, error)
+	SetBlogTags(ctx context.Context, blogID int32, tags []models.Tag) ([]models.Tag, error)
+	ListTags(ctx context.Context) ([]models.Tag, error)
+	CreateComment(ctx context.Context, blogID, parentID int32, authorEmail, body string) (*models.Comment, error)
+	ReadComment(ctx context.Context, id int32) (*models.Comment, error)
+	UpdateCommentBody(ctx context.Context, id int32, authorEmail, body string) (*models.Comment, error)
+	DeleteComment(ctx context.Context, id int32, authorEmail string) error
+	UpdateCommentStatus(ctx context.Context, id int32, status string) (*models.Comment, error)
+	ListBlogComments(ctx context.Context, blogID, limit, offset int32) ([]models.Comment, error)
+	CountBlogComments(ctx context.Context, blogID int32) (int64, error)
+	ListCommentsByStatus(ctx context.Context, status string, limit, offset int32) ([]models.Comment, error)
+	CountCommentsByStatus(ctx context.Context, status string) (int64, error)
 }
 
 type cryptographyI interface {
-	CreateToken(email string, role string, ttl time.Duration) (string, error)
-	ParseToken(inputToken string) (email string, role string, err error)
+	CreateToken(subject cryptography.TokenSubject, ttl time.Duration) (string, string, error)
+	ParseToken(inputToken string) (*cryptography.UserClaims, error)
 	CreateRefreshToken() (string, error)
+	CreateVerificationToken(email string, ttl time.Duration) (string, error)
+	ParseVerificationToken(inputToken string) (email string, err error)
+	RevokeToken(tokenID string, expiresAt int64)
+	IsTokenRevoked(tokenID string) bool
+	JWKS() cryptography.JSONWebKeySet
+}
+
+type loginGuardI interface {
+	Check(ctx context.Context, email, ip string) (time.Duration, error)
+	Fail(ctx context.Context, email, ip string) error
+	Success(ctx context.Context, email string) error
+}
+
+type rateLimitStoreI interface {
+	Take(ctx context.Context, key string, rate ratelimit.Rate) (ratelimit.Result, error)
+}
+
+type fileStorageI interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type permissionsI interface {
+	HasPermission(role, permission string) bool
+	Roles() []string
+}
+
+type mailSenderI interface {
+	Send(ctx context.Context, msg mail.Message) error
 }
 
 type configApiI interface {
@@ -37,12 +136,29 @@ type configApiI interface {
 	GetApiReadTimeout() time.Duration
 	GetApiWriteTimeout() time.Duration
 	GetCorsAllowedOrigins() []string
+	configGinBuilderI
 }
 
 type configGinBuilderI interface {
 	IfDebagOn() bool
+	IsEmailVerificationRequired() bool
+	GetVerificationTokenTTL() time.Duration
+	GetVerificationURL() string
+	GetPasswordResetTokenTTL() time.Duration
+	GetPasswordResetURL() string
+	GetRateLimitRules() map[string]ratelimit.Rule
+	GetAttachmentMaxSize() int64
+	GetAttachmentAllowedTypes() []string
+	GetPasswordMinLength() int
+	GetPasswordMaxLength() int
+	GetTrustedProxies() []string
 }
 
 type metricI interface {
 	MetricsMiddleware(next http.Handler) http.Handler
+	metricGinBuilderI
+}
+
+type metricGinBuilderI interface {
+	IncRateLimitRejected(group string)
 }
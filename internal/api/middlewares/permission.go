@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"github.com/Dsmit05/metida/internal/api/response"
+	"github.com/Dsmit05/metida/internal/apperr"
+	"github.com/gin-gonic/gin"
+)
+
+type permissionsI interface {
+	HasPermission(role, permission string) bool
+}
+
+// PermissionMidleware checks permissions of the user role, must be used after AuthMidleware.
+type PermissionMidleware struct {
+	permissions permissionsI
+}
+
+func NewPermissionMidleware(permissions permissionsI) *PermissionMidleware {
+	return &PermissionMidleware{permissions: permissions}
+}
+
+// RequirePermission return middleware which allows request only if the role has the permission.
+func (o *PermissionMidleware) RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		if role == "" || !o.permissions.HasPermission(role, permission) {
+			response.GinAppError(c, apperr.Wrap(apperr.Forbidden,
+				"You has no enough rights for access to resource.", ErrUserRole))
+			return
+		}
+	}
+}
@@ -3,17 +3,21 @@ package middlewares
 import (
 	"errors"
 	"fmt"
-	"net/http"
 
 	"github.com/Dsmit05/metida/internal/api/response"
+	"github.com/Dsmit05/metida/internal/apperr"
 
 	"github.com/Dsmit05/metida/internal/cryptography"
 
 	"github.com/gin-gonic/gin"
 )
 
+// maxAuthHeaderLength limits the size of the token in Authorizations header.
+const maxAuthHeaderLength = 1024
+
 var (
-	ErrUserRole = errors.New("the user has a negative role")
+	ErrUserRole     = errors.New("the user has a negative role")
+	ErrTokenRevoked = errors.New("the token has been revoked")
 )
 
 type ProtectedMidleware struct {
@@ -25,36 +29,30 @@ func NewProtectedMidleware(token cryptography.ManagerToken) *ProtectedMidleware
 }
 
 func (o *ProtectedMidleware) AuthMidleware(c *gin.Context) {
-	email, role, err := o.parseAuthHeader(c)
+	claims, err := o.parseAuthHeader(c)
 	if err != nil {
-		response.GinError(c, http.StatusExpectationFailed, response.CodeUnknownUser, "Please log in", err)
+		response.GinAppError(c, apperr.Wrap(apperr.Unauthorized, "Please log in", err))
 		return
 	}
 
-	c.Set("email", email)
-	c.Set("role", role)
-}
-
-func (o *ProtectedMidleware) parseAuthHeader(c *gin.Context) (email, role string, err error) {
-	header := c.GetHeader("Authorizations")
-	if header == "" || len(header) > 250 {
-		return "", "", fmt.Errorf("bad header")
+	if o.auth.IsTokenRevoked(claims.Id) {
+		response.GinAppError(c, apperr.Wrap(apperr.Unauthorized, "Please log in", ErrTokenRevoked))
+		return
 	}
 
-	return o.auth.ParseToken(header)
+	c.Set("userID", claims.UserID())
+	c.Set("email", claims.Email)
+	c.Set("role", claims.Role)
+	c.Set("sessionID", claims.SessionID)
+	c.Set("tokenID", claims.Id)
+	c.Set("tokenExpiresAt", claims.ExpiresAt)
 }
 
-func CheckAccessRights(c *gin.Context, roles ...string) error {
-	roleFromContext, ok := c.Get("role")
-	if !ok {
-		return ErrUserRole
-	}
-
-	for _, role := range roles {
-		if roleFromContext == role {
-			return nil
-		}
+func (o *ProtectedMidleware) parseAuthHeader(c *gin.Context) (*cryptography.UserClaims, error) {
+	header := c.GetHeader("Authorizations")
+	if header == "" || len(header) > maxAuthHeaderLength {
+		return nil, fmt.Errorf("bad header")
 	}
 
-	return ErrUserRole
+	return o.auth.ParseToken(header)
 }
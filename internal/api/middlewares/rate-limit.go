@@ -0,0 +1,86 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Dsmit05/metida/internal/api/response"
+	"github.com/Dsmit05/metida/internal/apperr"
+	"github.com/Dsmit05/metida/internal/logger"
+	"github.com/Dsmit05/metida/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+var ErrRateLimit = errors.New("rate limit exceeded")
+
+type rateLimitStoreI interface {
+	Take(ctx context.Context, key string, rate ratelimit.Rate) (ratelimit.Result, error)
+}
+
+type rateLimitMetricI interface {
+	IncRateLimitRejected(group string)
+}
+
+// RateLimitMidleware throttles requests with token buckets, rules are set per route group.
+type RateLimitMidleware struct {
+	store  rateLimitStoreI
+	rules  map[string]ratelimit.Rule
+	metric rateLimitMetricI
+}
+
+func NewRateLimitMidleware(
+	store rateLimitStoreI, rules map[string]ratelimit.Rule, metric rateLimitMetricI) *RateLimitMidleware {
+	return &RateLimitMidleware{store: store, rules: rules, metric: metric}
+}
+
+// RateLimit return middleware with the rule of the group, if the group has no rule requests are not limited.
+// Groups keyed by email must be used after AuthMidleware.
+func (o *RateLimitMidleware) RateLimit(group string) gin.HandlerFunc {
+	rule, ok := o.rules[group]
+	if !ok {
+		return func(c *gin.Context) {}
+	}
+
+	return func(c *gin.Context) {
+		result, err := o.store.Take(c.Request.Context(), group+":"+o.key(c, rule.Key), rule.Rate)
+		if err != nil {
+			// the store is unavailable, requests are not blocked
+			logger.Error("RateLimitMidleware.store.Take()", err)
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", formatSeconds(result.Reset))
+
+		if !result.Allowed {
+			o.metric.IncRateLimitRejected(group)
+			response.GinAppError(c, &apperr.Error{Kind: apperr.TooManyRequests,
+				Msg: "too many requests, please try again later", Err: ErrRateLimit, RetryAfter: result.RetryAfter})
+			return
+		}
+	}
+}
+
+// key return the bucket key of the request.
+func (o *RateLimitMidleware) key(c *gin.Context, keyMode string) string {
+	ip := c.ClientIP()
+	email := c.GetString("email")
+
+	switch {
+	case keyMode == ratelimit.KeyEmail && email != "":
+		return "email:" + email
+	case keyMode == ratelimit.KeyIPEmail && email != "":
+		return "ip:" + ip + ":email:" + email
+	default:
+		return "ip:" + ip
+	}
+}
+
+// formatSeconds rounds duration up to whole seconds.
+func formatSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
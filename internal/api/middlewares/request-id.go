@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// RequestIDHeader the id is taken from the proxy or is made and returned in the same header.
+	RequestIDHeader = "X-Request-ID"
+	// maxRequestIDLength ids of the proxy which are longer are replaced.
+	maxRequestIDLength = 64
+)
+
+// RequestID sets id of the request for logs and error answers.
+func RequestID(c *gin.Context) {
+	id := c.GetHeader(RequestIDHeader)
+	if !isRequestIDValid(id) {
+		id = uuid.NewString()
+	}
+
+	c.Set("requestID", id)
+	c.Header(RequestIDHeader, id)
+}
+
+// isRequestIDValid allows only printable ascii without spaces, so the id is safe for logs and headers.
+func isRequestIDValid(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
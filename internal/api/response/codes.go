@@ -1,16 +1,42 @@
 package response
 
-// Internal server Response Codes
+// Internal server Response Codes, the values are sent to clients, so they must not be changed.
 const (
-	CodeOk = iota
-	CodeUnknownException
-	CodeForbidden
-	CodeInvalidParams
-	CodeNotFound
-	CodeBadRequest
-	CodeInvalidJsonConversion
-	CodeUserPasswordIsEmpty
-	CodeUnknownUser
-	CodeCryptoError
-	CodeDBError
+	CodeOk                    = 0
+	CodeUnknownException      = 1
+	CodeForbidden             = 2
+	CodeInvalidParams         = 3
+	CodeNotFound              = 4
+	CodeBadRequest            = 5
+	CodeInvalidJsonConversion = 6
+	CodeUserPasswordIsEmpty   = 7
+	CodeUnknownUser           = 8
+	CodeCryptoError           = 9
+	CodeDBError               = 10
+	CodeTooManyRequests       = 11
+	CodeConflict              = 12
 )
+
+// problemTypeBase prefix of the type URIs of problem responses.
+const problemTypeBase = "urn:metida:problem:"
+
+type problemType struct {
+	name  string
+	title string
+}
+
+// problemTypes stable names of the codes in problem responses.
+var problemTypes = map[int]problemType{
+	CodeUnknownException:      {"internal", "Internal error"},
+	CodeForbidden:             {"forbidden", "Access is denied"},
+	CodeInvalidParams:         {"invalid-params", "Invalid input params"},
+	CodeNotFound:              {"not-found", "Object not found"},
+	CodeBadRequest:            {"bad-request", "Bad request"},
+	CodeInvalidJsonConversion: {"invalid-json", "Invalid JSON body"},
+	CodeUserPasswordIsEmpty:   {"empty-password", "Password is empty"},
+	CodeUnknownUser:           {"unauthorized", "User is not authorized"},
+	CodeCryptoError:           {"crypto", "Cryptography error"},
+	CodeDBError:               {"database", "Database error"},
+	CodeTooManyRequests:       {"too-many-requests", "Too many requests"},
+	CodeConflict:              {"conflict", "Object already exists"},
+}
@@ -1,10 +1,10 @@
 package response
 
 import (
-	"fmt"
-	"github.com/Dsmit05/metida/internal/logger"
 	"net/http"
 
+	"github.com/Dsmit05/metida/internal/apperr"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -14,9 +14,11 @@ type Error struct {
 	Error       string `json:"error" example:"status bad request"`
 }
 
-func ginError(c *gin.Context, status, code int, description string, err error) {
-	if err == nil {
-		err = fmt.Errorf(http.StatusText(status))
+// ginError answers by RFC 7807 or by the legacy envelope, it is chosen by Accept header.
+func ginError(c *gin.Context, status, code int, description string, fields []apperr.FieldError) {
+	if acceptsProblem(c) {
+		ginProblem(c, status, code, description, fields)
+		return
 	}
 
 	if len(description) == 0 {
@@ -26,14 +28,8 @@ func ginError(c *gin.Context, status, code int, description string, err error) {
 	newErr := Error{
 		Code:        code,
 		Description: description,
-		Error:       err.Error(),
+		Error:       http.StatusText(status),
 	}
 
 	c.JSON(status, newErr)
 }
-
-func GinError(c *gin.Context, status, code int, description string, err error) {
-	logger.RequestsError(c, status, code, description, err)
-	c.Abort()
-	ginError(c, status, code, description, err)
-}
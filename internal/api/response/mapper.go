@@ -0,0 +1,51 @@
+package response
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/Dsmit05/metida/internal/apperr"
+	"github.com/Dsmit05/metida/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// FromError derives status, code and description of the answer from kind of the error,
+// the description of internal errors is empty, so their details are not shown.
+func FromError(err error) (status, code int, description string) {
+	description = apperr.Message(err)
+
+	switch apperr.KindOf(err) {
+	case apperr.Validation:
+		return http.StatusBadRequest, CodeInvalidParams, description
+	case apperr.NotFound:
+		return http.StatusNotFound, CodeNotFound, description
+	case apperr.Conflict:
+		return http.StatusConflict, CodeConflict, description
+	case apperr.Unauthorized:
+		return http.StatusUnauthorized, CodeUnknownUser, description
+	case apperr.Forbidden:
+		return http.StatusForbidden, CodeForbidden, description
+	case apperr.TooManyRequests:
+		return http.StatusTooManyRequests, CodeTooManyRequests, description
+	case apperr.TooLarge:
+		return http.StatusRequestEntityTooLarge, CodeInvalidParams, description
+	case apperr.UnsupportedType:
+		return http.StatusUnsupportedMediaType, CodeInvalidParams, description
+	default:
+		return http.StatusInternalServerError, CodeUnknownException, ""
+	}
+}
+
+// GinAppError answers by kind of the error, the whole error with its cause is only logged.
+func GinAppError(c *gin.Context, err error) {
+	status, code, description := FromError(err)
+
+	if retryAfter := apperr.RetryAfter(err); retryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+
+	logger.RequestsError(c, status, code, description, err)
+	c.Abort()
+	ginError(c, status, code, description, apperr.Fields(err))
+}
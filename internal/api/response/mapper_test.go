@@ -0,0 +1,54 @@
+package response
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Dsmit05/metida/internal/apperr"
+)
+
+func TestFromError(t *testing.T) {
+	cause := errors.New("pq: connection refused")
+
+	var tests = []struct {
+		name     string
+		err      error
+		wantCode int
+		status   int
+		desc     string
+	}{
+		{name: "Case-1: validation", err: apperr.New(apperr.Validation, "bad email"),
+			status: http.StatusBadRequest, wantCode: CodeInvalidParams, desc: "bad email"},
+		{name: "Case-2: not found", err: apperr.New(apperr.NotFound, "User Not Found"),
+			status: http.StatusNotFound, wantCode: CodeNotFound, desc: "User Not Found"},
+		{name: "Case-3: conflict", err: apperr.New(apperr.Conflict, "User Is Exist"),
+			status: http.StatusConflict, wantCode: CodeConflict, desc: "User Is Exist"},
+		{name: "Case-4: unauthorized with cause", err: apperr.Wrap(apperr.Unauthorized, "Please log in", cause),
+			status: http.StatusUnauthorized, wantCode: CodeUnknownUser, desc: "Please log in"},
+		{name: "Case-5: forbidden", err: apperr.New(apperr.Forbidden, "no rights"),
+			status: http.StatusForbidden, wantCode: CodeForbidden, desc: "no rights"},
+		{name: "Case-6: too many requests", err: apperr.New(apperr.TooManyRequests, "wait"),
+			status: http.StatusTooManyRequests, wantCode: CodeTooManyRequests, desc: "wait"},
+		{name: "Case-7: internal message is hidden", err: apperr.Wrap(apperr.Internal, "pls, Try again", cause),
+			status: http.StatusInternalServerError, wantCode: CodeUnknownException},
+		{name: "Case-8: untyped error is internal", err: cause,
+			status: http.StatusInternalServerError, wantCode: CodeUnknownException},
+		{name: "Case-9: wrapped typed error", err: fmt.Errorf("read: %w", apperr.New(apperr.NotFound, "Content Not Found")),
+			status: http.StatusNotFound, wantCode: CodeNotFound, desc: "Content Not Found"},
+		{name: "Case-10: too large", err: apperr.New(apperr.TooLarge, "file is too large"),
+			status: http.StatusRequestEntityTooLarge, wantCode: CodeInvalidParams, desc: "file is too large"},
+		{name: "Case-11: unsupported type", err: apperr.New(apperr.UnsupportedType, "file type is not allowed"),
+			status: http.StatusUnsupportedMediaType, wantCode: CodeInvalidParams, desc: "file type is not allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, code, desc := FromError(tt.err)
+			if status != tt.status || code != tt.wantCode || desc != tt.desc {
+				t.Errorf("FromError() = %v, %v, %q, want %v, %v, %q",
+					status, code, desc, tt.status, tt.wantCode, tt.desc)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package response
+
+import (
+	"net/http"
+
+	"github.com/Dsmit05/metida/internal/apperr"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// MIMEProblemJSON media type of RFC 7807 responses.
+const MIMEProblemJSON = "application/problem+json"
+
+// Problem error answer by RFC 7807, it is sent if the client accepts application/problem+json.
+type Problem struct {
+	Type          string              `json:"type" example:"urn:metida:problem:invalid-params"`
+	Title         string              `json:"title" example:"Invalid input params"`
+	Status        int                 `json:"status" example:"400"`
+	Detail        string              `json:"detail,omitempty" example:"email: mail: no angle-addr"`
+	Instance      string              `json:"instance" example:"/api/v1/auth/sign-up"`
+	RequestID     string              `json:"requestId,omitempty" example:"9f1c2a9e-5a5b-4c1e-9d55-2f1d5c3b7a10"`
+	InvalidParams []apperr.FieldError `json:"invalidParams,omitempty"`
+}
+
+func newProblem(c *gin.Context, status, code int, description string, fields []apperr.FieldError) Problem {
+	problem := Problem{
+		Type:          "about:blank",
+		Title:         http.StatusText(status),
+		Status:        status,
+		Detail:        description,
+		Instance:      c.Request.URL.Path,
+		RequestID:     c.GetString("requestID"),
+		InvalidParams: fields,
+	}
+
+	if t, ok := problemTypes[code]; ok {
+		problem.Type = problemTypeBase + t.name
+		problem.Title = t.title
+	}
+
+	return problem
+}
+
+// acceptsProblem the legacy envelope is sent unless the client asks for problem+json.
+func acceptsProblem(c *gin.Context) bool {
+	return c.NegotiateFormat(binding.MIMEJSON, MIMEProblemJSON) == MIMEProblemJSON
+}
+
+func ginProblem(c *gin.Context, status, code int, description string, fields []apperr.FieldError) {
+	c.Header("Content-Type", MIMEProblemJSON)
+	c.JSON(status, newProblem(c, status, code, description, fields))
+}
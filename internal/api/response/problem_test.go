@@ -0,0 +1,94 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Dsmit05/metida/internal/apperr"
+	"github.com/Dsmit05/metida/internal/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestGinAppErrorFormat(t *testing.T) {
+	logger.ZapLog = zap.NewNop()
+	gin.SetMode(gin.TestMode)
+
+	err := apperr.Invalid(apperr.FieldError{Field: "email", Message: "bad email"})
+
+	var tests = []struct {
+		name        string
+		accept      string
+		wantProblem bool
+	}{
+		{name: "Case-1: no accept header", accept: ""},
+		{name: "Case-2: json", accept: "application/json"},
+		{name: "Case-3: any", accept: "*/*"},
+		{name: "Case-4: problem json", accept: "application/problem+json", wantProblem: true},
+		{name: "Case-5: problem json is preferred", accept: "application/problem+json, application/json", wantProblem: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/sign-up", nil)
+			if tt.accept != "" {
+				c.Request.Header.Set("Accept", tt.accept)
+			}
+			c.Set("requestID", "req-1")
+
+			GinAppError(c, err)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("GinAppError() status = %v, want %v", w.Code, http.StatusBadRequest)
+			}
+
+			if !tt.wantProblem {
+				var legacy Error
+				if err := json.Unmarshal(w.Body.Bytes(), &legacy); err != nil {
+					t.Fatal(err)
+				}
+				if legacy.Code != CodeInvalidParams || legacy.Description != "email: bad email" {
+					t.Errorf("GinAppError() legacy = %+v", legacy)
+				}
+				return
+			}
+
+			if got := w.Header().Get("Content-Type"); got != MIMEProblemJSON {
+				t.Errorf("GinAppError() content type = %v, want %v", got, MIMEProblemJSON)
+			}
+
+			var problem Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			want := Problem{
+				Type:          "urn:metida:problem:invalid-params",
+				Title:         "Invalid input params",
+				Status:        http.StatusBadRequest,
+				Detail:        "email: bad email",
+				Instance:      "/api/v1/auth/sign-up",
+				RequestID:     "req-1",
+				InvalidParams: []apperr.FieldError{{Field: "email", Message: "bad email"}},
+			}
+			if problem.Type != want.Type || problem.Title != want.Title || problem.Status != want.Status ||
+				problem.Detail != want.Detail || problem.Instance != want.Instance || problem.RequestID != want.RequestID ||
+				len(problem.InvalidParams) != 1 || problem.InvalidParams[0].Field != "email" ||
+				problem.InvalidParams[0].Message != "bad email" {
+				t.Errorf("GinAppError() problem = %+v, want %+v", problem, want)
+			}
+		})
+	}
+}
+
+func TestProblemTypesAreUnique(t *testing.T) {
+	seen := make(map[string]int, len(problemTypes))
+	for code, problem := range problemTypes {
+		if other, ok := seen[problem.name]; ok {
+			t.Errorf("codes %v and %v have the same problem type %q", code, other, problem.name)
+		}
+		seen[problem.name] = code
+	}
+}
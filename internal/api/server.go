@@ -2,9 +2,11 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 
+	"github.com/Dsmit05/metida/internal/api/middlewares"
 	"github.com/Dsmit05/metida/internal/logger"
 	"github.com/Dsmit05/metida/internal/utils"
 	"github.com/rs/cors"
@@ -19,16 +21,30 @@ type ApiServer struct {
 func NewApiServer(
 	db repositoryI,
 	managerToken cryptographyI,
+	mailSender mailSenderI,
+	permissions permissionsI,
+	loginGuard loginGuardI,
+	rateLimitStore rateLimitStoreI,
+	fileStorage fileStorageI,
 	cfg configApiI,
 	metric metricI) *ApiServer {
 
-	ginBuilder := NewGinBuilder(db, managerToken, cfg).AddV1("/api/v1")
+	ginBuilder := NewGinBuilder(db, managerToken, mailSender, permissions, loginGuard, rateLimitStore, fileStorage, cfg, metric).AddV1("/api/v1")
 
 	serveMux := utils.RouterComposition(utils.Hanlde{
 		Pattern: "/api/",
 		Handler: ginBuilder,
 	})
 
+	// Public keys for verification of access tokens by other services
+	serveMux.HandleFunc("/.well-known/jwks.json", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		if err := json.NewEncoder(w).Encode(managerToken.JWKS()); err != nil {
+			logger.Error("jwks encoding error", err)
+		}
+	})
+
 	serveMux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
 		if req.URL.Path != "/" {
 			http.NotFound(w, req)
@@ -39,9 +55,14 @@ func NewApiServer(
 
 	metricMiddl := metric.MetricsMiddleware(serveMux)
 
+	exposedHeaders := []string{
+		"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", middlewares.RequestIDHeader,
+	}
+
 	corsProvided := cors.New(cors.Options{
 		AllowedOrigins:   cfg.GetCorsAllowedOrigins(),
-		AllowedHeaders:   []string{"Authorizations", "Content-Type"},
+		AllowedHeaders:   []string{"Authorizations", "Content-Type", "Accept", middlewares.RequestIDHeader},
+		ExposedHeaders:   exposedHeaders,
 		AllowCredentials: true,
 		Debug:            cfg.IfDebagOn(),
 	})
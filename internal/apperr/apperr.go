@@ -0,0 +1,148 @@
+// Package apperr defines kinds of errors shared by the repository, services and transports.
+package apperr
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Kind of the error, the transport chooses the answer by it.
+type Kind int
+
+const (
+	// Internal the cause is hidden from the client.
+	Internal Kind = iota
+	// Validation input breaks the rules.
+	Validation
+	// NotFound the object does not exist or is hidden from the user.
+	NotFound
+	// Conflict the object already exists or was changed.
+	Conflict
+	// Unauthorized the user is not recognized.
+	Unauthorized
+	// Forbidden the user is recognized, but is not allowed to do it.
+	Forbidden
+	// TooManyRequests the client must wait RetryAfter.
+	TooManyRequests
+	// TooLarge input exceeds the size limit.
+	TooLarge
+	// UnsupportedType input has a type which is not allowed.
+	UnsupportedType
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Validation:
+		return "validation"
+	case NotFound:
+		return "not found"
+	case Conflict:
+		return "conflict"
+	case Unauthorized:
+		return "unauthorized"
+	case Forbidden:
+		return "forbidden"
+	case TooManyRequests:
+		return "too many requests"
+	case TooLarge:
+		return "too large"
+	case UnsupportedType:
+		return "unsupported type"
+	default:
+		return "internal"
+	}
+}
+
+// FieldError tells which field of the input breaks which rule, params are limits of the rule.
+type FieldError struct {
+	Field   string                 `json:"field" example:"password"`
+	Rule    string                 `json:"rule" example:"length"`
+	Message string                 `json:"message" example:"password length must be between 8 to 32 characters long"`
+	Params  map[string]interface{} `json:"params,omitempty"`
+}
+
+// Error has the message which is safe to show to the client and the cause which is only logged.
+type Error struct {
+	Kind       Kind
+	Msg        string
+	Err        error
+	RetryAfter time.Duration
+	Fields     []FieldError
+}
+
+// New error without cause.
+func New(kind Kind, msg string) *Error {
+	return &Error{Kind: kind, Msg: msg}
+}
+
+// Wrap keeps err as the cause.
+func Wrap(kind Kind, msg string, err error) *Error {
+	return &Error{Kind: kind, Msg: msg, Err: err}
+}
+
+// Invalid validation error with the list of broken fields, the message joins them.
+func Invalid(fields ...FieldError) *Error {
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, field.Field+": "+field.Message)
+	}
+
+	return &Error{Kind: Validation, Msg: strings.Join(messages, "; "), Fields: fields}
+}
+
+// Error message with the cause, for logs.
+func (o *Error) Error() string {
+	switch {
+	case o.Err == nil:
+		return o.Msg
+	case o.Msg == "":
+		return o.Err.Error()
+	default:
+		return o.Msg + ": " + o.Err.Error()
+	}
+}
+
+func (o *Error) Unwrap() error {
+	return o.Err
+}
+
+// KindOf return kind of the outer typed error, other errors are internal.
+func KindOf(err error) Kind {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Kind
+	}
+
+	return Internal
+}
+
+// Message return the message for the client, details of internal errors are hidden.
+func Message(err error) string {
+	var appErr *Error
+	if !errors.As(err, &appErr) || appErr.Kind == Internal {
+		return ""
+	}
+
+	return appErr.Msg
+}
+
+// RetryAfter return how long the client must wait, zero if it need not.
+func RetryAfter(err error) time.Duration {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.RetryAfter
+	}
+
+	return 0
+}
+
+// Fields return broken fields of the validation error.
+func Fields(err error) []FieldError {
+	var appErr *Error
+	if errors.As(err, &appErr) && appErr.Kind == Validation {
+		return appErr.Fields
+	}
+
+	return nil
+}
@@ -3,11 +3,17 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/Dsmit05/metida/internal/consts"
+	"github.com/Dsmit05/metida/internal/cryptography"
+	"github.com/Dsmit05/metida/internal/lockout"
 	"github.com/Dsmit05/metida/internal/logger"
+	"github.com/Dsmit05/metida/internal/ratelimit"
+	"github.com/Dsmit05/metida/internal/storage"
 	"gopkg.in/yaml.v3"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -23,6 +29,16 @@ type Database struct {
 	Table    string `yaml:"table"`
 	User     string `yaml:"user"`
 	Password string `yaml:"password"`
+	Pool     Pool   `yaml:"pool"`
+}
+
+// Pool - contains parameters of the database connection pool, time values in second.
+type Pool struct {
+	MaxConns          int32 `yaml:"maxConns"`
+	MinConns          int32 `yaml:"minConns"`
+	MaxConnLifetime   int   `yaml:"maxConnLifetime"`
+	MaxConnIdleTime   int   `yaml:"maxConnIdleTime"`
+	HealthCheckPeriod int   `yaml:"healthCheckPeriod"`
 }
 
 // ApiServer - contains parameter for rest connection.
@@ -31,6 +47,8 @@ type ApiServer struct {
 	Port         int    `yaml:"port"`
 	ReadTimeout  int    `yaml:"readTimeout"`
 	WriteTimeout int    `yaml:"writeTimeout"`
+	// TrustedProxies addresses or CIDRs of proxies, whose X-Forwarded-For header is used as ip of the client
+	TrustedProxies []string `yaml:"trustedProxies"`
 }
 
 // DebagServer - contains parameter for debag server.
@@ -46,9 +64,94 @@ type CORS struct {
 	AllowedOrigins []string `yaml:"allowedOrigins"`
 }
 
-// Cryptography - contains secret for jwt token.
+// Cryptography - contains secret for jwt token and keys for asymmetric signing.
 type Cryptography struct {
-	Secret string `yaml:"secret"`
+	Secret    string   `yaml:"secret"`
+	ActiveKey string   `yaml:"activeKey"` // kid of the key signing new tokens
+	Keys      []JWTKey `yaml:"keys"`      // if empty, tokens are signed with HS256 and the secret
+	Issuer    string   `yaml:"issuer"`
+	Audience  string   `yaml:"audience"`
+	Leeway    int      `yaml:"leeway"` // allowed clock skew in second
+}
+
+// JWTKey - contains paths to PEM files of the signing key.
+type JWTKey struct {
+	ID         string `yaml:"kid"`
+	PrivateKey string `yaml:"privateKey"`
+	PublicKey  string `yaml:"publicKey"` // for retired keys, which only verify tokens
+}
+
+// Verification - contains parameters of email confirmation.
+type Verification struct {
+	Required bool   `yaml:"required"` // sign-in is denied until the email is confirmed
+	TokenTTL int    `yaml:"tokenTTL"`
+	URL      string `yaml:"url"` // link from the letter, token is added as query param
+}
+
+// PasswordReset - contains parameters of password recovery.
+type PasswordReset struct {
+	TokenTTL int    `yaml:"tokenTTL"`
+	URL      string `yaml:"url"` // page of the client app, token is added as query param
+}
+
+// Lockout - contains parameters of brute-force protection on sign-in, time in second.
+type Lockout struct {
+	Store              string `yaml:"store"` // memory or postgres
+	MaxAccountFailures int    `yaml:"maxAccountFailures"`
+	MaxIPFailures      int    `yaml:"maxIPFailures"`
+	BaseLockout        int    `yaml:"baseLockout"`
+	MaxLockout         int    `yaml:"maxLockout"`
+	Window             int    `yaml:"window"`
+}
+
+// RateLimit - contains rate limits of route groups.
+type RateLimit struct {
+	Store  string                   `yaml:"store"` // memory
+	Groups map[string]RateLimitRule `yaml:"groups"`
+}
+
+// RateLimitRule - contains token bucket parameters, period in second.
+type RateLimitRule struct {
+	Requests int    `yaml:"requests"`
+	Period   int    `yaml:"period"`
+	Burst    int    `yaml:"burst"`
+	Key      string `yaml:"key"` // ip, email or ip+email
+}
+
+// Mail - contains parameters for sending letters.
+type Mail struct {
+	Sender   string `yaml:"sender"` // log or file
+	From     string `yaml:"from"`
+	FilePath string `yaml:"filePath"`
+}
+
+// Storage - contains parameters of the storage of attached files.
+type Storage struct {
+	Type      string    `yaml:"type"` // local or s3
+	LocalPath string    `yaml:"localPath"`
+	S3        StorageS3 `yaml:"s3"`
+}
+
+// StorageS3 - contains parameters of the S3 compatible storage.
+type StorageS3 struct {
+	Endpoint  string `yaml:"endpoint"`
+	Region    string `yaml:"region"`
+	Bucket    string `yaml:"bucket"`
+	AccessKey string `yaml:"accessKey"`
+	SecretKey string `yaml:"secretKey"`
+	PathStyle bool   `yaml:"pathStyle"` // bucket in the path, for MinIO
+}
+
+// Attachments - contains limits of attached files, size in bytes.
+type Attachments struct {
+	MaxSize      int64    `yaml:"maxSize"`
+	AllowedTypes []string `yaml:"allowedTypes"`
+}
+
+// Password - contains length limits of user passwords.
+type Password struct {
+	MinLength int `yaml:"minLength"`
+	MaxLength int `yaml:"maxLength"`
 }
 
 // Project - contains all parameters project information.
@@ -58,11 +161,19 @@ type Project struct {
 
 // Config contains all necessary params.
 type Config struct {
-	Database     Database     `yaml:"database"`
-	ApiServer    ApiServer    `yaml:"apiServer"`
-	DebagServer  DebagServer  `yaml:"debagServer"`
-	CORS         CORS         `yaml:"cors"`
-	Cryptography Cryptography `yaml:"cryptography"`
+	Database      Database      `yaml:"database"`
+	ApiServer     ApiServer     `yaml:"apiServer"`
+	DebagServer   DebagServer   `yaml:"debagServer"`
+	CORS          CORS          `yaml:"cors"`
+	Cryptography  Cryptography  `yaml:"cryptography"`
+	Verification  Verification  `yaml:"verification"`
+	PasswordReset PasswordReset `yaml:"passwordReset"`
+	Lockout       Lockout       `yaml:"lockout"`
+	RateLimit     RateLimit     `yaml:"rateLimit"`
+	Mail          Mail          `yaml:"mail"`
+	Storage       Storage       `yaml:"storage"`
+	Attachments   Attachments   `yaml:"attachments"`
+	Password      Password      `yaml:"password"`
 	Project
 	CommandLineI
 }
@@ -87,6 +198,10 @@ func NewConfig(flagCmd CommandLineI) (*Config, error) {
 		}
 	}
 
+	if err := cfg.checkPassword(); err != nil {
+		return nil, err
+	}
+
 	cfg.CommandLineI = flagCmd
 	cfg.Project.BuildVersion = buildVersion
 
@@ -129,6 +244,31 @@ func (o *Config) GetConnectDB() string {
 	)
 }
 
+// GetDbMaxConns return maximum size of the pool.
+func (o *Config) GetDbMaxConns() int32 {
+	return o.Database.Pool.MaxConns
+}
+
+// GetDbMinConns return minimum size of the pool.
+func (o *Config) GetDbMinConns() int32 {
+	return o.Database.Pool.MinConns
+}
+
+// GetDbMaxConnLifetime in second.
+func (o *Config) GetDbMaxConnLifetime() time.Duration {
+	return time.Duration(o.Database.Pool.MaxConnLifetime) * time.Second
+}
+
+// GetDbMaxConnIdleTime in second.
+func (o *Config) GetDbMaxConnIdleTime() time.Duration {
+	return time.Duration(o.Database.Pool.MaxConnIdleTime) * time.Second
+}
+
+// GetDbHealthCheckPeriod in second.
+func (o *Config) GetDbHealthCheckPeriod() time.Duration {
+	return time.Duration(o.Database.Pool.HealthCheckPeriod) * time.Second
+}
+
 // GetApiAddr return addr in format localhost:8080
 func (o *Config) GetApiAddr() string {
 	return fmt.Sprintf("%v:%v", o.ApiServer.Host, o.ApiServer.Port)
@@ -146,6 +286,11 @@ func (o *Config) GetApiWriteTimeout() time.Duration {
 	return timeout
 }
 
+// GetTrustedProxies return proxies trusted to pass ip of the client, by default no one is trusted.
+func (o *Config) GetTrustedProxies() []string {
+	return o.ApiServer.TrustedProxies
+}
+
 // GetDebagAddr return addr debag server.
 func (o *Config) GetDebagAddr() string {
 	return fmt.Sprintf("%v:%v", o.DebagServer.Host, o.DebagServer.Port)
@@ -169,6 +314,222 @@ func (o *Config) GetCorsAllowedOrigins() []string {
 	return o.CORS.AllowedOrigins
 }
 
+// GetJWTActiveKeyID return kid of the key signing new tokens.
+func (o *Config) GetJWTActiveKeyID() string {
+	return o.Cryptography.ActiveKey
+}
+
+// GetJWTClaimsOptions return issuer, audience and leeway for checking access tokens.
+func (o *Config) GetJWTClaimsOptions() cryptography.ClaimsOptions {
+	return cryptography.ClaimsOptions{
+		Issuer:   o.Cryptography.Issuer,
+		Audience: o.Cryptography.Audience,
+		Leeway:   time.Duration(o.Cryptography.Leeway) * time.Second,
+	}
+}
+
+// GetJWTKeyFiles return PEM files of keys for asymmetric signing.
+func (o *Config) GetJWTKeyFiles() []cryptography.KeyFile {
+	files := make([]cryptography.KeyFile, 0, len(o.Cryptography.Keys))
+	for _, key := range o.Cryptography.Keys {
+		files = append(files, cryptography.KeyFile{
+			ID:             key.ID,
+			PrivateKeyPath: key.PrivateKey,
+			PublicKeyPath:  key.PublicKey,
+		})
+	}
+
+	return files
+}
+
+// IsEmailVerificationRequired return true if users must confirm email before sign-in.
+func (o *Config) IsEmailVerificationRequired() bool {
+	return o.Verification.Required
+}
+
+// GetVerificationTokenTTL in second, if not set return consts.VerificationTokenTTL.
+func (o *Config) GetVerificationTokenTTL() time.Duration {
+	if o.Verification.TokenTTL <= 0 {
+		return consts.VerificationTokenTTL
+	}
+
+	return time.Duration(o.Verification.TokenTTL) * time.Second
+}
+
+// GetVerificationURL return link for email confirmation.
+func (o *Config) GetVerificationURL() string {
+	return o.Verification.URL
+}
+
+// GetPasswordResetTokenTTL in second, if not set return consts.PasswordResetTokenTTL.
+func (o *Config) GetPasswordResetTokenTTL() time.Duration {
+	if o.PasswordReset.TokenTTL <= 0 {
+		return consts.PasswordResetTokenTTL
+	}
+
+	return time.Duration(o.PasswordReset.TokenTTL) * time.Second
+}
+
+// GetPasswordResetURL return link for password recovery.
+func (o *Config) GetPasswordResetURL() string {
+	return o.PasswordReset.URL
+}
+
+// GetLockoutStore return name of the store of failed sign-in attempts.
+func (o *Config) GetLockoutStore() string {
+	return o.Lockout.Store
+}
+
+// GetLockoutOptions return limits of failed sign-in attempts, zero values are replaced by defaults.
+func (o *Config) GetLockoutOptions() lockout.Options {
+	opts := lockout.Options{
+		MaxAccountFailures: o.Lockout.MaxAccountFailures,
+		MaxIPFailures:      o.Lockout.MaxIPFailures,
+		BaseLockout:        time.Duration(o.Lockout.BaseLockout) * time.Second,
+		MaxLockout:         time.Duration(o.Lockout.MaxLockout) * time.Second,
+		Window:             time.Duration(o.Lockout.Window) * time.Second,
+	}
+
+	if opts.MaxAccountFailures <= 0 {
+		opts.MaxAccountFailures = consts.LockoutMaxAccountFailures
+	}
+
+	if opts.MaxIPFailures <= 0 {
+		opts.MaxIPFailures = consts.LockoutMaxIPFailures
+	}
+
+	if opts.BaseLockout <= 0 {
+		opts.BaseLockout = consts.LockoutBaseDuration
+	}
+
+	if opts.MaxLockout <= 0 {
+		opts.MaxLockout = consts.LockoutMaxLockout
+	}
+
+	if opts.Window <= 0 {
+		opts.Window = consts.LockoutWindow
+	}
+
+	return opts
+}
+
+// GetRateLimitStore return name of the store of token buckets.
+func (o *Config) GetRateLimitStore() string {
+	return o.RateLimit.Store
+}
+
+// GetRateLimitRules return rate limits of route groups, groups without requests or period are skipped.
+func (o *Config) GetRateLimitRules() map[string]ratelimit.Rule {
+	rules := make(map[string]ratelimit.Rule, len(o.RateLimit.Groups))
+	for group, rule := range o.RateLimit.Groups {
+		if rule.Requests <= 0 || rule.Period <= 0 {
+			continue
+		}
+
+		rules[group] = ratelimit.Rule{
+			Rate: ratelimit.Rate{
+				Requests: rule.Requests,
+				Period:   time.Duration(rule.Period) * time.Second,
+				Burst:    rule.Burst,
+			},
+			Key: rule.Key,
+		}
+	}
+
+	return rules
+}
+
+// GetMailSender return name of the mail sender.
+func (o *Config) GetMailSender() string {
+	return o.Mail.Sender
+}
+
+// GetMailFrom return sender address.
+func (o *Config) GetMailFrom() string {
+	return o.Mail.From
+}
+
+// GetMailFilePath return file name for the file mail sender.
+func (o *Config) GetMailFilePath() string {
+	return o.Mail.FilePath
+}
+
+// GetStorageType return name of the storage of attached files.
+func (o *Config) GetStorageType() string {
+	return o.Storage.Type
+}
+
+// GetStorageLocalPath return directory of the local storage, if not set return consts.StorageLocalPath.
+func (o *Config) GetStorageLocalPath() string {
+	if o.Storage.LocalPath == "" {
+		return consts.StorageLocalPath
+	}
+
+	return o.Storage.LocalPath
+}
+
+// GetStorageS3Options return parameters of the S3 compatible storage.
+func (o *Config) GetStorageS3Options() storage.S3Options {
+	return storage.S3Options{
+		Endpoint:  o.Storage.S3.Endpoint,
+		Region:    o.Storage.S3.Region,
+		Bucket:    o.Storage.S3.Bucket,
+		AccessKey: o.Storage.S3.AccessKey,
+		SecretKey: o.Storage.S3.SecretKey,
+		PathStyle: o.Storage.S3.PathStyle,
+	}
+}
+
+// GetAttachmentMaxSize in bytes, if not set return consts.AttachmentMaxSize.
+func (o *Config) GetAttachmentMaxSize() int64 {
+	if o.Attachments.MaxSize <= 0 {
+		return consts.AttachmentMaxSize
+	}
+
+	return o.Attachments.MaxSize
+}
+
+// GetAttachmentAllowedTypes return MIME types of files allowed to upload, if not set return consts.AttachmentAllowedTypes.
+func (o *Config) GetAttachmentAllowedTypes() []string {
+	if len(o.Attachments.AllowedTypes) == 0 {
+		return strings.Split(consts.AttachmentAllowedTypes, ",")
+	}
+
+	return o.Attachments.AllowedTypes
+}
+
+// GetPasswordMinLength return minimum length of passwords in symbols, zero is replaced by default.
+func (o *Config) GetPasswordMinLength() int {
+	if o.Password.MinLength <= 0 {
+		return consts.PasswordMinLength
+	}
+
+	return o.Password.MinLength
+}
+
+// GetPasswordMaxLength return maximum length of passwords in symbols, zero is replaced by default.
+func (o *Config) GetPasswordMaxLength() int {
+	if o.Password.MaxLength <= 0 {
+		return consts.PasswordMaxLength
+	}
+
+	return o.Password.MaxLength
+}
+
+// checkPassword rejects length limits which no password satisfies or bcrypt can't hash.
+func (o *Config) checkPassword() error {
+	min, max := o.GetPasswordMinLength(), o.GetPasswordMaxLength()
+	if min > max {
+		return fmt.Errorf("password: minLength %v is greater than maxLength %v", min, max)
+	}
+
+	if max > consts.PasswordMaxBytes {
+		return fmt.Errorf("password: maxLength %v is above the bcrypt limit of %v bytes", max, consts.PasswordMaxBytes)
+	}
+
+	return nil
+}
+
 // GetConfigInfo handler info build.
 func (o *Config) GetConfigInfo(w http.ResponseWriter, r *http.Request) {
 	data := map[string]interface{}{
@@ -10,4 +10,80 @@ const (
 const (
 	AccessTokenTTL  = time.Second * 60 * 30
 	RefreshTokenTTL = time.Hour * 48
+
+	VerificationTokenTTL  = time.Hour * 24
+	PasswordResetTokenTTL = time.Hour
+)
+
+// Permissions of roles, the mapping is stored in role_permissions table.
+const (
+	PermissionContentRead  = "content:read"
+	PermissionContentWrite = "content:write"
+	PermissionBlogCreate   = "blog:create"
+	PermissionBlogUpdate   = "blog:update"
+	PermissionBlogDelete   = "blog:delete"
+	PermissionBlogPublish  = "blog:publish"
+	PermissionUsersManage  = "users:manage"
+
+	PermissionCommentsWrite    = "comments:write"
+	PermissionCommentsModerate = "comments:moderate"
+)
+
+// Statuses of blog posts, only published posts are shown to everyone.
+const (
+	BlogStatusDraft     = "draft"
+	BlogStatusPublished = "published"
+	BlogStatusArchived  = "archived"
+)
+
+// Statuses of comments, new and edited comments wait for moderation.
+const (
+	CommentStatusPending  = "pending"
+	CommentStatusApproved = "approved"
+	CommentStatusRejected = "rejected"
+	CommentStatusHidden   = "hidden"
+)
+
+// Access of the user to content, owners share content with read or edit permission.
+const (
+	ContentAccessOwner = "owner"
+	ContentAccessEdit  = "edit"
+	ContentAccessRead  = "read"
+)
+
+// Default lifetime of public links to content.
+const (
+	ContentLinkTTL    = time.Hour * 24 * 7
+	ContentLinkMaxTTL = time.Hour * 24 * 30
+)
+
+// Default length limits of user passwords in symbols, bcrypt hashes only the first 72 bytes.
+const (
+	PasswordMinLength = 8
+	PasswordMaxLength = 32
+	PasswordMaxBytes  = 72
+)
+
+// Default limits of failed sign-in attempts.
+const (
+	LockoutMaxAccountFailures = 5
+	LockoutMaxIPFailures      = 20
+	LockoutBaseDuration       = time.Second * 30
+	LockoutMaxLockout         = time.Minute * 15
+	LockoutWindow             = time.Minute * 15
+)
+
+// Default storage and limits of files attached to content.
+const (
+	StorageLocalPath       = "files"
+	AttachmentMaxSize      = 10 << 20
+	AttachmentAllowedTypes = "image/png,image/jpeg,image/gif,image/webp,application/pdf,text/plain"
+)
+
+// Route groups with own rate limits.
+const (
+	RateLimitGlobal = "global"
+	RateLimitAuth   = "auth"
+	RateLimitLk     = "lk"
+	RateLimitAdmin  = "admin"
 )
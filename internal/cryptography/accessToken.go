@@ -2,62 +2,196 @@ package cryptography
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 )
 
 type AccessToken interface {
-	CreateToken(email string, role string, ttl time.Duration) (string, error)
-	ParseToken(inputToken string) (email string, role string, err error)
+	CreateToken(subject TokenSubject, ttl time.Duration) (token string, tokenID string, err error)
+	ParseToken(inputToken string) (*UserClaims, error)
+	JWKS() JSONWebKeySet
 }
 
+// ClaimsOptions settings of registered claims, empty issuer or audience are not checked.
+type ClaimsOptions struct {
+	Issuer   string
+	Audience string
+	Leeway   time.Duration // allowed clock skew between services
+}
+
+// TokenJWT signs access tokens with HS256 and the secret,
+// or with asymmetric keys if the key set is given.
 type TokenJWT struct {
 	secretKey []byte
+	keys      *KeySet
+	opts      ClaimsOptions
+}
+
+func NewTokenJWT(secret string, opts ClaimsOptions) *TokenJWT {
+	return &TokenJWT{secretKey: []byte(secret), opts: opts}
 }
 
-func NewTokenJWT(secret string) *TokenJWT {
-	return &TokenJWT{secretKey: []byte(secret)}
+// NewTokenJWTWithKeys return TokenJWT signing with RS256, ES256 or EdDSA, the kid header selects the key.
+func NewTokenJWTWithKeys(keys *KeySet, opts ClaimsOptions) *TokenJWT {
+	return &TokenJWT{keys: keys, opts: opts}
+}
+
+// TokenSubject the user for whom the token is issued.
+type TokenSubject struct {
+	UserID    int32
+	Email     string
+	Role      string
+	SessionID int32
 }
 
 // UserClaims include custom claims on jwt.
 type UserClaims struct {
-	Email string `json:"email"`
-	Role  string `json:"role"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	SessionID int32  `json:"sid"` // session which the token was issued for
 	jwt.StandardClaims
 }
 
-// CreateToken create new token with parameters.
-func (o *TokenJWT) CreateToken(email, role string, ttl time.Duration) (string, error) {
-	claims := UserClaims{email, role,
-		jwt.StandardClaims{ExpiresAt: time.Now().Add(ttl).Unix()},
+// UserID return id of the user from sub claim.
+func (o *UserClaims) UserID() int32 {
+	id, err := strconv.ParseInt(o.Subject, 10, 32)
+	if err != nil {
+		return 0
+	}
+
+	return int32(id)
+}
+
+// CreateToken create new token with parameters, return token and its unique id (jti).
+func (o *TokenJWT) CreateToken(subject TokenSubject, ttl time.Duration) (string, string, error) {
+	tokenID := uuid.New().String()
+	now := time.Now()
+
+	claims := UserClaims{subject.Email, subject.Role, subject.SessionID,
+		jwt.StandardClaims{
+			Id:        tokenID,
+			Issuer:    o.opts.Issuer,
+			Subject:   strconv.Itoa(int(subject.UserID)),
+			Audience:  o.opts.Audience,
+			IssuedAt:  now.Unix(),
+			NotBefore: now.Unix(),
+			ExpiresAt: now.Add(ttl).Unix(),
+		},
+	}
+
+	signedToken, err := o.sign(claims)
+	if err != nil {
+		return "", "", err
+	}
+
+	return signedToken, tokenID, nil
+}
+
+// sign token with the active key, or with the secret if keys are not set.
+func (o *TokenJWT) sign(claims jwt.Claims) (string, error) {
+	if o.keys == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(o.secretKey)
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	key := o.keys.Active()
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
 
-	return token.SignedString(o.secretKey)
+	return token.SignedString(key.Private)
 }
 
-// ParseToken parsing input token, and return email and role from token.
-func (o *TokenJWT) ParseToken(inputToken string) (email, role string, err error) {
-	token, err := jwt.Parse(inputToken, func(token *jwt.Token) (interface{}, error) {
+// verificationKey return key for checking the token signature,
+// with asymmetric keys HMAC tokens are rejected to prevent algorithm confusion.
+func (o *TokenJWT) verificationKey(token *jwt.Token) (interface{}, error) {
+	if o.keys == nil {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return o.secretKey, nil
-	})
+	}
+
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := o.keys.Get(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown key id: %v", token.Header["kid"])
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.Public, nil
+}
+
+// JWKS return public keys for verification of tokens by other services.
+func (o *TokenJWT) JWKS() JSONWebKeySet {
+	if o.keys == nil {
+		return JSONWebKeySet{Keys: []JSONWebKey{}}
+	}
+
+	return o.keys.JWKS()
+}
+
+// ParseToken parsing input token, and return user claims from token.
+func (o *TokenJWT) ParseToken(inputToken string) (*UserClaims, error) {
+	claims := &UserClaims{}
+
+	// time claims are checked by validateClaims with leeway
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+
+	token, err := parser.ParseWithClaims(inputToken, claims, o.verificationKey)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
 	if !token.Valid {
-		return "", "", fmt.Errorf("not valid token")
+		return nil, fmt.Errorf("not valid token")
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return "", "", fmt.Errorf("error get user claims from token")
+	if err = o.validateClaims(claims, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if claims.Email == "" || claims.Role == "" || claims.Id == "" || claims.Subject == "" {
+		return nil, fmt.Errorf("error get user claims from token")
+	}
+
+	return claims, nil
+}
+
+// validateClaims checks registered claims: exp, nbf, iat with leeway, iss and aud.
+func (o *TokenJWT) validateClaims(claims *UserClaims, now time.Time) error {
+	leeway := int64(o.opts.Leeway / time.Second)
+	unixNow := now.Unix()
+
+	if claims.ExpiresAt == 0 {
+		return fmt.Errorf("token has no expiration")
+	}
+
+	if unixNow-leeway >= claims.ExpiresAt {
+		return fmt.Errorf("token is expired")
+	}
+
+	if claims.NotBefore != 0 && unixNow+leeway < claims.NotBefore {
+		return fmt.Errorf("token is not valid yet")
+	}
+
+	if claims.IssuedAt != 0 && unixNow+leeway < claims.IssuedAt {
+		return fmt.Errorf("token used before issued")
+	}
+
+	if o.opts.Issuer != "" && claims.Issuer != o.opts.Issuer {
+		return fmt.Errorf("unexpected token issuer: %v", claims.Issuer)
+	}
+
+	if o.opts.Audience != "" && claims.Audience != o.opts.Audience {
+		return fmt.Errorf("unexpected token audience: %v", claims.Audience)
 	}
 
-	return claims["email"].(string), claims["role"].(string), nil
+	return nil
 }
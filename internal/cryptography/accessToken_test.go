@@ -3,18 +3,22 @@ package cryptography
 import (
 	"testing"
 	"time"
+
+	"github.com/golang-jwt/jwt"
 )
 
+var testSubject = TokenSubject{UserID: 42, Email: "test@email.com", Role: "user", SessionID: 1}
+
 func TestNewTokenJWT(t *testing.T) {
 	type args struct {
-		email string
-		role  string
-		ttl   time.Duration
+		subject TokenSubject
+		ttl     time.Duration
 	}
 
 	var tests = []struct {
 		name      string
 		secretKey string
+		opts      ClaimsOptions
 		args      args
 		wantEmail string
 		wantRole  string
@@ -23,34 +27,129 @@ func TestNewTokenJWT(t *testing.T) {
 		{name: "Case-1: check token",
 			secretKey: "hello",
 			args: args{
-				email: "test@email.com",
-				role:  "user",
-				ttl:   time.Minute * 15,
+				subject: TokenSubject{UserID: 3, Email: "test@email.com", Role: "user", SessionID: 7},
+				ttl:     time.Minute * 15,
 			},
 			wantEmail: "test@email.com",
 			wantRole:  "user",
 			wantErr:   false,
 		},
+		{name: "Case-2: check token with issuer and audience",
+			secretKey: "hello",
+			opts:      ClaimsOptions{Issuer: "metida", Audience: "metida-api", Leeway: time.Second * 5},
+			args: args{
+				subject: TokenSubject{UserID: 5, Email: "admin@email.com", Role: "admin", SessionID: 2},
+				ttl:     time.Minute,
+			},
+			wantEmail: "admin@email.com",
+			wantRole:  "admin",
+			wantErr:   false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			o := &TokenJWT{
-				secretKey: []byte(tt.secretKey),
-			}
-			got, err := o.CreateToken(tt.args.email, tt.args.role, tt.args.ttl)
+			o := NewTokenJWT(tt.secretKey, tt.opts)
+			got, tokenID, err := o.CreateToken(tt.args.subject, tt.args.ttl)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CreateToken error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
-			email, role, err := o.ParseToken(got)
+			claims, err := o.ParseToken(got)
 			if err != nil {
 				t.Errorf("got = %v, ParseToken error = %v ", tt.args, err)
+				return
 			}
 
-			if email != tt.wantEmail || role != tt.wantRole {
+			if claims.Email != tt.wantEmail || claims.Role != tt.wantRole || claims.SessionID != tt.args.subject.SessionID ||
+				claims.Id != tokenID || claims.UserID() != tt.args.subject.UserID {
 				t.Errorf("got = %v, want email: %v, want role: %v", tt.args, tt.wantEmail, tt.wantRole)
 			}
+
+			if claims.Issuer != tt.opts.Issuer || claims.Audience != tt.opts.Audience ||
+				claims.IssuedAt == 0 || claims.NotBefore == 0 {
+				t.Errorf("registered claims = %+v, want options %+v", claims.StandardClaims, tt.opts)
+			}
+		})
+	}
+}
+
+func TestTokenJWT_ParseTokenAudience(t *testing.T) {
+	issuer := NewTokenJWT("hello", ClaimsOptions{Issuer: "metida", Audience: "metida-api"})
+
+	token, _, err := issuer.CreateToken(testSubject, time.Minute)
+	if err != nil {
+		t.Fatalf("CreateToken error = %v", err)
+	}
+
+	var tests = []struct {
+		name    string
+		opts    ClaimsOptions
+		wantErr bool
+	}{
+		{name: "Case-1: same audience", opts: ClaimsOptions{Issuer: "metida", Audience: "metida-api"}, wantErr: false},
+		{name: "Case-2: another audience", opts: ClaimsOptions{Issuer: "metida", Audience: "billing"}, wantErr: true},
+		{name: "Case-3: another issuer", opts: ClaimsOptions{Issuer: "other", Audience: "metida-api"}, wantErr: true},
+		{name: "Case-4: checks are not configured", opts: ClaimsOptions{}, wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTokenJWT("hello", tt.opts).ParseToken(token)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseToken error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTokenJWT_ParseTokenLeeway(t *testing.T) {
+	now := time.Now()
+
+	var tests = []struct {
+		name    string
+		claims  jwt.StandardClaims
+		leeway  time.Duration
+		wantErr bool
+	}{
+		{name: "Case-1: expired token",
+			claims:  jwt.StandardClaims{ExpiresAt: now.Add(-time.Second * 10).Unix()},
+			wantErr: true,
+		},
+		{name: "Case-2: expired token within leeway",
+			claims:  jwt.StandardClaims{ExpiresAt: now.Add(-time.Second * 10).Unix()},
+			leeway:  time.Minute,
+			wantErr: false,
+		},
+		{name: "Case-3: token from the future",
+			claims:  jwt.StandardClaims{ExpiresAt: now.Add(time.Hour).Unix(), NotBefore: now.Add(time.Second * 30).Unix()},
+			wantErr: true,
+		},
+		{name: "Case-4: token from the future within leeway",
+			claims: jwt.StandardClaims{ExpiresAt: now.Add(time.Hour).Unix(), NotBefore: now.Add(time.Second * 30).Unix(),
+				IssuedAt: now.Add(time.Second * 30).Unix()},
+			leeway:  time.Minute,
+			wantErr: false,
+		},
+		{name: "Case-5: token without expiration",
+			claims:  jwt.StandardClaims{},
+			leeway:  time.Minute,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewTokenJWT("hello", ClaimsOptions{Leeway: tt.leeway})
+
+			tt.claims.Id = "id"
+			tt.claims.Subject = "1"
+			token, err := o.sign(UserClaims{Email: "test@email.com", Role: "user", StandardClaims: tt.claims})
+			if err != nil {
+				t.Fatalf("sign error = %v", err)
+			}
+
+			if _, err = o.ParseToken(token); (err != nil) != tt.wantErr {
+				t.Errorf("ParseToken error = %v, wantErr %v", err, tt.wantErr)
+			}
 		})
 	}
 }
@@ -0,0 +1,62 @@
+package cryptography
+
+import (
+	"time"
+
+	"github.com/Dsmit05/metida/pkg/cache/lru"
+)
+
+// denylistCleanupInterval how often expired token IDs are removed.
+const denylistCleanupInterval = time.Minute
+
+type TokenRevoker interface {
+	RevokeToken(tokenID string, expiresAt int64)
+	IsTokenRevoked(tokenID string) bool
+}
+
+// tokenIDKey is key of the denylist cache.
+type tokenIDKey string
+
+func (o tokenIDKey) Equally(k lru.KeyI) bool {
+	return o == k
+}
+
+// TokenDenylist keeps IDs (jti) of revoked access tokens until the tokens expire.
+type TokenDenylist struct {
+	cache *lru.Cache // jti -> unix time when the token expires
+	now   func() time.Time
+}
+
+// NewTokenDenylist create denylist without capacity limit,
+// eviction would make a revoked token valid again, the size is limited by token lifetime.
+func NewTokenDenylist() *TokenDenylist {
+	return newTokenDenylist(lru.NewLruCache(0, 0, denylistCleanupInterval), time.Now)
+}
+
+func newTokenDenylist(cache *lru.Cache, now func() time.Time) *TokenDenylist {
+	return &TokenDenylist{cache: cache, now: now}
+}
+
+// RevokeToken add token ID to the denylist for the remaining token lifetime,
+// expiresAt is unix time when the token expires.
+func (o *TokenDenylist) RevokeToken(tokenID string, expiresAt int64) {
+	if tokenID == "" {
+		return
+	}
+
+	remaining := time.Unix(expiresAt, 0).Sub(o.now())
+	if remaining <= 0 {
+		return
+	}
+
+	// ErrKeyAlreadyExist means the token is already revoked
+	_ = o.cache.Add(tokenIDKey(tokenID), expiresAt, remaining)
+}
+
+// IsTokenRevoked return true if token ID is in the denylist,
+// the cache keeps expired IDs until cleanup, so the expiration is checked too.
+func (o *TokenDenylist) IsTokenRevoked(tokenID string) bool {
+	expiresAt, ok := o.cache.Peek(tokenIDKey(tokenID))
+
+	return ok && expiresAt.(int64) > o.now().Unix()
+}
@@ -0,0 +1,65 @@
+package cryptography
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dsmit05/metida/pkg/cache/lru"
+)
+
+func TestTokenDenylist(t *testing.T) {
+	var tests = []struct {
+		name      string
+		tokenID   string
+		expiresAt int64
+		want      bool
+	}{
+		{name: "Case-1: alive token is revoked",
+			tokenID:   "a8b1e4c2",
+			expiresAt: time.Now().Add(time.Minute).Unix(),
+			want:      true,
+		},
+		{name: "Case-2: expired token is not stored",
+			tokenID:   "f0c3d9a7",
+			expiresAt: time.Now().Add(-time.Minute).Unix(),
+			want:      false,
+		},
+		{name: "Case-3: empty id is ignored",
+			tokenID:   "",
+			expiresAt: time.Now().Add(time.Minute).Unix(),
+			want:      false,
+		},
+	}
+
+	o := NewTokenDenylist()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o.RevokeToken(tt.tokenID, tt.expiresAt)
+
+			if got := o.IsTokenRevoked(tt.tokenID); got != tt.want {
+				t.Errorf("IsTokenRevoked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if o.IsTokenRevoked("not-revoked") {
+		t.Errorf("IsTokenRevoked() = true for unknown token")
+	}
+}
+
+func TestTokenDenylistExpiration(t *testing.T) {
+	now := time.Unix(1000, 0)
+	o := newTokenDenylist(lru.NewLruCache(0, 0, 0), func() time.Time { return now })
+
+	o.RevokeToken("short", now.Add(time.Minute).Unix())
+	o.RevokeToken("long", now.Add(time.Hour).Unix())
+
+	now = now.Add(2 * time.Minute)
+	if o.IsTokenRevoked("short") {
+		t.Errorf("IsTokenRevoked() = true for expired token")
+	}
+
+	if !o.IsTokenRevoked("long") {
+		t.Errorf("IsTokenRevoked() = false for alive token")
+	}
+}
@@ -0,0 +1,182 @@
+package cryptography
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// KeyFile paths to PEM files of the signing key, a key without private part is used only for verification.
+type KeyFile struct {
+	ID             string
+	PrivateKeyPath string
+	PublicKeyPath  string
+}
+
+// SigningKey asymmetric key for signing and verification of access tokens.
+type SigningKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.PrivateKey
+	Public  crypto.PublicKey
+}
+
+// KeySet contains all keys accepted for verification and the active key that signs new tokens,
+// several keys allow to rotate them without logging out users.
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// LoadKeySet read keys from PEM files, return nil if no keys are set.
+func LoadKeySet(activeID string, files ...KeyFile) (*KeySet, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	keySet := &KeySet{keys: make(map[string]*SigningKey, len(files))}
+
+	for _, file := range files {
+		if file.ID == "" {
+			return nil, fmt.Errorf("key id (kid) is not set")
+		}
+
+		if _, ok := keySet.keys[file.ID]; ok {
+			return nil, fmt.Errorf("key %v: duplicate kid", file.ID)
+		}
+
+		key, err := loadSigningKey(file)
+		if err != nil {
+			return nil, fmt.Errorf("key %v: %w", file.ID, err)
+		}
+
+		keySet.keys[file.ID] = key
+	}
+
+	active, ok := keySet.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("active key %v is not found", activeID)
+	}
+
+	if active.Private == nil {
+		return nil, fmt.Errorf("active key %v has no private key", activeID)
+	}
+
+	keySet.active = active
+
+	return keySet, nil
+}
+
+// Active return key for signing new tokens.
+func (o *KeySet) Active() *SigningKey {
+	return o.active
+}
+
+// Get return key by kid.
+func (o *KeySet) Get(id string) (*SigningKey, bool) {
+	key, ok := o.keys[id]
+	return key, ok
+}
+
+// loadSigningKey read private key, or public key if private is not set.
+func loadSigningKey(file KeyFile) (*SigningKey, error) {
+	key := &SigningKey{ID: file.ID}
+
+	switch {
+	case file.PrivateKeyPath != "":
+		data, err := os.ReadFile(filepath.Clean(file.PrivateKeyPath))
+		if err != nil {
+			return nil, err
+		}
+
+		key.Private, key.Public, err = parsePrivateKey(data)
+		if err != nil {
+			return nil, err
+		}
+	case file.PublicKeyPath != "":
+		data, err := os.ReadFile(filepath.Clean(file.PublicKeyPath))
+		if err != nil {
+			return nil, err
+		}
+
+		key.Public, err = parsePublicKey(data)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("path to the key is not set")
+	}
+
+	method, err := signingMethodFor(key.Public)
+	if err != nil {
+		return nil, err
+	}
+	key.Method = method
+
+	return key, nil
+}
+
+func parsePrivateKey(data []byte) (crypto.PrivateKey, crypto.PublicKey, error) {
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		return key, &key.PublicKey, nil
+	}
+
+	if key, err := jwt.ParseECPrivateKeyFromPEM(data); err == nil {
+		return key, &key.PublicKey, nil
+	}
+
+	if key, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+		edKey, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return edKey, edKey.Public(), nil
+	}
+
+	return nil, nil, fmt.Errorf("unsupported private key, expected RSA, ECDSA or Ed25519 in PEM")
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+
+	if key, err := jwt.ParseECPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+
+	if key, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unsupported public key, expected RSA, ECDSA or Ed25519 in PEM")
+}
+
+// signingMethodFor select jwt algorithm by key type: RS256, ES256/ES384/ES512 or EdDSA.
+func signingMethodFor(public crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256():
+			return jwt.SigningMethodES256, nil
+		case elliptic.P384():
+			return jwt.SigningMethodES384, nil
+		case elliptic.P521():
+			return jwt.SigningMethodES512, nil
+		}
+		return nil, fmt.Errorf("unsupported elliptic curve %v", key.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", public)
+	}
+}
@@ -0,0 +1,174 @@
+package cryptography
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writePEMKeys save private and public key in dir, return paths.
+func writePEMKeys(t *testing.T, dir, name string, private crypto.PrivateKey, public crypto.PublicKey) (string, string) {
+	t.Helper()
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey error = %v", err)
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey error = %v", err)
+	}
+
+	privatePath := filepath.Join(dir, name+".pem")
+	publicPath := filepath.Join(dir, name+".pub.pem")
+
+	if err = os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0600); err != nil {
+		t.Fatalf("WriteFile error = %v", err)
+	}
+
+	if err = os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0600); err != nil {
+		t.Fatalf("WriteFile error = %v", err)
+	}
+
+	return privatePath, publicPath
+}
+
+func TestTokenJWTWithKeys(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey error = %v", err)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey error = %v", err)
+	}
+
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey error = %v", err)
+	}
+
+	rsaPrivate, rsaPublic := writePEMKeys(t, dir, "rsa", rsaKey, &rsaKey.PublicKey)
+	ecPrivate, ecPublic := writePEMKeys(t, dir, "ec", ecKey, &ecKey.PublicKey)
+	edPrivatePath, edPublicPath := writePEMKeys(t, dir, "ed", edPrivate, edPublic)
+
+	var tests = []struct {
+		name    string
+		kid     string
+		files   []KeyFile
+		wantAlg string
+		wantKty string
+	}{
+		{name: "Case-1: RS256",
+			kid:     "rsa",
+			files:   []KeyFile{{ID: "rsa", PrivateKeyPath: rsaPrivate}},
+			wantAlg: "RS256",
+			wantKty: "RSA",
+		},
+		{name: "Case-2: ES256",
+			kid:     "ec",
+			files:   []KeyFile{{ID: "ec", PrivateKeyPath: ecPrivate}},
+			wantAlg: "ES256",
+			wantKty: "EC",
+		},
+		{name: "Case-3: EdDSA",
+			kid:     "ed",
+			files:   []KeyFile{{ID: "ed", PrivateKeyPath: edPrivatePath}},
+			wantAlg: "EdDSA",
+			wantKty: "OKP",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := LoadKeySet(tt.kid, tt.files...)
+			if err != nil {
+				t.Fatalf("LoadKeySet error = %v", err)
+			}
+
+			o := NewTokenJWTWithKeys(keys, ClaimsOptions{})
+			token, _, err := o.CreateToken(testSubject, time.Minute)
+			if err != nil {
+				t.Fatalf("CreateToken error = %v", err)
+			}
+
+			claims, err := o.ParseToken(token)
+			if err != nil {
+				t.Fatalf("ParseToken error = %v", err)
+			}
+
+			if claims.Email != "test@email.com" {
+				t.Errorf("got email = %v", claims.Email)
+			}
+
+			jwks := o.JWKS()
+			if len(jwks.Keys) != 1 || jwks.Keys[0].Alg != tt.wantAlg ||
+				jwks.Keys[0].Kty != tt.wantKty || jwks.Keys[0].Kid != tt.kid {
+				t.Errorf("got jwks = %+v, want alg %v, kty %v", jwks, tt.wantAlg, tt.wantKty)
+			}
+		})
+	}
+
+	t.Run("Case-4: token of the retired key is valid after rotation", func(t *testing.T) {
+		oldKeys, err := LoadKeySet("rsa", KeyFile{ID: "rsa", PrivateKeyPath: rsaPrivate})
+		if err != nil {
+			t.Fatalf("LoadKeySet error = %v", err)
+		}
+
+		token, _, err := NewTokenJWTWithKeys(oldKeys, ClaimsOptions{}).CreateToken(testSubject, time.Minute)
+		if err != nil {
+			t.Fatalf("CreateToken error = %v", err)
+		}
+
+		newKeys, err := LoadKeySet("ed",
+			KeyFile{ID: "ed", PrivateKeyPath: edPrivatePath},
+			KeyFile{ID: "rsa", PublicKeyPath: rsaPublic},
+			KeyFile{ID: "ec", PublicKeyPath: ecPublic},
+		)
+		if err != nil {
+			t.Fatalf("LoadKeySet error = %v", err)
+		}
+
+		if _, err = NewTokenJWTWithKeys(newKeys, ClaimsOptions{}).ParseToken(token); err != nil {
+			t.Errorf("ParseToken error = %v", err)
+		}
+
+		if len(newKeys.JWKS().Keys) != 3 {
+			t.Errorf("got %v keys in jwks, want 3", len(newKeys.JWKS().Keys))
+		}
+	})
+
+	t.Run("Case-5: HMAC token is rejected with asymmetric keys", func(t *testing.T) {
+		keys, err := LoadKeySet("ed", KeyFile{ID: "ed", PrivateKeyPath: edPrivatePath})
+		if err != nil {
+			t.Fatalf("LoadKeySet error = %v", err)
+		}
+
+		token, _, err := NewTokenJWT("hello", ClaimsOptions{}).CreateToken(testSubject, time.Minute)
+		if err != nil {
+			t.Fatalf("CreateToken error = %v", err)
+		}
+
+		if _, err = NewTokenJWTWithKeys(keys, ClaimsOptions{}).ParseToken(token); err == nil {
+			t.Errorf("HMAC token accepted")
+		}
+	})
+
+	t.Run("Case-6: active key without private part", func(t *testing.T) {
+		if _, err := LoadKeySet("ed", KeyFile{ID: "ed", PublicKeyPath: edPublicPath}); err == nil {
+			t.Errorf("LoadKeySet accepted active key without private key")
+		}
+	})
+}
@@ -5,19 +5,29 @@ import "time"
 type ManagerToken interface {
 	AccessToken
 	RefreshToken
+	VerificationToken
+	TokenRevoker
 }
 
 type ManagerToken1 interface {
-	CreateToken(email string, role string, ttl time.Duration) (string, error)
+	CreateToken(subject TokenSubject, ttl time.Duration) (string, string, error)
 	RefreshToken
 }
 
-func NewManagerToken(secret string) ManagerToken {
-	accessToken := NewTokenJWT(secret)
+// NewManagerToken return ManagerToken, access tokens are signed with keys if the set is not nil.
+func NewManagerToken(secret string, keys *KeySet, opts ClaimsOptions) ManagerToken {
+	accessToken := NewTokenJWT(secret, opts)
+	if keys != nil {
+		accessToken = NewTokenJWTWithKeys(keys, opts)
+	}
 	refreshToken := NewRefreshToken()
+	verificationToken := NewTokenVerification(secret)
+	denylist := NewTokenDenylist()
 
 	return struct {
 		AccessToken
 		RefreshToken
-	}{accessToken, refreshToken}
+		VerificationToken
+		TokenRevoker
+	}{accessToken, refreshToken, verificationToken, denylist}
 }
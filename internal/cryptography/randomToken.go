@@ -0,0 +1,31 @@
+package cryptography
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// randomTokenSize number of random bytes in the token.
+const randomTokenSize = 32
+
+// RandomToken return random token for the user and its hash for the database,
+// it is used by single-use links like password reset and public links of content.
+// Only the hash is stored, so a leaked table can't be used to follow the links.
+func RandomToken() (token string, tokenHash string, err error) {
+	buf := make([]byte, randomTokenSize)
+	if _, err = rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(buf)
+
+	return token, HashToken(token), nil
+}
+
+// HashToken return hex encoded sha256 of the token.
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
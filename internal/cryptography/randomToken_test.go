@@ -0,0 +1,37 @@
+package cryptography
+
+import "testing"
+
+func TestRandomToken(t *testing.T) {
+	token, tokenHash, err := RandomToken()
+	if err != nil {
+		t.Fatalf("RandomToken error = %v", err)
+	}
+
+	var tests = []struct {
+		name  string
+		token string
+		want  bool
+	}{
+		{name: "Case-1: hash of the same token", token: token, want: true},
+		{name: "Case-2: hash of another token", token: token + "a", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HashToken(tt.token) == tokenHash; got != tt.want {
+				t.Errorf("HashToken() equal = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Case-3: tokens are unique", func(t *testing.T) {
+		next, _, err := RandomToken()
+		if err != nil {
+			t.Fatalf("RandomToken error = %v", err)
+		}
+
+		if next == token {
+			t.Errorf("RandomToken() return the same token twice")
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package cryptography
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const verificationPurpose = "email-verification"
+
+type VerificationToken interface {
+	CreateVerificationToken(email string, ttl time.Duration) (string, error)
+	ParseVerificationToken(inputToken string) (email string, err error)
+}
+
+// TokenVerification signs email confirmation tokens,
+// the key is derived from the secret so access tokens can't be used instead of it.
+type TokenVerification struct {
+	secretKey []byte
+}
+
+func NewTokenVerification(secret string) *TokenVerification {
+	key := sha256.Sum256([]byte(secret + ":" + verificationPurpose))
+	return &TokenVerification{secretKey: key[:]}
+}
+
+// verificationClaims include custom claims on verification token.
+type verificationClaims struct {
+	Email   string `json:"email"`
+	Purpose string `json:"purpose"`
+	jwt.StandardClaims
+}
+
+// CreateVerificationToken create new signed token for email confirmation.
+func (o *TokenVerification) CreateVerificationToken(email string, ttl time.Duration) (string, error) {
+	claims := verificationClaims{email, verificationPurpose,
+		jwt.StandardClaims{ExpiresAt: time.Now().Add(ttl).Unix()},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	return token.SignedString(o.secretKey)
+}
+
+// ParseVerificationToken parsing input token, and return email from token.
+func (o *TokenVerification) ParseVerificationToken(inputToken string) (email string, err error) {
+	claims := &verificationClaims{}
+
+	token, err := jwt.ParseWithClaims(inputToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return o.secretKey, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if !token.Valid || claims.Purpose != verificationPurpose || claims.Email == "" {
+		return "", fmt.Errorf("not valid token")
+	}
+
+	return claims.Email, nil
+}
@@ -0,0 +1,60 @@
+package cryptography
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenVerification(t *testing.T) {
+	var tests = []struct {
+		name      string
+		secretKey string
+		email     string
+		ttl       time.Duration
+		wantErr   bool
+	}{
+		{name: "Case-1: valid token",
+			secretKey: "hello",
+			email:     "test@email.com",
+			ttl:       time.Minute * 15,
+			wantErr:   false,
+		},
+		{name: "Case-2: expired token",
+			secretKey: "hello",
+			email:     "test@email.com",
+			ttl:       -time.Minute,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewTokenVerification(tt.secretKey)
+			got, err := o.CreateVerificationToken(tt.email, tt.ttl)
+			if err != nil {
+				t.Errorf("CreateVerificationToken error = %v", err)
+				return
+			}
+
+			email, err := o.ParseVerificationToken(got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseVerificationToken error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && email != tt.email {
+				t.Errorf("got = %v, want email: %v", email, tt.email)
+			}
+		})
+	}
+}
+
+func TestTokenVerificationRejectAccessToken(t *testing.T) {
+	accessToken, _, err := NewTokenJWT("hello", ClaimsOptions{}).CreateToken(testSubject, time.Minute)
+	if err != nil {
+		t.Fatalf("CreateToken error = %v", err)
+	}
+
+	if _, err = NewTokenVerification("hello").ParseVerificationToken(accessToken); err == nil {
+		t.Errorf("access token accepted as verification token")
+	}
+}
@@ -0,0 +1,180 @@
+package lockout
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Dsmit05/metida/internal/logger"
+	"github.com/Dsmit05/metida/internal/models"
+)
+
+// Targets of the failed attempts counters.
+const (
+	TargetAccount = "account"
+	TargetIP      = "ip"
+)
+
+// maxBackoffShift limits the exponent of the backoff, so the duration doesn't overflow.
+const maxBackoffShift = 20
+
+// storeCleanupInterval how often expired counters are removed from the postgres store.
+const storeCleanupInterval = time.Hour
+
+// Store keeps counters of failed sign-in attempts, a missing key returns empty attempts.
+// IncrementLoginAttempts must be atomic, so concurrent failures are all counted.
+type Store interface {
+	ReadLoginAttempts(ctx context.Context, key string) (*models.LoginAttempts, error)
+	IncrementLoginAttempts(ctx context.Context, key string, window time.Duration) (models.LoginAttempts, error)
+	LockLoginAttempts(ctx context.Context, key string, lockedUntil int64) error
+	DeleteLoginAttempts(ctx context.Context, key string) error
+	DeleteExpiredLoginAttempts(ctx context.Context) error
+}
+
+// Options of the lockout.
+type Options struct {
+	MaxAccountFailures int           // failures of one account before it is locked
+	MaxIPFailures      int           // failures from one ip before it is locked
+	BaseLockout        time.Duration // the first lockout, every next one is twice as long
+	MaxLockout         time.Duration
+	Window             time.Duration // counters are forgotten after the window without failures
+}
+
+type metricI interface {
+	IncLoginLockout(target string)
+	IncLoginBlocked(target string)
+}
+
+type configLockoutI interface {
+	GetLockoutStore() string
+	GetLockoutOptions() Options
+}
+
+// Guard tracks failed sign-in attempts per account and per ip,
+// after the limit of failures the target is locked with exponential backoff.
+type Guard struct {
+	store  Store
+	opts   Options
+	metric metricI
+}
+
+// NewGuard return Guard with the store selected in config, db is used by the postgres store.
+func NewGuard(cfg configLockoutI, db Store, metric metricI) (*Guard, error) {
+	switch cfg.GetLockoutStore() {
+	case "", "memory":
+		return NewGuardWithStore(NewMemoryStore(), cfg.GetLockoutOptions(), metric), nil
+	case "postgres":
+		o := NewGuardWithStore(db, cfg.GetLockoutOptions(), metric)
+		go o.cleanupWithInterval(storeCleanupInterval)
+
+		return o, nil
+	default:
+		return nil, fmt.Errorf("unknown lockout store: %v", cfg.GetLockoutStore())
+	}
+}
+
+func NewGuardWithStore(store Store, opts Options, metric metricI) *Guard {
+	return &Guard{store: store, opts: opts, metric: metric}
+}
+
+// Check return time left until the account or the ip is unlocked, zero if sign-in is allowed.
+func (o *Guard) Check(ctx context.Context, email, ip string) (time.Duration, error) {
+	now := time.Now()
+	var retryAfter time.Duration
+
+	for target, key := range o.keys(email, ip) {
+		attempts, err := o.store.ReadLoginAttempts(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+
+		left := time.Unix(attempts.LockedUntil, 0).Sub(now)
+		if left <= 0 {
+			continue
+		}
+
+		o.metric.IncLoginBlocked(target)
+		if left > retryAfter {
+			retryAfter = left
+		}
+	}
+
+	return retryAfter, nil
+}
+
+// Fail registers failed attempt for the account and the ip.
+func (o *Guard) Fail(ctx context.Context, email, ip string) error {
+	now := time.Now()
+
+	for target, key := range o.keys(email, ip) {
+		attempts, err := o.store.IncrementLoginAttempts(ctx, key, o.opts.Window)
+		if err != nil {
+			return err
+		}
+
+		over := int(attempts.Failures) - o.maxFailures(target)
+		if over < 0 {
+			continue
+		}
+
+		if err = o.store.LockLoginAttempts(ctx, key, now.Add(o.backoff(over)).Unix()); err != nil {
+			return err
+		}
+
+		o.metric.IncLoginLockout(target)
+	}
+
+	return nil
+}
+
+// Success resets counter of the account, the ip counter is kept,
+// so one own account can't be used to reset failures of others.
+func (o *Guard) Success(ctx context.Context, email string) error {
+	return o.store.DeleteLoginAttempts(ctx, accountKey(email))
+}
+
+// backoff return lockout duration after over failures above the limit.
+func (o *Guard) backoff(over int) time.Duration {
+	if over > maxBackoffShift {
+		over = maxBackoffShift
+	}
+
+	lockout := o.opts.BaseLockout << over
+	if o.opts.MaxLockout > 0 && lockout > o.opts.MaxLockout {
+		lockout = o.opts.MaxLockout
+	}
+
+	return lockout
+}
+
+func (o *Guard) cleanupWithInterval(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	for {
+		<-ticker.C
+
+		if err := o.store.DeleteExpiredLoginAttempts(context.Background()); err != nil {
+			logger.Error("Guard.store.DeleteExpiredLoginAttempts()", err)
+		}
+	}
+}
+
+func (o *Guard) maxFailures(target string) int {
+	if target == TargetIP {
+		return o.opts.MaxIPFailures
+	}
+
+	return o.opts.MaxAccountFailures
+}
+
+func (o *Guard) keys(email, ip string) map[string]string {
+	return map[string]string{
+		TargetAccount: accountKey(email),
+		TargetIP:      "ip:" + ip,
+	}
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
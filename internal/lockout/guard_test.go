@@ -0,0 +1,123 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type metricMock struct {
+	lockouts map[string]int
+	blocked  map[string]int
+}
+
+func newMetricMock() *metricMock {
+	return &metricMock{lockouts: map[string]int{}, blocked: map[string]int{}}
+}
+
+func (o *metricMock) IncLoginLockout(target string) { o.lockouts[target]++ }
+func (o *metricMock) IncLoginBlocked(target string) { o.blocked[target]++ }
+
+// syncMetricMock ignores metrics, it is safe for concurrent use.
+type syncMetricMock struct{}
+
+func (o *syncMetricMock) IncLoginLockout(target string) {}
+func (o *syncMetricMock) IncLoginBlocked(target string) {}
+
+func TestGuard(t *testing.T) {
+	ctx := context.Background()
+	opts := Options{
+		MaxAccountFailures: 3,
+		MaxIPFailures:      5,
+		BaseLockout:        time.Minute,
+		MaxLockout:         time.Minute * 3,
+		Window:             time.Hour,
+	}
+
+	t.Run("Case-1: account is locked after max failures", func(t *testing.T) {
+		metric := newMetricMock()
+		o := NewGuardWithStore(NewMemoryStore(), opts, metric)
+
+		for i := 0; i < opts.MaxAccountFailures-1; i++ {
+			if err := o.Fail(ctx, "test@email.com", "127.0.0.1"); err != nil {
+				t.Fatalf("Fail error = %v", err)
+			}
+		}
+
+		if retryAfter, _ := o.Check(ctx, "test@email.com", "127.0.0.1"); retryAfter != 0 {
+			t.Fatalf("Check() = %v before max failures", retryAfter)
+		}
+
+		_ = o.Fail(ctx, "TEST@email.com", "127.0.0.1")
+
+		retryAfter, err := o.Check(ctx, "test@email.com", "10.0.0.1")
+		if err != nil || retryAfter <= 0 || retryAfter > opts.BaseLockout {
+			t.Errorf("Check() = %v, %v, want lockout up to %v", retryAfter, err, opts.BaseLockout)
+		}
+
+		if metric.lockouts[TargetAccount] != 1 || metric.blocked[TargetAccount] != 1 {
+			t.Errorf("metrics = %v %v", metric.lockouts, metric.blocked)
+		}
+	})
+
+	t.Run("Case-2: lockout grows exponentially up to max", func(t *testing.T) {
+		var tests = []struct {
+			over int
+			want time.Duration
+		}{
+			{over: 0, want: time.Minute},
+			{over: 1, want: time.Minute * 2},
+			{over: 2, want: time.Minute * 3},
+			{over: 100, want: time.Minute * 3},
+		}
+
+		o := NewGuardWithStore(NewMemoryStore(), opts, newMetricMock())
+		for _, tt := range tests {
+			if got := o.backoff(tt.over); got != tt.want {
+				t.Errorf("backoff(%v) = %v, want %v", tt.over, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("Case-3: success resets only the account", func(t *testing.T) {
+		o := NewGuardWithStore(NewMemoryStore(), opts, newMetricMock())
+
+		for i := 0; i < opts.MaxIPFailures; i++ {
+			_ = o.Fail(ctx, "user"+string(rune('a'+i))+"@email.com", "127.0.0.1")
+		}
+		_ = o.Fail(ctx, "test@email.com", "10.0.0.1")
+
+		if err := o.Success(ctx, "test@email.com"); err != nil {
+			t.Fatalf("Success error = %v", err)
+		}
+
+		attempts, _ := o.store.ReadLoginAttempts(ctx, accountKey("test@email.com"))
+		if attempts.Failures != 0 {
+			t.Errorf("account failures = %v after success", attempts.Failures)
+		}
+
+		if retryAfter, _ := o.Check(ctx, "other@email.com", "127.0.0.1"); retryAfter <= 0 {
+			t.Errorf("Check() = %v, want ip lockout", retryAfter)
+		}
+	})
+
+	t.Run("Case-4: concurrent failures are all counted", func(t *testing.T) {
+		o := NewGuardWithStore(NewMemoryStore(), opts, &syncMetricMock{})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = o.Fail(ctx, "test@email.com", "127.0.0.1")
+			}()
+		}
+		wg.Wait()
+
+		attempts, _ := o.store.ReadLoginAttempts(ctx, accountKey("test@email.com"))
+		if attempts.Failures != 50 {
+			t.Errorf("account failures = %v, want 50", attempts.Failures)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package lockout
+
+import (
+	"context"
+	"time"
+
+	"github.com/Dsmit05/metida/internal/models"
+	"github.com/Dsmit05/metida/pkg/cache/lru"
+)
+
+const (
+	// memoryStoreCapacity limits memory used by counters, a flood of new ips or emails
+	// evicts the least recently failed keys.
+	memoryStoreCapacity        = 100000
+	memoryStoreCleanupInterval = time.Minute
+)
+
+// attemptsKey is key of the memory store cache.
+type attemptsKey string
+
+func (o attemptsKey) Equally(k lru.KeyI) bool {
+	return o == k
+}
+
+// attemptsValue keeps expiration, because the cache returns expired values until cleanup.
+type attemptsValue struct {
+	attempts  models.LoginAttempts
+	expiresIn int64 // unix time, after it the counter is the same as a missing one
+}
+
+// MemoryStore keeps counters in the process memory, counters are lost on restart
+// and are not shared between instances.
+type MemoryStore struct {
+	cache *lru.Cache
+	now   func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return newMemoryStore(lru.NewLruCache(memoryStoreCapacity, 0, memoryStoreCleanupInterval), time.Now)
+}
+
+func newMemoryStore(cache *lru.Cache, now func() time.Time) *MemoryStore {
+	return &MemoryStore{cache: cache, now: now}
+}
+
+func (o *MemoryStore) ReadLoginAttempts(ctx context.Context, key string) (*models.LoginAttempts, error) {
+	value, ok := o.cache.Peek(attemptsKey(key))
+	if !ok {
+		return &models.LoginAttempts{}, nil
+	}
+
+	stored := value.(attemptsValue)
+	if stored.expiresIn <= o.now().Unix() {
+		return &models.LoginAttempts{}, nil
+	}
+
+	attempts := stored.attempts
+
+	return &attempts, nil
+}
+
+// IncrementLoginAttempts adds one failure under the lock of the cache,
+// the counter of the expired key starts again.
+func (o *MemoryStore) IncrementLoginAttempts(
+	ctx context.Context, key string, window time.Duration) (models.LoginAttempts, error) {
+	value, err := o.cache.Modify(attemptsKey(key), func(value lru.ValI, found bool) (lru.ValI, time.Duration) {
+		var stored attemptsValue
+		if found && value.(attemptsValue).expiresIn > o.now().Unix() {
+			stored = value.(attemptsValue)
+		}
+
+		stored.attempts.Failures++
+
+		if expiresIn := o.now().Add(window).Unix(); expiresIn > stored.expiresIn {
+			stored.expiresIn = expiresIn
+		}
+
+		return stored, o.ttl(stored.expiresIn)
+	})
+	if err != nil {
+		return models.LoginAttempts{}, err
+	}
+
+	return value.(attemptsValue).attempts, nil
+}
+
+// LockLoginAttempts locks the key until lockedUntil, the longer lockout is kept.
+func (o *MemoryStore) LockLoginAttempts(ctx context.Context, key string, lockedUntil int64) error {
+	_, err := o.cache.Modify(attemptsKey(key), func(value lru.ValI, found bool) (lru.ValI, time.Duration) {
+		var stored attemptsValue
+		if found && value.(attemptsValue).expiresIn > o.now().Unix() {
+			stored = value.(attemptsValue)
+		}
+
+		if lockedUntil > stored.attempts.LockedUntil {
+			stored.attempts.LockedUntil = lockedUntil
+		}
+
+		if lockedUntil > stored.expiresIn {
+			stored.expiresIn = lockedUntil
+		}
+
+		return stored, o.ttl(stored.expiresIn)
+	})
+
+	return err
+}
+
+func (o *MemoryStore) DeleteLoginAttempts(ctx context.Context, key string) error {
+	o.cache.Delete(attemptsKey(key))
+
+	return nil
+}
+
+func (o *MemoryStore) DeleteExpiredLoginAttempts(ctx context.Context) error {
+	o.cache.ClearExpiredData()
+
+	return nil
+}
+
+// ttl return lifetime of the cache unit, an already expired value is removed by the next cleanup.
+func (o *MemoryStore) ttl(expiresIn int64) time.Duration {
+	ttl := time.Unix(expiresIn, 0).Sub(o.now())
+	if ttl <= 0 {
+		ttl = time.Nanosecond
+	}
+
+	return ttl
+}
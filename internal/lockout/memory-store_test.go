@@ -0,0 +1,65 @@
+package lockout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Dsmit05/metida/pkg/cache/lru"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	o := newMemoryStore(lru.NewLruCache(2, 0, 0), func() time.Time { return now })
+
+	t.Run("Case-1: counter is increased and locked", func(t *testing.T) {
+		_, _ = o.IncrementLoginAttempts(ctx, "key", time.Minute)
+		attempts, _ := o.IncrementLoginAttempts(ctx, "key", time.Minute)
+		if attempts.Failures != 2 {
+			t.Fatalf("IncrementLoginAttempts() failures = %v, want 2", attempts.Failures)
+		}
+
+		_ = o.LockLoginAttempts(ctx, "key", now.Add(time.Hour).Unix())
+		_ = o.LockLoginAttempts(ctx, "key", now.Add(time.Minute).Unix())
+
+		got, _ := o.ReadLoginAttempts(ctx, "key")
+		if got.Failures != 2 || got.LockedUntil != now.Add(time.Hour).Unix() {
+			t.Errorf("ReadLoginAttempts() = %+v, want the longer lockout", got)
+		}
+	})
+
+	t.Run("Case-2: expired counter starts again", func(t *testing.T) {
+		now = now.Add(time.Hour * 2)
+
+		if got, _ := o.ReadLoginAttempts(ctx, "key"); got.Failures != 0 {
+			t.Errorf("ReadLoginAttempts() = %+v for expired key", got)
+		}
+
+		attempts, _ := o.IncrementLoginAttempts(ctx, "key", time.Minute)
+		if attempts.Failures != 1 || attempts.LockedUntil != 0 {
+			t.Errorf("IncrementLoginAttempts() = %+v, want new counter", attempts)
+		}
+	})
+
+	t.Run("Case-3: flood of keys is limited by capacity", func(t *testing.T) {
+		_, _ = o.IncrementLoginAttempts(ctx, "ip:1", time.Minute)
+		_, _ = o.IncrementLoginAttempts(ctx, "ip:2", time.Minute)
+
+		if o.cache.Len() != 2 {
+			t.Errorf("cache len = %v, want capacity 2", o.cache.Len())
+		}
+
+		if got, _ := o.ReadLoginAttempts(ctx, "key"); got.Failures != 0 {
+			t.Errorf("ReadLoginAttempts() = %+v, want evicted key", got)
+		}
+	})
+
+	t.Run("Case-4: delete", func(t *testing.T) {
+		_ = o.DeleteLoginAttempts(ctx, "ip:2")
+
+		if got, _ := o.ReadLoginAttempts(ctx, "ip:2"); got.Failures != 0 {
+			t.Errorf("ReadLoginAttempts() = %+v after delete", got)
+		}
+	})
+}
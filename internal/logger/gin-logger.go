@@ -13,6 +13,7 @@ type request struct {
 	IP        string
 	Email     string
 	Role      string
+	RequestID string
 }
 
 func (r *request) MarshalLogObject(enc zapcore.ObjectEncoder) error {
@@ -22,6 +23,7 @@ func (r *request) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 	enc.AddString("ip", r.IP)
 	enc.AddString("email", r.Email)
 	enc.AddString("role", r.Role)
+	enc.AddString("requestId", r.RequestID)
 	return nil
 }
 
@@ -44,6 +46,7 @@ func RequestsError(c *gin.Context, status, code int, description string, err err
 	newContext := c.Copy()
 	email := newContext.GetString("email")
 	role := newContext.GetString("role")
+	requestID := newContext.GetString("requestID")
 	path := newContext.Request.URL.Path
 	rawQuery := newContext.Request.URL.RawQuery
 	userAgent := newContext.Request.UserAgent()
@@ -56,6 +59,7 @@ func RequestsError(c *gin.Context, status, code int, description string, err err
 		IP:        ip,
 		Email:     email,
 		Role:      role,
+		RequestID: requestID,
 	}
 
 	var textError string
@@ -93,6 +97,7 @@ func RequestsInfo(c *gin.Context, status, code int, description string, data int
 	newContext := c.Copy()
 	email := newContext.GetString("email")
 	role := newContext.GetString("role")
+	requestID := newContext.GetString("requestID")
 	path := newContext.Request.URL.Path
 	rawQuery := newContext.Request.URL.RawQuery
 	userAgent := newContext.Request.UserAgent()
@@ -105,6 +110,7 @@ func RequestsInfo(c *gin.Context, status, code int, description string, data int
 		IP:        ip,
 		Email:     email,
 		Role:      role,
+		RequestID: requestID,
 	}
 
 	resp := &responseSuccess{
@@ -48,3 +48,8 @@ func Fatal(message string, err error) {
 func DatabaseError(message string, err error, any interface{}) {
 	ZapLog.Error(message, zap.Error(err), zap.Any("data", any))
 }
+
+// SecurityEvent logs events that may indicate an attack on user accounts.
+func SecurityEvent(event string, data interface{}) {
+	ZapLog.Warn("security event", zap.String("event", event), zap.Any("data", data))
+}
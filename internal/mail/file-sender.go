@@ -0,0 +1,48 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileSender appends letters to a file, used for local runs.
+type FileSender struct {
+	from string
+	path string
+	mx   sync.Mutex
+}
+
+func NewFileSender(from, path string) (*FileSender, error) {
+	if path == "" {
+		return nil, fmt.Errorf("the name of the mail file is not specified")
+	}
+
+	return &FileSender{from: from, path: filepath.Clean(path)}, nil
+}
+
+func (o *FileSender) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	o.mx.Lock()
+	defer o.mx.Unlock()
+
+	file, err := os.OpenFile(o.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(file, "Date: %v\nFrom: %v\nTo: %v\nSubject: %v\n\n%v\n\n",
+		time.Now().UTC().Format(time.RFC1123Z), o.from, msg.To, msg.Subject, msg.Body)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,27 @@
+package mail
+
+import (
+	"context"
+
+	"github.com/Dsmit05/metida/internal/logger"
+)
+
+// LogSender writes letters to the application log, used for local runs.
+type LogSender struct {
+	from string
+}
+
+func NewLogSender(from string) *LogSender {
+	return &LogSender{from: from}
+}
+
+func (o *LogSender) Send(ctx context.Context, msg Message) error {
+	logger.Debug("mail.LogSender.Send()", map[string]string{
+		"from":    o.from,
+		"to":      msg.To,
+		"subject": msg.Subject,
+		"body":    msg.Body,
+	})
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+)
+
+// Message is a letter for the user.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers letters to users, implementations can be swapped without changing the api.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+type configMailI interface {
+	GetMailSender() string
+	GetMailFrom() string
+	GetMailFilePath() string
+}
+
+// NewSender return Sender selected in config.
+func NewSender(cfg configMailI) (Sender, error) {
+	switch cfg.GetMailSender() {
+	case "", "log":
+		return NewLogSender(cfg.GetMailFrom()), nil
+	case "file":
+		return NewFileSender(cfg.GetMailFrom(), cfg.GetMailFilePath())
+	default:
+		return nil, fmt.Errorf("unknown mail sender: %v", cfg.GetMailSender())
+	}
+}
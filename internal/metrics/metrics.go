@@ -9,6 +9,9 @@ type ServiceMetrics struct {
 	httpRequestDurations   *prometheus.HistogramVec
 	httpRequestCounters    *prometheus.CounterVec
 	dbRequestErrorCounters prometheus.Counter
+	loginLockoutCounters   *prometheus.CounterVec
+	loginBlockedCounters   *prometheus.CounterVec
+	rateLimitCounters      *prometheus.CounterVec
 }
 
 func NewServiceMetrics() *ServiceMetrics {
@@ -27,13 +30,46 @@ func NewServiceMetrics() *ServiceMetrics {
 		Help: "The total number of errors events",
 	})
 
+	loginLockoutCounters := promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "login_lockout_counters",
+		Help: "The total number of lockouts after failed sign-in attempts",
+	}, []string{"target"})
+
+	loginBlockedCounters := promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "login_blocked_counters",
+		Help: "The total number of sign-in attempts rejected by lockout",
+	}, []string{"target"})
+
+	rateLimitCounters := promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rate_limit_rejected_counters",
+		Help: "The total number of requests rejected by rate limit",
+	}, []string{"group"})
+
 	return &ServiceMetrics{
 		httpRequestDurations,
 		httpRequestCounters,
-		dbRequestErrorCounters}
+		dbRequestErrorCounters,
+		loginLockoutCounters,
+		loginBlockedCounters,
+		rateLimitCounters}
 }
 
 // IncDbError увеличивает количество ошибок у бд
 func (o *ServiceMetrics) IncDbError() {
 	o.dbRequestErrorCounters.Inc()
 }
+
+// IncLoginLockout увеличивает количество блокировок входа, target - account или ip
+func (o *ServiceMetrics) IncLoginLockout(target string) {
+	o.loginLockoutCounters.With(prometheus.Labels{"target": target}).Inc()
+}
+
+// IncLoginBlocked увеличивает количество отклоненных попыток входа
+func (o *ServiceMetrics) IncLoginBlocked(target string) {
+	o.loginBlockedCounters.With(prometheus.Labels{"target": target}).Inc()
+}
+
+// IncRateLimitRejected увеличивает количество запросов, отклоненных ограничением частоты
+func (o *ServiceMetrics) IncRateLimitRejected(group string) {
+	o.rateLimitCounters.With(prometheus.Labels{"group": group}).Inc()
+}
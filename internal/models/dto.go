@@ -1,7 +1,12 @@
 package models
 
 type UserEmailRole struct {
+	UserID    int32
 	Email     string
 	Role      string
 	ExpiresIn int64
+	SessionID int32
+
+	AccessTokenID   string // последний access token сессии
+	AccessExpiresIn int64
 }
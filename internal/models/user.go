@@ -4,11 +4,26 @@ import (
 	"time"
 )
 
-// Blog блог приложения.
+// Blog запись блога приложения.
 type Blog struct {
 	ID          int32
 	Name        string
 	Description string
+	Slug        string // уникальный идентификатор записи для ссылок.
+	Status      string // draft, published или archived.
+	AuthorEmail string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	PublishedAt *time.Time // время первой публикации, nil у черновиков.
+	Tags        []Tag
+}
+
+// Tag тег записей блога.
+type Tag struct {
+	ID    int32
+	Name  string
+	Slug  string // нормализованное название, по нему фильтруются записи.
+	Posts int64  // число опубликованных записей с тегом, заполняется только в списке тегов.
 }
 
 // Content объект, которым владеет пользователь.
@@ -17,6 +32,94 @@ type Content struct {
 	UserEmail   string
 	Name        string
 	Description string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Access      string // owner, edit или read - права пользователя, запросившего контент.
+}
+
+// ContentRevision снимок контента после создания или изменения.
+type ContentRevision struct {
+	ID          int32
+	ContentID   int32
+	Revision    int32 // номер версии внутри контента, начиная с 1.
+	AuthorEmail string
+	Name        string
+	Description string
+	Summary     string // краткое описание изменений.
+	CreatedAt   time.Time
+}
+
+// ContentShare доступ другого пользователя к контенту.
+type ContentShare struct {
+	ContentID  int32
+	UserEmail  string
+	Permission string // read или edit.
+	CreatedAt  time.Time
+}
+
+// ContentLink публичная ссылка на контент, хранится только хеш токена ссылки.
+type ContentLink struct {
+	ID        int32
+	ContentID int32
+	ExpiresIn int64
+	CreatedAt time.Time
+}
+
+// ContentFilter параметры выборки контента пользователя.
+type ContentFilter struct {
+	Name     string // часть названия, пустая строка - без фильтра.
+	SortBy   string // created_at, updated_at или name.
+	SortDesc bool
+	Limit    int32
+	Cursor   *ContentCursor // nil - первая страница.
+}
+
+// ContentCursor позиция последнего элемента предыдущей страницы.
+type ContentCursor struct {
+	ID   int32     `json:"id"`
+	Name string    `json:"name,omitempty"`
+	Time time.Time `json:"time,omitempty"`
+}
+
+// BlogSearchResult запись блога, найденная полнотекстовым поиском.
+type BlogSearchResult struct {
+	Blog
+	Rank    float32 // релевантность, чем больше, тем выше в выдаче.
+	Snippet string  // экранированный фрагмент текста, найденные слова в тегах <mark>.
+}
+
+// ContentSearchResult контент пользователя, найденный полнотекстовым поиском.
+type ContentSearchResult struct {
+	Content
+	Rank    float32
+	Snippet string
+}
+
+// Attachment файл, прикрепленный к контенту, сам файл хранится в хранилище по StorageKey.
+type Attachment struct {
+	ID          int32
+	ContentID   int32
+	UserEmail   string // кто загрузил файл.
+	FileName    string
+	ContentType string
+	Size        int64 // в байтах.
+	StorageKey  string
+	CreatedAt   time.Time
+}
+
+// Comment комментарий к записи блога.
+type Comment struct {
+	ID          int32
+	BlogID      int32
+	ParentID    int32 // 0 у комментариев первого уровня.
+	AuthorEmail string
+	AuthorName  string // заполняется только в списке комментариев записи.
+	Body        string
+	Status      string // pending, approved, rejected или hidden.
+	IsDeleted   bool   // удален автором, текст не показывается.
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Replies     []Comment // одобренные ответы, заполняются только в списке комментариев записи.
 }
 
 // Session хранить информацию о сессиях пользователя.
@@ -24,11 +127,21 @@ type Session struct {
 	ID           int32
 	UserEmail    string
 	RefreshToken string
-	AccessToken  string // Можно выдавать сервисам токен с увеличенным сроком жизни, сейчас не используется.
+	AccessToken  string // id (jti) последнего access token, выданного в рамках сессии.
 	UserAgent    string
 	IP           string
 	ExpiresIn    int64
 	CreatedAt    time.Time
+
+	AccessExpiresIn int64 // время истечения последнего access token.
+}
+
+// RetiredRefreshToken refresh token, который уже был обменян в рамках сессии.
+type RetiredRefreshToken struct {
+	Token     string
+	SessionID int32
+	UserEmail string
+	RetiredAt time.Time
 }
 
 // User хранить информацию о пользователе.
@@ -39,4 +152,17 @@ type User struct {
 	Email     string
 	Role      string
 	IsDeleted bool
+	Verified  bool // пользователь подтвердил почту
+}
+
+// RolePermission разрешение, выданное роли.
+type RolePermission struct {
+	Role       string
+	Permission string
+}
+
+// LoginAttempts неудачные попытки входа для аккаунта или ip.
+type LoginAttempts struct {
+	Failures    int32
+	LockedUntil int64 // unix time, до которого вход запрещен
 }
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Rate of the token bucket: Requests per Period, Burst is the bucket capacity.
+type Rate struct {
+	Requests int
+	Period   time.Duration
+	Burst    int // if zero, equals Requests
+}
+
+// capacity return size of the bucket.
+func (o Rate) capacity() float64 {
+	if o.Burst > 0 {
+		return float64(o.Burst)
+	}
+
+	return float64(o.Requests)
+}
+
+// perSecond return speed of the bucket refill.
+func (o Rate) perSecond() float64 {
+	if o.Period <= 0 {
+		return 0
+	}
+
+	return float64(o.Requests) / o.Period.Seconds()
+}
+
+// Result of taking a token from the bucket.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // time until the bucket is full
+	RetryAfter time.Duration // time until the next token, if not allowed
+}
+
+// Store keeps token buckets, implementations for shared backends
+// must take the token atomically, because several instances use the same bucket.
+type Store interface {
+	Take(ctx context.Context, key string, rate Rate) (Result, error)
+}
+
+// Keys of the rule, requests with the same key share the bucket.
+const (
+	KeyIP      = "ip"
+	KeyEmail   = "email"    // for anonymous requests ip is used
+	KeyIPEmail = "ip+email" // separate bucket for every user on every ip
+)
+
+// Rule of the route group.
+type Rule struct {
+	Rate
+	Key string
+}
+
+type configRateLimitI interface {
+	GetRateLimitStore() string
+}
+
+// NewStore return Store selected in config.
+func NewStore(cfg configRateLimitI) (Store, error) {
+	switch cfg.GetRateLimitStore() {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store: %v", cfg.GetRateLimitStore())
+	}
+}
@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// memoryStoreCleanupInterval how often full buckets are removed.
+const memoryStoreCleanupInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	fullAt  time.Time // after this time the bucket is full and can be removed
+}
+
+// MemoryStore keeps buckets in the process memory, limits are not shared between instances.
+type MemoryStore struct {
+	mutex   *sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	o := newMemoryStore(time.Now)
+	go o.cleanupWithInterval(memoryStoreCleanupInterval)
+
+	return o
+}
+
+func newMemoryStore(now func() time.Time) *MemoryStore {
+	return &MemoryStore{
+		mutex:   &sync.Mutex{},
+		buckets: make(map[string]*bucket),
+		now:     now,
+	}
+}
+
+// Take removes one token from the bucket of the key, if the bucket is not empty.
+func (o *MemoryStore) Take(ctx context.Context, key string, rate Rate) (Result, error) {
+	capacity := rate.capacity()
+	perSecond := rate.perSecond()
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	now := o.now()
+
+	b, ok := o.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		o.buckets[key] = b
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*perSecond)
+	b.updated = now
+
+	result := Result{Limit: int(capacity)}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / perSecond)
+	}
+
+	result.Remaining = int(b.tokens)
+	result.Reset = secondsToDuration((capacity - b.tokens) / perSecond)
+	b.fullAt = now.Add(result.Reset)
+
+	return result, nil
+}
+
+// cleanup removes full buckets, they are the same as missing ones.
+func (o *MemoryStore) cleanup() {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	now := o.now()
+	for key, b := range o.buckets {
+		if !now.Before(b.fullAt) {
+			delete(o.buckets, key)
+		}
+	}
+}
+
+func (o *MemoryStore) cleanupWithInterval(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	for {
+		<-ticker.C
+		o.cleanup()
+	}
+}
+
+// secondsToDuration converts seconds to duration, infinite values mean the bucket is never refilled.
+func secondsToDuration(seconds float64) time.Duration {
+	if math.IsInf(seconds, 0) || math.IsNaN(seconds) {
+		return 0
+	}
+
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	o := newMemoryStore(func() time.Time { return now })
+
+	rate := Rate{Requests: 2, Period: time.Second * 10, Burst: 3}
+
+	var tests = []struct {
+		name          string
+		advance       time.Duration
+		key           string
+		wantAllowed   bool
+		wantRemaining int
+	}{
+		{name: "Case-1: first request", key: "ip:1", wantAllowed: true, wantRemaining: 2},
+		{name: "Case-2: second request", key: "ip:1", wantAllowed: true, wantRemaining: 1},
+		{name: "Case-3: last token of burst", key: "ip:1", wantAllowed: true, wantRemaining: 0},
+		{name: "Case-4: bucket is empty", key: "ip:1", wantAllowed: false, wantRemaining: 0},
+		{name: "Case-5: another key has own bucket", key: "ip:2", wantAllowed: true, wantRemaining: 2},
+		{name: "Case-6: one token is refilled", advance: time.Second * 5, key: "ip:1", wantAllowed: true, wantRemaining: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+
+			got, err := o.Take(ctx, tt.key, rate)
+			if err != nil {
+				t.Fatalf("Take error = %v", err)
+			}
+
+			if got.Allowed != tt.wantAllowed || got.Remaining != tt.wantRemaining || got.Limit != 3 {
+				t.Errorf("Take() = %+v, want allowed %v, remaining %v", got, tt.wantAllowed, tt.wantRemaining)
+			}
+
+			if !got.Allowed && got.RetryAfter != time.Second*5 {
+				t.Errorf("RetryAfter = %v, want %v", got.RetryAfter, time.Second*5)
+			}
+		})
+	}
+
+	t.Run("Case-7: full buckets are removed", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		o.cleanup()
+
+		if len(o.buckets) != 0 {
+			t.Errorf("buckets = %v, want empty", len(o.buckets))
+		}
+	})
+}
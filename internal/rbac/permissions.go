@@ -0,0 +1,82 @@
+package rbac
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Dsmit05/metida/internal/models"
+)
+
+type storeI interface {
+	ListRolePermissions(ctx context.Context) ([]models.RolePermission, error)
+}
+
+// Permissions caches the mapping of roles to permissions loaded from the store.
+type Permissions struct {
+	store storeI
+	mutex *sync.RWMutex
+	roles map[string]map[string]struct{}
+}
+
+// NewPermissions return Permissions with the mapping loaded from the store.
+func NewPermissions(ctx context.Context, store storeI) (*Permissions, error) {
+	o := &Permissions{
+		store: store,
+		mutex: &sync.RWMutex{},
+		roles: make(map[string]map[string]struct{}),
+	}
+
+	if err := o.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// Reload reads the mapping from the store again, the cache is kept on error.
+func (o *Permissions) Reload(ctx context.Context) error {
+	rolePermissions, err := o.store.ListRolePermissions(ctx)
+	if err != nil {
+		return err
+	}
+
+	roles := make(map[string]map[string]struct{})
+	for _, rolePermission := range rolePermissions {
+		if _, ok := roles[rolePermission.Role]; !ok {
+			roles[rolePermission.Role] = make(map[string]struct{})
+		}
+		roles[rolePermission.Role][rolePermission.Permission] = struct{}{}
+	}
+
+	o.mutex.Lock()
+	o.roles = roles
+	o.mutex.Unlock()
+
+	return nil
+}
+
+// HasPermission return true if the role is granted the permission.
+func (o *Permissions) HasPermission(role, permission string) bool {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	_, ok := o.roles[role][permission]
+
+	return ok
+}
+
+// Roles return sorted names of the loaded roles.
+func (o *Permissions) Roles() []string {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	roles := make([]string, 0, len(o.roles))
+	for role := range o.roles {
+		roles = append(roles, role)
+	}
+
+	sort.Strings(roles)
+
+	return roles
+}
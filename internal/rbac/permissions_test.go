@@ -0,0 +1,79 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Dsmit05/metida/internal/models"
+)
+
+type storeMock struct {
+	rolePermissions []models.RolePermission
+	err             error
+}
+
+func (o *storeMock) ListRolePermissions(ctx context.Context) ([]models.RolePermission, error) {
+	return o.rolePermissions, o.err
+}
+
+func TestPermissions_HasPermission(t *testing.T) {
+	store := &storeMock{rolePermissions: []models.RolePermission{
+		{Role: "User", Permission: "content:read"},
+		{Role: "Admin", Permission: "content:read"},
+		{Role: "Admin", Permission: "blog:create"},
+	}}
+
+	permissions, err := NewPermissions(context.Background(), store)
+	if err != nil {
+		t.Fatalf("NewPermissions error = %v", err)
+	}
+
+	var tests = []struct {
+		name       string
+		role       string
+		permission string
+		want       bool
+	}{
+		{name: "Case-1: granted permission", role: "User", permission: "content:read", want: true},
+		{name: "Case-2: not granted permission", role: "User", permission: "blog:create", want: false},
+		{name: "Case-3: admin permission", role: "Admin", permission: "blog:create", want: true},
+		{name: "Case-4: unknown role", role: "Guest", permission: "content:read", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := permissions.HasPermission(tt.role, tt.permission); got != tt.want {
+				t.Errorf("HasPermission() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Case-5: cache is kept on reload error", func(t *testing.T) {
+		store.err = errors.New("db is down")
+		if err = permissions.Reload(context.Background()); err == nil {
+			t.Fatalf("Reload error = nil, want error")
+		}
+
+		if !permissions.HasPermission("Admin", "blog:create") {
+			t.Errorf("HasPermission() = false after failed reload")
+		}
+	})
+}
+
+func TestPermissions_Roles(t *testing.T) {
+	store := &storeMock{rolePermissions: []models.RolePermission{
+		{Role: "User", Permission: "content:read"},
+		{Role: "Admin", Permission: "content:read"},
+		{Role: "Admin", Permission: "blog:create"},
+	}}
+
+	permissions, err := NewPermissions(context.Background(), store)
+	if err != nil {
+		t.Fatalf("NewPermissions error = %v", err)
+	}
+
+	if got, want := permissions.Roles(), []string{"Admin", "User"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Roles() = %v, want %v", got, want)
+	}
+}
@@ -4,27 +4,50 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"time"
 
+	"github.com/Dsmit05/metida/internal/apperr"
+	"github.com/Dsmit05/metida/internal/consts"
 	"github.com/Dsmit05/metida/internal/logger"
 	"github.com/Dsmit05/metida/internal/models"
 	"github.com/Dsmit05/metida/internal/repositories/postgres"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 var (
-	errUserNotFound    = errors.New("User Not Found")
-	errUserIsExist     = errors.New("User already exists")
-	errBlogNotFound    = errors.New("Blog Not Found")
-	errBlogIsExist     = errors.New("Blog already exists")
-	errContentNotFound = errors.New("Content Not Found")
-	errContentIsExist  = errors.New("Content already exists")
-	errOther           = errors.New("pls, Try again")
+	errUserNotFound     = apperr.New(apperr.NotFound, "User Not Found")
+	errUserIsExist      = apperr.New(apperr.Conflict, "User already exists")
+	errRoleNotFound     = apperr.New(apperr.NotFound, "Role Not Found")
+	errSessionNotFound  = apperr.New(apperr.NotFound, "Session Not Found")
+	errTokenNotFound    = apperr.New(apperr.NotFound, "Token Not Found")
+	errBlogNotFound     = apperr.New(apperr.NotFound, "Blog Not Found")
+	errBlogIsExist      = apperr.New(apperr.Conflict, "Blog already exists")
+	errContentNotFound  = apperr.New(apperr.NotFound, "Content Not Found")
+	errContentIsExist   = apperr.New(apperr.Conflict, "Content already exists")
+	errCommentNotFound  = apperr.New(apperr.NotFound, "Comment Not Found")
+	errFileNotFound     = apperr.New(apperr.NotFound, "File Not Found")
+	errShareNotFound    = apperr.New(apperr.NotFound, "Share Not Found")
+	errLinkNotFound     = apperr.New(apperr.NotFound, "Link Not Found")
+	errRevisionNotFound = apperr.New(apperr.NotFound, "Revision Not Found")
+	errOther            = apperr.New(apperr.Internal, "pls, Try again")
 )
 
+// otherError unexpected error of the database, the cause is kept for logs.
+func otherError(err error) error {
+	return apperr.Wrap(apperr.Internal, errOther.Msg, err)
+}
+
 type DBConnectI interface {
 	GetConnectDB() string
+	GetDbMaxConns() int32
+	GetDbMinConns() int32
+	GetDbMaxConnLifetime() time.Duration
+	GetDbMaxConnIdleTime() time.Duration
+	GetDbHealthCheckPeriod() time.Duration
 }
 
 type metricI interface {
@@ -33,58 +56,97 @@ type metricI interface {
 
 // PostgresRepository repository for accessing Postgres database
 type PostgresRepository struct {
-	conn    *pgx.Conn
+	pool    *pgxpool.Pool
 	queries *postgres.Queries
 	metric  metricI
 }
 
-func NewPostgresRepository(url DBConnectI, metric metricI) (*PostgresRepository, error) {
-	conn, err := pgx.Connect(context.Background(), url.GetConnectDB())
+func NewPostgresRepository(ctx context.Context, cfg DBConnectI, metric metricI) (*PostgresRepository, error) {
+	poolConfig, err := newPoolConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := pgxpool.ConnectConfig(ctx, poolConfig)
 	if err != nil {
 		return nil, err
 	}
-	queries := postgres.New(conn)
+
+	if err = pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	queries := postgres.New(pool)
 
 	logger.Info("repositories.NewPostgresRepository()", "Init")
 	return &PostgresRepository{
-		conn:    conn,
+		pool:    pool,
 		queries: queries,
 		metric:  metric,
 	}, nil
 }
 
-func (o *PostgresRepository) Close() {
-	err := o.conn.Close(context.Background())
+// newPoolConfig builds pool settings, zero values in config leave the pgxpool defaults.
+func newPoolConfig(cfg DBConnectI) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.GetConnectDB())
 	if err != nil {
-		logger.Error("(o *PostgresRepository) Close() error:", err)
+		return nil, err
+	}
+
+	if maxConns := cfg.GetDbMaxConns(); maxConns > 0 {
+		poolConfig.MaxConns = maxConns
+	}
+
+	if minConns := cfg.GetDbMinConns(); minConns > 0 {
+		poolConfig.MinConns = minConns
 	}
+
+	if lifetime := cfg.GetDbMaxConnLifetime(); lifetime > 0 {
+		poolConfig.MaxConnLifetime = lifetime
+	}
+
+	if idleTime := cfg.GetDbMaxConnIdleTime(); idleTime > 0 {
+		poolConfig.MaxConnIdleTime = idleTime
+	}
+
+	if period := cfg.GetDbHealthCheckPeriod(); period > 0 {
+		poolConfig.HealthCheckPeriod = period
+	}
+
+	return poolConfig, nil
+}
+
+func (o *PostgresRepository) Close() {
+	o.pool.Close()
+	logger.Info("repositories.PostgresRepository.Close()", "pool closed")
 }
 
-func (o *PostgresRepository) CreateUser(name, password, email, role string) error {
+func (o *PostgresRepository) CreateUser(ctx context.Context, name, password, email, role string) (int32, error) {
 	inputData := postgres.CreateUserParams{
-		Name:     sql.NullString{name, true},
+		Name:     sql.NullString{String: name, Valid: true},
 		Password: password,
 		Email:    email,
 		Role:     role,
 	}
 
-	err := o.queries.CreateUser(context.Background(), inputData)
+	id, err := o.q(ctx).CreateUser(ctx, inputData)
 	val, ok := err.(*pgconn.PgError)
 
 	if ok && pgerrcode.IsIntegrityConstraintViolation(val.Code) {
-		return errUserIsExist
+		return 0, errUserIsExist
 	}
 
 	if err != nil {
 		logger.DatabaseError("queries.CreateUser", err, inputData)
-		return errOther
+		return 0, otherError(err)
 	}
 
-	return nil
+	return id, nil
 }
 
-func (o *PostgresRepository) ReadUser(email string) (*models.User, error) {
-	user, err := o.queries.ReadUser(context.Background(), email)
+func (o *PostgresRepository) ReadUser(ctx context.Context, email string) (*models.User, error) {
+	user, err := o.q(ctx).ReadUser(ctx, email)
 
 	if err != nil {
 		logger.DatabaseError("queries.ReadUser", err, email)
@@ -92,7 +154,7 @@ func (o *PostgresRepository) ReadUser(email string) (*models.User, error) {
 			return nil, errUserNotFound
 		}
 
-		return nil, errOther
+		return nil, otherError(err)
 	}
 
 	if user.IsDeleted.Bool {
@@ -106,77 +168,289 @@ func (o *PostgresRepository) ReadUser(email string) (*models.User, error) {
 		Email:     user.Email,
 		Role:      user.Role,
 		IsDeleted: user.IsDeleted.Bool,
+		Verified:  user.Verifay,
 	}
 
 	return userModel, nil
 }
 
-func (o *PostgresRepository) UpdateUser(email string, name, password, role string, isDeleted bool) error {
+func (o *PostgresRepository) UpdateUser(ctx context.Context, email string, name, password, role string, isDeleted bool) error {
 	inputData := postgres.UpdateUserParams{
 		Email:     email,
-		Name:      sql.NullString{name, true},
+		Name:      sql.NullString{String: name, Valid: true},
 		Password:  password,
 		Role:      role,
-		IsDeleted: sql.NullBool{isDeleted, true},
+		IsDeleted: sql.NullBool{Bool: isDeleted, Valid: true},
 	}
 
-	err := o.queries.UpdateUser(context.Background(), inputData)
+	err := o.q(ctx).UpdateUser(ctx, inputData)
 	if err != nil {
 		logger.DatabaseError("queries.UpdateUser", err, inputData)
-		return errOther
+		return otherError(err)
 	}
 
 	return nil
 }
 
-func (o *PostgresRepository) DeleteUser(email string) error {
-	err := o.queries.DeleteUser(context.Background(), email)
+func (o *PostgresRepository) DeleteUser(ctx context.Context, email string) error {
+	err := o.q(ctx).DeleteUser(ctx, email)
 	if err != nil {
 		logger.DatabaseError("queries.DeleteUser", err, email)
-		return errOther
+		return otherError(err)
+	}
+
+	return nil
+}
+
+// VerifyUser marks the user email as confirmed.
+func (o *PostgresRepository) VerifyUser(ctx context.Context, email string) error {
+	rows, err := o.q(ctx).VerifyUser(ctx, email)
+	if err != nil {
+		logger.DatabaseError("queries.VerifyUser", err, email)
+		return otherError(err)
+	}
+
+	if rows == 0 {
+		return errUserNotFound
+	}
+
+	return nil
+}
+
+// ReadUserByID return user by id, deleted users are returned too.
+func (o *PostgresRepository) ReadUserByID(ctx context.Context, id int32) (*models.User, error) {
+	user, err := o.q(ctx).ReadUserByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errUserNotFound
+		}
+
+		logger.DatabaseError("queries.ReadUserByID", err, id)
+		return nil, otherError(err)
+	}
+
+	userModel := &models.User{
+		ID:        user.ID,
+		Name:      user.Name.String,
+		Email:     user.Email,
+		Role:      user.Role,
+		IsDeleted: user.IsDeleted.Bool,
+		Verified:  user.Verifay,
+	}
+
+	return userModel, nil
+}
+
+// ListUsers return users which email or name contains search, passwords are not read.
+func (o *PostgresRepository) ListUsers(
+	ctx context.Context, search string, withDeleted bool, limit, offset int32) ([]models.User, error) {
+	inputData := postgres.ListUsersParams{
+		Search:      search,
+		WithDeleted: withDeleted,
+		LimitCount:  limit,
+		OffsetCount: offset,
+	}
+
+	users, err := o.q(ctx).ListUsers(ctx, inputData)
+	if err != nil {
+		logger.DatabaseError("queries.ListUsers", err, inputData)
+		return nil, otherError(err)
+	}
+
+	userModels := make([]models.User, 0, len(users))
+	for _, user := range users {
+		userModels = append(userModels, models.User{
+			ID:        user.ID,
+			Name:      user.Name.String,
+			Email:     user.Email,
+			Role:      user.Role,
+			IsDeleted: user.IsDeleted.Bool,
+			Verified:  user.Verifay,
+		})
+	}
+
+	return userModels, nil
+}
+
+// CountUsers return number of users found by ListUsers with the same filter.
+func (o *PostgresRepository) CountUsers(ctx context.Context, search string, withDeleted bool) (int64, error) {
+	inputData := postgres.CountUsersParams{
+		Search:      search,
+		WithDeleted: withDeleted,
+	}
+
+	count, err := o.q(ctx).CountUsers(ctx, inputData)
+	if err != nil {
+		logger.DatabaseError("queries.CountUsers", err, inputData)
+		return 0, otherError(err)
+	}
+
+	return count, nil
+}
+
+// UpdateUserRole set new role of the user and return user email.
+func (o *PostgresRepository) UpdateUserRole(ctx context.Context, id int32, role string) (string, error) {
+	inputData := postgres.UpdateUserRoleParams{
+		ID:   id,
+		Role: role,
+	}
+
+	email, err := o.q(ctx).UpdateUserRole(ctx, inputData)
+
+	val, ok := err.(*pgconn.PgError)
+	if ok && val.Code == pgerrcode.ForeignKeyViolation {
+		return "", errRoleNotFound
+	}
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", errUserNotFound
+		}
+
+		logger.DatabaseError("queries.UpdateUserRole", err, inputData)
+		return "", otherError(err)
+	}
+
+	return email, nil
+}
+
+// DeleteUserByID marks the user as deleted and return user email.
+func (o *PostgresRepository) DeleteUserByID(ctx context.Context, id int32) (string, error) {
+	email, err := o.q(ctx).DeleteUserByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", errUserNotFound
+		}
+
+		logger.DatabaseError("queries.DeleteUserByID", err, id)
+		return "", otherError(err)
+	}
+
+	return email, nil
+}
+
+// RestoreUser removes the deleted mark from the user.
+func (o *PostgresRepository) RestoreUser(ctx context.Context, id int32) error {
+	rows, err := o.q(ctx).RestoreUser(ctx, id)
+	if err != nil {
+		logger.DatabaseError("queries.RestoreUser", err, id)
+		return otherError(err)
+	}
+
+	if rows == 0 {
+		return errUserNotFound
+	}
+
+	return nil
+}
+
+// UpdateUserPassword set new password hash of the user.
+func (o *PostgresRepository) UpdateUserPassword(ctx context.Context, email string, password string) error {
+	inputData := postgres.UpdateUserPasswordParams{
+		Email:    email,
+		Password: password,
+	}
+
+	rows, err := o.q(ctx).UpdateUserPassword(ctx, inputData)
+	if err != nil {
+		logger.DatabaseError("queries.UpdateUserPassword", err, email)
+		return otherError(err)
+	}
+
+	if rows == 0 {
+		return errUserNotFound
+	}
+
+	return nil
+}
+
+// CreatePasswordResetToken save hash of the reset token.
+func (o *PostgresRepository) CreatePasswordResetToken(
+	ctx context.Context, email string, tokenHash string, expiresIn int64) error {
+	inputData := postgres.CreatePasswordResetTokenParams{
+		TokenHash: tokenHash,
+		UserEmail: email,
+		ExpiresIn: expiresIn,
+	}
+
+	if err := o.q(ctx).CreatePasswordResetToken(ctx, inputData); err != nil {
+		logger.DatabaseError("queries.CreatePasswordResetToken", err, email)
+		return otherError(err)
+	}
+
+	return nil
+}
+
+// UsePasswordResetToken marks the unexpired reset token as used and return its owner email,
+// the token can be used only once.
+func (o *PostgresRepository) UsePasswordResetToken(ctx context.Context, tokenHash string) (string, error) {
+	inputData := postgres.UsePasswordResetTokenParams{
+		TokenHash: tokenHash,
+		ExpiresIn: time.Now().Unix(),
+	}
+
+	email, err := o.q(ctx).UsePasswordResetToken(ctx, inputData)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", errTokenNotFound
+		}
+
+		logger.DatabaseError("queries.UsePasswordResetToken", err, nil)
+		return "", otherError(err)
+	}
+
+	return email, nil
+}
+
+// RevokePasswordResetTokens marks all unused reset tokens of the user as used.
+func (o *PostgresRepository) RevokePasswordResetTokens(ctx context.Context, email string) error {
+	if err := o.q(ctx).RevokePasswordResetTokens(ctx, email); err != nil {
+		logger.DatabaseError("queries.RevokePasswordResetTokens", err, email)
+		return otherError(err)
 	}
 
 	return nil
 }
 
-func (o *PostgresRepository) CreateSession(email string, refreshToken, userAgent, ip string, expiresIn int64) error {
+func (o *PostgresRepository) CreateSession(
+	ctx context.Context, email string, refreshToken, userAgent, ip string, expiresIn int64) (int32, error) {
 	inputData := postgres.CreateSessionParams{
-		UserEmail:    sql.NullString{email, true},
-		RefreshToken: sql.NullString{refreshToken, true},
+		UserEmail:    sql.NullString{String: email, Valid: true},
+		RefreshToken: sql.NullString{String: refreshToken, Valid: true},
 		AccessToken:  sql.NullString{Valid: false},
-		UserAgent:    sql.NullString{userAgent, true},
-		Ip:           sql.NullString{ip, true},
+		UserAgent:    sql.NullString{String: userAgent, Valid: true},
+		Ip:           sql.NullString{String: ip, Valid: true},
 		ExpiresIn:    expiresIn,
 	}
 
-	err := o.queries.CreateSession(context.Background(), inputData)
+	sessionID, err := o.q(ctx).CreateSession(ctx, inputData)
 
 	val, ok := err.(*pgconn.PgError)
 	if ok && pgerrcode.IsIntegrityConstraintViolation(val.Code) {
-		return errOther
+		return 0, otherError(err)
 	}
 
 	if err != nil {
 		logger.DatabaseError("queries.CreateSession", err, inputData)
-		return errOther
+		return 0, otherError(err)
 	}
 
-	return nil
+	return sessionID, nil
 }
 
-func (o *PostgresRepository) ReadSession(email string, userAgent, ip string) (*models.Session, error) {
+func (o *PostgresRepository) ReadSession(ctx context.Context, email string, userAgent, ip string) (*models.Session, error) {
 	inputData := postgres.ReadSessionParams{
-		UserEmail: sql.NullString{email, true},
-		UserAgent: sql.NullString{userAgent, true},
-		Ip:        sql.NullString{ip, true},
+		UserEmail: sql.NullString{String: email, Valid: true},
+		UserAgent: sql.NullString{String: userAgent, Valid: true},
+		Ip:        sql.NullString{String: ip, Valid: true},
 	}
 
-	session, err := o.queries.ReadSession(context.Background(), inputData)
+	session, err := o.q(ctx).ReadSession(ctx, inputData)
 
 	if err != nil {
 		logger.DatabaseError("queries.ReadSession", err, inputData)
 		if session.UserEmail.String == "" {
-			return nil, errOther
+			return nil, otherError(err)
 		}
 
 		return nil, err
@@ -186,63 +460,105 @@ func (o *PostgresRepository) ReadSession(email string, userAgent, ip string) (*m
 		return nil, errOther
 	}
 
-	userModel := &models.Session{
-		ID:           session.ID,
-		UserEmail:    session.UserEmail.String,
-		RefreshToken: session.RefreshToken.String,
-		AccessToken:  session.AccessToken.String,
-		UserAgent:    session.UserAgent.String,
-		IP:           session.Ip.String,
-		ExpiresIn:    session.ExpiresIn,
-		CreatedAt:    session.CreatedAt,
-	}
+	userModel := newSessionModel(session)
 
-	return userModel, nil
+	return &userModel, nil
 }
 
 func (o *PostgresRepository) UpdateSession(
-	email string, refreshToken string, newRefreshToken string, expiresIn int64) error {
+	ctx context.Context, email string, refreshToken string, newRefreshToken string, expiresIn int64) error {
 	inputData := postgres.UpdateSessionParams{
-		UserEmail:      sql.NullString{email, true},
-		RefreshToken:   sql.NullString{refreshToken, true},
-		RefreshToken_2: sql.NullString{newRefreshToken, true},
+		UserEmail:      sql.NullString{String: email, Valid: true},
+		RefreshToken:   sql.NullString{String: refreshToken, Valid: true},
+		RefreshToken_2: sql.NullString{String: newRefreshToken, Valid: true},
 		ExpiresIn:      expiresIn,
 	}
 
-	err := o.queries.UpdateSession(context.Background(), inputData)
+	err := o.q(ctx).UpdateSession(ctx, inputData)
 	if err != nil {
 		logger.DatabaseError("queries.UpdateSession", err, inputData)
-		return errOther
+		return otherError(err)
 	}
 
 	return nil
 }
 
 func (o *PostgresRepository) UpdateSessionTokenOnly(
-	refreshToken string, newRefreshToken string, expiresIn int64) error {
+	ctx context.Context, refreshToken string, newRefreshToken string, expiresIn int64) error {
 
 	inputData := postgres.UpdateSessionTokenOnlyParams{
-		RefreshToken:   sql.NullString{refreshToken, true},
-		RefreshToken_2: sql.NullString{newRefreshToken, true},
+		RefreshToken:   sql.NullString{String: refreshToken, Valid: true},
+		RefreshToken_2: sql.NullString{String: newRefreshToken, Valid: true},
 		ExpiresIn:      expiresIn,
 	}
 
-	err := o.queries.UpdateSessionTokenOnly(context.Background(), inputData)
+	rows, err := o.q(ctx).UpdateSessionTokenOnly(ctx, inputData)
 	if err != nil {
 		logger.DatabaseError("queries.UpdateSessionTokenOnly", err, inputData)
-		return errOther
+		return otherError(err)
+	}
+
+	if rows == 0 {
+		return errSessionNotFound
 	}
 
 	return nil
 }
 
-func (o *PostgresRepository) ReadEmailRoleWithRefreshToken(refreshToken string) (*models.UserEmailRole, error) {
+// RotateRefreshToken replace session refresh token in one transaction, the old one is kept as retired in the token family.
+func (o *PostgresRepository) RotateRefreshToken(ctx context.Context,
+	sessionID int32, email string, refreshToken string, newRefreshToken string, expiresIn int64) error {
+	// the old token must not stay valid without being retired, otherwise its reuse is not detected
+	return o.WithinTx(ctx, func(ctx context.Context) error {
+		if err := o.UpdateSessionTokenOnly(ctx, refreshToken, newRefreshToken, expiresIn); err != nil {
+			return err
+		}
+
+		inputData := postgres.CreateRetiredRefreshTokenParams{
+			Token:     refreshToken,
+			SessionID: sessionID,
+			UserEmail: sql.NullString{String: email, Valid: true},
+		}
+
+		if err := o.q(ctx).CreateRetiredRefreshToken(ctx, inputData); err != nil {
+			logger.DatabaseError("queries.CreateRetiredRefreshToken", err, inputData)
+			return otherError(err)
+		}
+
+		return nil
+	})
+}
+
+// ReadRetiredRefreshToken return refresh token that was already exchanged.
+func (o *PostgresRepository) ReadRetiredRefreshToken(
+	ctx context.Context, refreshToken string) (*models.RetiredRefreshToken, error) {
+	token, err := o.q(ctx).ReadRetiredRefreshToken(ctx, refreshToken)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errTokenNotFound
+		}
+
+		logger.DatabaseError("queries.ReadRetiredRefreshToken", err, refreshToken)
+		return nil, otherError(err)
+	}
+
+	tokenModel := &models.RetiredRefreshToken{
+		Token:     token.Token,
+		SessionID: token.SessionID,
+		UserEmail: token.UserEmail.String,
+		RetiredAt: token.RetiredAt,
+	}
+
+	return tokenModel, nil
+}
+
+func (o *PostgresRepository) ReadEmailRoleWithRefreshToken(ctx context.Context, refreshToken string) (*models.UserEmailRole, error) {
 	inputData := sql.NullString{
 		String: refreshToken,
 		Valid:  true,
 	}
 
-	emailAndRole, err := o.queries.ReadEmailRoleFromSessions(context.Background(), inputData)
+	emailAndRole, err := o.q(ctx).ReadEmailRoleFromSessions(ctx, inputData)
 
 	if err != nil {
 		logger.DatabaseError("queries.ReadEmailRoleFromSessions", err, inputData)
@@ -250,122 +566,1527 @@ func (o *PostgresRepository) ReadEmailRoleWithRefreshToken(refreshToken string)
 			return nil, errUserNotFound
 		}
 
-		return nil, errOther
+		return nil, otherError(err)
 	}
 
 	userModel := &models.UserEmailRole{
+		UserID:    emailAndRole.UserID,
 		Email:     emailAndRole.Email,
 		Role:      emailAndRole.Role,
 		ExpiresIn: emailAndRole.ExpiresIn,
+		SessionID: emailAndRole.SessionID,
+
+		AccessTokenID:   emailAndRole.AccessToken.String,
+		AccessExpiresIn: emailAndRole.AccessExpiresIn,
 	}
 
 	return userModel, nil
 }
 
-func (o *PostgresRepository) DeleteSession(email string, ip, userAgent string) error {
+func (o *PostgresRepository) DeleteSession(ctx context.Context, email string, ip, userAgent string) error {
 	inputData := postgres.DeleteSessionParams{
-		UserAgent: sql.NullString{email, true},
-		Ip:        sql.NullString{ip, true},
-		UserEmail: sql.NullString{userAgent, true},
+		UserAgent: sql.NullString{String: userAgent, Valid: true},
+		Ip:        sql.NullString{String: ip, Valid: true},
+		UserEmail: sql.NullString{String: email, Valid: true},
 	}
 
-	err := o.queries.DeleteSession(context.Background(), inputData)
+	err := o.q(ctx).DeleteSession(ctx, inputData)
 	if err != nil {
 		logger.DatabaseError("queries.DeleteSession", err, inputData)
-		return errOther
+		return otherError(err)
 	}
 
 	return nil
 }
 
-func (o *PostgresRepository) CreatContent(email string, name, description string) error {
-	inputData := postgres.CreateContentParams{
-		UserEmail:   sql.NullString{email, true},
-		Name:        sql.NullString{name, true},
-		Description: sql.NullString{description, true},
+// ListSessions return not expired user sessions, newest first.
+func (o *PostgresRepository) ListSessions(ctx context.Context, email string) ([]models.Session, error) {
+	inputData := postgres.ListSessionsParams{
+		UserEmail: sql.NullString{String: email, Valid: true},
+		ExpiresIn: time.Now().Unix(),
 	}
 
-	err := o.queries.CreateContent(context.Background(), inputData)
+	sessions, err := o.q(ctx).ListSessions(ctx, inputData)
+	if err != nil {
+		logger.DatabaseError("queries.ListSessions", err, inputData)
+		return nil, otherError(err)
+	}
 
-	val, ok := err.(*pgconn.PgError)
-	if ok && pgerrcode.IsIntegrityConstraintViolation(val.Code) {
-		return errContentIsExist
+	return newSessionModels(sessions), nil
+}
+
+// UpdateSessionAccessToken save id and expiration of the last access token issued for the session.
+func (o *PostgresRepository) UpdateSessionAccessToken(
+	ctx context.Context, sessionID int32, tokenID string, expiresIn int64) error {
+	inputData := postgres.UpdateSessionAccessTokenParams{
+		ID:              sessionID,
+		AccessToken:     sql.NullString{String: tokenID, Valid: true},
+		AccessExpiresIn: expiresIn,
 	}
 
+	err := o.q(ctx).UpdateSessionAccessToken(ctx, inputData)
 	if err != nil {
-		logger.DatabaseError("queries.CreateContent", err, inputData)
-		return errOther
+		logger.DatabaseError("queries.UpdateSessionAccessToken", err, inputData)
+		return otherError(err)
 	}
 
 	return nil
 }
 
-func (o *PostgresRepository) ReadContent(email string, id int32) (*models.Content, error) {
+// DeleteSessionByID remove user session and return it, sessions of other users are not affected.
+func (o *PostgresRepository) DeleteSessionByID(ctx context.Context, email string, id int32) (*models.Session, error) {
+	inputData := postgres.DeleteSessionByIDParams{
+		ID:        id,
+		UserEmail: sql.NullString{String: email, Valid: true},
+	}
+
+	session, err := o.q(ctx).DeleteSessionByID(ctx, inputData)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errSessionNotFound
+		}
+
+		logger.DatabaseError("queries.DeleteSessionByID", err, inputData)
+		return nil, otherError(err)
+	}
+
+	sessionModel := newSessionModel(session)
+
+	return &sessionModel, nil
+}
+
+// DeleteUserSessions remove all user sessions and return removed sessions.
+func (o *PostgresRepository) DeleteUserSessions(ctx context.Context, email string) ([]models.Session, error) {
+	inputData := sql.NullString{String: email, Valid: true}
+
+	sessions, err := o.q(ctx).DeleteUserSessions(ctx, inputData)
+	if err != nil {
+		logger.DatabaseError("queries.DeleteUserSessions", err, inputData)
+		return nil, otherError(err)
+	}
+
+	return newSessionModels(sessions), nil
+}
+
+// DeleteOtherSessions remove all user sessions except the current one and return removed sessions.
+func (o *PostgresRepository) DeleteOtherSessions(
+	ctx context.Context, email string, currentID int32) ([]models.Session, error) {
+	inputData := postgres.DeleteOtherSessionsParams{
+		UserEmail: sql.NullString{String: email, Valid: true},
+		ID:        currentID,
+	}
+
+	sessions, err := o.q(ctx).DeleteOtherSessions(ctx, inputData)
+	if err != nil {
+		logger.DatabaseError("queries.DeleteOtherSessions", err, inputData)
+		return nil, otherError(err)
+	}
+
+	return newSessionModels(sessions), nil
+}
+
+// newSessionModel convert database session to models.Session.
+func newSessionModel(session postgres.Session) models.Session {
+	return models.Session{
+		ID:              session.ID,
+		UserEmail:       session.UserEmail.String,
+		RefreshToken:    session.RefreshToken.String,
+		AccessToken:     session.AccessToken.String,
+		UserAgent:       session.UserAgent.String,
+		IP:              session.Ip.String,
+		ExpiresIn:       session.ExpiresIn,
+		CreatedAt:       session.CreatedAt,
+		AccessExpiresIn: session.AccessExpiresIn,
+	}
+}
+
+func newSessionModels(sessions []postgres.Session) []models.Session {
+	sessionModels := make([]models.Session, 0, len(sessions))
+	for _, session := range sessions {
+		sessionModels = append(sessionModels, newSessionModel(session))
+	}
+
+	return sessionModels
+}
+
+// CreatContent adds the user content and its first revision in one transaction.
+func (o *PostgresRepository) CreatContent(ctx context.Context, email string, name, description string) (int32, error) {
+	inputData := postgres.CreateContentParams{
+		UserEmail:   sql.NullString{String: email, Valid: true},
+		Name:        sql.NullString{String: name, Valid: true},
+		Description: sql.NullString{String: description, Valid: true},
+	}
+
+	var id int32
+
+	err := o.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		id, err = o.q(ctx).CreateContent(ctx, inputData)
+
+		val, ok := err.(*pgconn.PgError)
+		if ok && pgerrcode.IsIntegrityConstraintViolation(val.Code) {
+			return errContentIsExist
+		}
+
+		if err != nil {
+			logger.DatabaseError("queries.CreateContent", err, inputData)
+			return otherError(err)
+		}
+
+		revisionData := postgres.CreateContentRevisionParams{
+			ContentID:   id,
+			AuthorEmail: inputData.UserEmail,
+			Name:        inputData.Name,
+			Description: inputData.Description,
+			Summary:     revisionCreated,
+		}
+
+		if _, err = o.q(ctx).CreateContentRevision(ctx, revisionData); err != nil {
+			logger.DatabaseError("queries.CreateContentRevision", err, id)
+			return otherError(err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// ReadContent return content owned by the user or shared with the user, Access shows rights of the user.
+func (o *PostgresRepository) ReadContent(ctx context.Context, email string, id int32) (*models.Content, error) {
 	inputData := postgres.ReadContentParams{
-		UserEmail: sql.NullString{email, true},
+		UserEmail: sql.NullString{String: email, Valid: true},
 		ID:        id,
 	}
 
-	content, err := o.queries.ReadContent(context.Background(), inputData)
+	content, err := o.q(ctx).ReadContent(ctx, inputData)
 	if err != nil {
 		logger.DatabaseError("queries.ReadContent", err, inputData)
 		return nil, errContentNotFound
 	}
 
-	contentModel := &models.Content{
+	contentModel := newContentModel(contentRow{
 		ID:          content.ID,
-		UserEmail:   content.UserEmail.String,
-		Name:        content.Name.String,
-		Description: content.Description.String,
-	}
-
-	return contentModel, nil
+		UserEmail:   content.UserEmail,
+		Name:        content.Name,
+		Description: content.Description,
+		CreatedAt:   content.CreatedAt,
+		UpdatedAt:   content.UpdatedAt,
+	})
+	contentModel.Access = content.Access
+
+	return &contentModel, nil
 }
 
-func (o *PostgresRepository) CreatBlog(name, description string) error {
-	inputData := postgres.CreateBlogParams{
-		Name:        sql.NullString{name, true},
-		Description: sql.NullString{description, true},
+// ListContent return page of the user content, sorted and filtered by filter.
+func (o *PostgresRepository) ListContent(
+	ctx context.Context, email string, filter models.ContentFilter) ([]models.Content, error) {
+	inputData := postgres.ListContentParams{
+		UserEmail:  sql.NullString{String: email, Valid: true},
+		NameFilter: escapeLike(filter.Name),
+		SortBy:     filter.SortBy,
+		SortDesc:   filter.SortDesc,
+		LimitCount: filter.Limit,
 	}
 
-	err := o.queries.CreateBlog(context.Background(), inputData)
+	if filter.Cursor != nil {
+		inputData.CursorID = filter.Cursor.ID
+		inputData.CursorName = filter.Cursor.Name
+		inputData.CursorTime = filter.Cursor.Time
+	}
 
-	val, ok := err.(*pgconn.PgError)
-	if ok && pgerrcode.IsIntegrityConstraintViolation(val.Code) {
-		return errBlogIsExist
+	contents, err := o.q(ctx).ListContent(ctx, inputData)
+	if err != nil {
+		logger.DatabaseError("queries.ListContent", err, inputData)
+		return nil, otherError(err)
+	}
+
+	contentModels := make([]models.Content, 0, len(contents))
+	for _, content := range contents {
+		contentModels = append(contentModels, newContentModel(contentRow(content)))
 	}
 
+	return contentModels, nil
+}
+
+// UpdateContent replaces name and description of the content owned by the user or shared for edit,
+// the revision is written in the same transaction.
+func (o *PostgresRepository) UpdateContent(
+	ctx context.Context, email string, id int32, name, description string) (*models.Content, error) {
+	var contentModel *models.Content
+
+	err := o.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		contentModel, err = o.updateContent(ctx, email, id, name, description, "")
+
+		return err
+	})
 	if err != nil {
-		logger.DatabaseError("queries.CreateBlog", err, inputData)
-		return errOther
+		return nil, err
 	}
 
-	return nil
+	return contentModel, nil
 }
 
-func (o *PostgresRepository) ReadBlog(id int32) (*models.Blog, error) {
-	blog, err := o.queries.ReadBlog(context.Background(), id)
+// RestoreContentRevision replaces the content by the snapshot of its revision, the restore is a new revision.
+func (o *PostgresRepository) RestoreContentRevision(
+	ctx context.Context, email string, id, revision int32) (*models.Content, error) {
+	var contentModel *models.Content
+
+	err := o.WithinTx(ctx, func(ctx context.Context) error {
+		inputData := postgres.ReadContentRevisionParams{
+			ContentID: id,
+			Revision:  revision,
+		}
+
+		revisionRow, err := o.q(ctx).ReadContentRevision(ctx, inputData)
+		if err != nil {
+			return revisionError("queries.ReadContentRevision", err, inputData)
+		}
+
+		contentModel, err = o.updateContent(ctx, email, id,
+			revisionRow.Name.String, revisionRow.Description.String, fmt.Sprintf(revisionRestored, revision))
+
+		return err
+	})
 	if err != nil {
-		logger.DatabaseError("queries.CreateBlog", err, id)
+		return nil, err
+	}
 
-		// Todo: данная метрика используется пока только тут
-		o.metric.IncDbError()
-		if blog.Name.String == "" {
-			return nil, errBlogNotFound
+	return contentModel, nil
+}
+
+// updateContent changes the content and writes its revision, ctx must have the transaction of WithinTx.
+func (o *PostgresRepository) updateContent(ctx context.Context,
+	email string, id int32, name, description, summaryPrefix string) (*models.Content, error) {
+	old, err := o.q(ctx).ReadContentForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errContentNotFound
 		}
 
-		return nil, errOther
+		logger.DatabaseError("queries.ReadContentForUpdate", err, id)
+		return nil, otherError(err)
 	}
 
-	if !blog.Description.Valid {
-		return nil, errOther
+	inputData := postgres.UpdateContentParams{
+		UserEmail:   sql.NullString{String: email, Valid: true},
+		ID:          id,
+		Name:        sql.NullString{String: name, Valid: true},
+		Description: sql.NullString{String: description, Valid: true},
 	}
 
-	blogModel := &models.Blog{
-		ID:          blog.ID,
-		Name:        blog.Name.String,
-		Description: blog.Description.String,
+	content, err := o.q(ctx).UpdateContent(ctx, inputData)
+
+	val, ok := err.(*pgconn.PgError)
+	if ok && pgerrcode.IsIntegrityConstraintViolation(val.Code) {
+		return nil, errContentIsExist
 	}
 
-	return blogModel, nil
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errContentNotFound
+		}
+
+		logger.DatabaseError("queries.UpdateContent", err, inputData)
+		return nil, otherError(err)
+	}
+
+	revisionData := postgres.CreateContentRevisionParams{
+		ContentID:   id,
+		AuthorEmail: inputData.UserEmail,
+		Name:        inputData.Name,
+		Description: inputData.Description,
+		Summary:     summaryPrefix + revisionSummary(old.Name.String, old.Description.String, name, description),
+	}
+
+	if _, err = o.q(ctx).CreateContentRevision(ctx, revisionData); err != nil {
+		logger.DatabaseError("queries.CreateContentRevision", err, id)
+		return nil, otherError(err)
+	}
+
+	contentModel := newContentModel(contentRow(content))
+	contentModel.Access = consts.ContentAccessEdit
+
+	if contentModel.UserEmail == email {
+		contentModel.Access = consts.ContentAccessOwner
+	}
+
+	return &contentModel, nil
+}
+
+func (o *PostgresRepository) ReadContentRevision(
+	ctx context.Context, contentID, revision int32) (*models.ContentRevision, error) {
+	inputData := postgres.ReadContentRevisionParams{
+		ContentID: contentID,
+		Revision:  revision,
+	}
+
+	revisionRow, err := o.q(ctx).ReadContentRevision(ctx, inputData)
+	if err != nil {
+		return nil, revisionError("queries.ReadContentRevision", err, inputData)
+	}
+
+	revisionModel := newContentRevisionModel(revisionRow)
+
+	return &revisionModel, nil
+}
+
+// ListContentRevisions return page of revisions of the content, the latest first.
+func (o *PostgresRepository) ListContentRevisions(
+	ctx context.Context, contentID, limit, offset int32) ([]models.ContentRevision, error) {
+	inputData := postgres.ListContentRevisionsParams{
+		ContentID:   contentID,
+		LimitCount:  limit,
+		OffsetCount: offset,
+	}
+
+	revisions, err := o.q(ctx).ListContentRevisions(ctx, inputData)
+	if err != nil {
+		logger.DatabaseError("queries.ListContentRevisions", err, inputData)
+		return nil, otherError(err)
+	}
+
+	revisionModels := make([]models.ContentRevision, 0, len(revisions))
+	for _, revision := range revisions {
+		revisionModels = append(revisionModels, newContentRevisionModel(revision))
+	}
+
+	return revisionModels, nil
+}
+
+func (o *PostgresRepository) CountContentRevisions(ctx context.Context, contentID int32) (int64, error) {
+	count, err := o.q(ctx).CountContentRevisions(ctx, contentID)
+	if err != nil {
+		logger.DatabaseError("queries.CountContentRevisions", err, contentID)
+		return 0, otherError(err)
+	}
+
+	return count, nil
+}
+
+// revisionError converts error of the revision queries.
+func revisionError(query string, err error, inputData interface{}) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errRevisionNotFound
+	}
+
+	logger.DatabaseError(query, err, inputData)
+
+	return otherError(err)
+}
+
+func newContentRevisionModel(revision postgres.ContentRevision) models.ContentRevision {
+	return models.ContentRevision{
+		ID:          revision.ID,
+		ContentID:   revision.ContentID,
+		Revision:    revision.Revision,
+		AuthorEmail: revision.AuthorEmail.String,
+		Name:        revision.Name.String,
+		Description: revision.Description.String,
+		Summary:     revision.Summary,
+		CreatedAt:   revision.CreatedAt,
+	}
+}
+
+// DeleteContent removes the user content.
+func (o *PostgresRepository) DeleteContent(ctx context.Context, email string, id int32) error {
+	inputData := postgres.DeleteContentParams{
+		UserEmail: sql.NullString{String: email, Valid: true},
+		ID:        id,
+	}
+
+	rows, err := o.q(ctx).DeleteContent(ctx, inputData)
+	if err != nil {
+		logger.DatabaseError("queries.DeleteContent", err, inputData)
+		return otherError(err)
+	}
+
+	if rows == 0 {
+		return errContentNotFound
+	}
+
+	return nil
+}
+
+// SearchContent return page of the user content found by the full-text query, most relevant first.
+func (o *PostgresRepository) SearchContent(
+	ctx context.Context, email, query string, limit, offset int32) ([]models.ContentSearchResult, error) {
+	inputData := postgres.SearchContentParams{
+		Query:       query,
+		UserEmail:   sql.NullString{String: email, Valid: true},
+		LimitCount:  limit,
+		OffsetCount: offset,
+	}
+
+	contents, err := o.q(ctx).SearchContent(ctx, inputData)
+	if err != nil {
+		logger.DatabaseError("queries.SearchContent", err, inputData)
+		return nil, otherError(err)
+	}
+
+	results := make([]models.ContentSearchResult, 0, len(contents))
+	for _, content := range contents {
+		results = append(results, models.ContentSearchResult{
+			Content: newContentModel(contentRow{
+				ID:          content.ID,
+				UserEmail:   content.UserEmail,
+				Name:        content.Name,
+				Description: content.Description,
+				CreatedAt:   content.CreatedAt,
+				UpdatedAt:   content.UpdatedAt,
+			}),
+			Rank:    content.Rank,
+			Snippet: highlightSnippet(content.Snippet),
+		})
+	}
+
+	return results, nil
+}
+
+// CountSearchContent return number of the user content found by SearchContent.
+func (o *PostgresRepository) CountSearchContent(ctx context.Context, email, query string) (int64, error) {
+	inputData := postgres.CountSearchContentParams{
+		UserEmail: sql.NullString{String: email, Valid: true},
+		Query:     query,
+	}
+
+	count, err := o.q(ctx).CountSearchContent(ctx, inputData)
+	if err != nil {
+		logger.DatabaseError("queries.CountSearchContent", err, inputData)
+		return 0, otherError(err)
+	}
+
+	return count, nil
+}
+
+// contentRow content columns without search vector, rows of all content queries are converted into it.
+type contentRow = postgres.ListContentRow
+
+func newContentModel(content contentRow) models.Content {
+	return models.Content{
+		ID:          content.ID,
+		UserEmail:   content.UserEmail.String,
+		Name:        content.Name.String,
+		Description: content.Description.String,
+		CreatedAt:   content.CreatedAt,
+		UpdatedAt:   content.UpdatedAt,
+	}
+}
+
+// ShareContent grants the user read or edit permission to the content, the permission is replaced if exists.
+func (o *PostgresRepository) ShareContent(
+	ctx context.Context, contentID int32, email, permission string) (*models.ContentShare, error) {
+	inputData := postgres.UpsertContentShareParams{
+		ContentID:  contentID,
+		UserEmail:  email,
+		Permission: permission,
+	}
+
+	share, err := o.q(ctx).UpsertContentShare(ctx, inputData)
+
+	val, ok := err.(*pgconn.PgError)
+	if ok && val.Code == pgerrcode.ForeignKeyViolation {
+		return nil, errUserNotFound
+	}
+
+	if err != nil {
+		logger.DatabaseError("queries.UpsertContentShare", err, inputData)
+		return nil, otherError(err)
+	}
+
+	shareModel := newContentShareModel(share)
+
+	return &shareModel, nil
+}
+
+// UnshareContent revokes access of the user to the content.
+func (o *PostgresRepository) UnshareContent(ctx context.Context, contentID int32, email string) error {
+	inputData := postgres.DeleteContentShareParams{
+		ContentID: contentID,
+		UserEmail: email,
+	}
+
+	rows, err := o.q(ctx).DeleteContentShare(ctx, inputData)
+	if err != nil {
+		logger.DatabaseError("queries.DeleteContentShare", err, inputData)
+		return otherError(err)
+	}
+
+	if rows == 0 {
+		return errShareNotFound
+	}
+
+	return nil
+}
+
+// ListContentShares return users having access to the content.
+func (o *PostgresRepository) ListContentShares(ctx context.Context, contentID int32) ([]models.ContentShare, error) {
+	shares, err := o.q(ctx).ListContentShares(ctx, contentID)
+	if err != nil {
+		logger.DatabaseError("queries.ListContentShares", err, contentID)
+		return nil, otherError(err)
+	}
+
+	shareModels := make([]models.ContentShare, 0, len(shares))
+	for _, share := range shares {
+		shareModels = append(shareModels, newContentShareModel(share))
+	}
+
+	return shareModels, nil
+}
+
+// ListSharedContent return page of content shared with the user, recently shared first.
+func (o *PostgresRepository) ListSharedContent(
+	ctx context.Context, email string, limit, offset int32) ([]models.Content, error) {
+	inputData := postgres.ListSharedContentParams{
+		UserEmail:   email,
+		LimitCount:  limit,
+		OffsetCount: offset,
+	}
+
+	contents, err := o.q(ctx).ListSharedContent(ctx, inputData)
+	if err != nil {
+		logger.DatabaseError("queries.ListSharedContent", err, inputData)
+		return nil, otherError(err)
+	}
+
+	contentModels := make([]models.Content, 0, len(contents))
+	for _, content := range contents {
+		contentModel := newContentModel(contentRow{
+			ID:          content.ID,
+			UserEmail:   content.UserEmail,
+			Name:        content.Name,
+			Description: content.Description,
+			CreatedAt:   content.CreatedAt,
+			UpdatedAt:   content.UpdatedAt,
+		})
+		contentModel.Access = content.Permission
+
+		contentModels = append(contentModels, contentModel)
+	}
+
+	return contentModels, nil
+}
+
+// CountSharedContent return number of content shared with the user.
+func (o *PostgresRepository) CountSharedContent(ctx context.Context, email string) (int64, error) {
+	count, err := o.q(ctx).CountSharedContent(ctx, email)
+	if err != nil {
+		logger.DatabaseError("queries.CountSharedContent", err, email)
+		return 0, otherError(err)
+	}
+
+	return count, nil
+}
+
+// CreateContentLink saves hash of the token of the public link to the content.
+func (o *PostgresRepository) CreateContentLink(
+	ctx context.Context, contentID int32, tokenHash string, expiresIn int64) (*models.ContentLink, error) {
+	inputData := postgres.CreateContentLinkParams{
+		ContentID: contentID,
+		TokenHash: tokenHash,
+		ExpiresIn: expiresIn,
+	}
+
+	link, err := o.q(ctx).CreateContentLink(ctx, inputData)
+	if err != nil {
+		logger.DatabaseError("queries.CreateContentLink", err, contentID)
+		return nil, otherError(err)
+	}
+
+	linkModel := newContentLinkModel(link)
+
+	return &linkModel, nil
+}
+
+// ListContentLinks return unexpired public links to the content.
+func (o *PostgresRepository) ListContentLinks(ctx context.Context, contentID int32) ([]models.ContentLink, error) {
+	inputData := postgres.ListContentLinksParams{
+		ContentID: contentID,
+		ExpiresIn: time.Now().Unix(),
+	}
+
+	links, err := o.q(ctx).ListContentLinks(ctx, inputData)
+	if err != nil {
+		logger.DatabaseError("queries.ListContentLinks", err, inputData)
+		return nil, otherError(err)
+	}
+
+	linkModels := make([]models.ContentLink, 0, len(links))
+	for _, link := range links {
+		linkModels = append(linkModels, newContentLinkModel(link))
+	}
+
+	return linkModels, nil
+}
+
+// DeleteContentLink revokes the public link to the content.
+func (o *PostgresRepository) DeleteContentLink(ctx context.Context, contentID, id int32) error {
+	inputData := postgres.DeleteContentLinkParams{
+		ID:        id,
+		ContentID: contentID,
+	}
+
+	rows, err := o.q(ctx).DeleteContentLink(ctx, inputData)
+	if err != nil {
+		logger.DatabaseError("queries.DeleteContentLink", err, inputData)
+		return otherError(err)
+	}
+
+	if rows == 0 {
+		return errLinkNotFound
+	}
+
+	return nil
+}
+
+// ReadContentByLink return content by hash of the token of the public link and expiration time of the link.
+func (o *PostgresRepository) ReadContentByLink(ctx context.Context, tokenHash string) (*models.Content, int64, error) {
+	content, err := o.q(ctx).ReadContentByLink(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, 0, errLinkNotFound
+		}
+
+		logger.DatabaseError("queries.ReadContentByLink", err, nil)
+		return nil, 0, otherError(err)
+	}
+
+	contentModel := newContentModel(contentRow{
+		ID:          content.ID,
+		UserEmail:   content.UserEmail,
+		Name:        content.Name,
+		Description: content.Description,
+		CreatedAt:   content.CreatedAt,
+		UpdatedAt:   content.UpdatedAt,
+	})
+	contentModel.Access = consts.ContentAccessRead
+
+	return &contentModel, content.ExpiresIn, nil
+}
+
+func newContentShareModel(share postgres.ContentShare) models.ContentShare {
+	return models.ContentShare{
+		ContentID:  share.ContentID,
+		UserEmail:  share.UserEmail,
+		Permission: share.Permission,
+		CreatedAt:  share.CreatedAt,
+	}
+}
+
+func newContentLinkModel(link postgres.ContentLink) models.ContentLink {
+	return models.ContentLink{
+		ID:        link.ID,
+		ContentID: link.ContentID,
+		ExpiresIn: link.ExpiresIn,
+		CreatedAt: link.CreatedAt,
+	}
+}
+
+// CreateAttachment saves metadata of the file uploaded to the content.
+func (o *PostgresRepository) CreateAttachment(ctx context.Context, contentID int32, email string,
+	fileName, contentType string, size int64, storageKey string) (*models.Attachment, error) {
+	inputData := postgres.CreateAttachmentParams{
+		ContentID:   contentID,
+		UserEmail:   sql.NullString{String: email, Valid: true},
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  storageKey,
+	}
+
+	attachment, err := o.q(ctx).CreateAttachment(ctx, inputData)
+
+	val, ok := err.(*pgconn.PgError)
+	if ok && val.Code == pgerrcode.ForeignKeyViolation {
+		return nil, errContentNotFound
+	}
+
+	if err != nil {
+		logger.DatabaseError("queries.CreateAttachment", err, inputData)
+		return nil, otherError(err)
+	}
+
+	attachmentModel := newAttachmentModel(attachment)
+
+	return &attachmentModel, nil
+}
+
+func (o *PostgresRepository) ReadAttachment(ctx context.Context, contentID, id int32) (*models.Attachment, error) {
+	inputData := postgres.ReadAttachmentParams{
+		ID:        id,
+		ContentID: contentID,
+	}
+
+	attachment, err := o.q(ctx).ReadAttachment(ctx, inputData)
+	if err != nil {
+		return nil, attachmentError("queries.ReadAttachment", err, inputData)
+	}
+
+	attachmentModel := newAttachmentModel(attachment)
+
+	return &attachmentModel, nil
+}
+
+// ListAttachments return files of the content in order of upload.
+func (o *PostgresRepository) ListAttachments(ctx context.Context, contentID int32) ([]models.Attachment, error) {
+	attachments, err := o.q(ctx).ListAttachments(ctx, contentID)
+	if err != nil {
+		logger.DatabaseError("queries.ListAttachments", err, contentID)
+		return nil, otherError(err)
+	}
+
+	attachmentModels := make([]models.Attachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		attachmentModels = append(attachmentModels, newAttachmentModel(attachment))
+	}
+
+	return attachmentModels, nil
+}
+
+// DeleteAttachment removes metadata of the file and return its storage key.
+func (o *PostgresRepository) DeleteAttachment(ctx context.Context, contentID, id int32) (string, error) {
+	inputData := postgres.DeleteAttachmentParams{
+		ID:        id,
+		ContentID: contentID,
+	}
+
+	storageKey, err := o.q(ctx).DeleteAttachment(ctx, inputData)
+	if err != nil {
+		return "", attachmentError("queries.DeleteAttachment", err, inputData)
+	}
+
+	return storageKey, nil
+}
+
+// ListAttachmentKeys return storage keys of all files of the content.
+func (o *PostgresRepository) ListAttachmentKeys(ctx context.Context, contentID int32) ([]string, error) {
+	keys, err := o.q(ctx).ListAttachmentKeys(ctx, contentID)
+	if err != nil {
+		logger.DatabaseError("queries.ListAttachmentKeys", err, contentID)
+		return nil, otherError(err)
+	}
+
+	return keys, nil
+}
+
+// attachmentError converts error of the attachment queries.
+func attachmentError(query string, err error, inputData interface{}) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errFileNotFound
+	}
+
+	logger.DatabaseError(query, err, inputData)
+
+	return otherError(err)
+}
+
+func newAttachmentModel(attachment postgres.Attachment) models.Attachment {
+	return models.Attachment{
+		ID:          attachment.ID,
+		ContentID:   attachment.ContentID,
+		UserEmail:   attachment.UserEmail.String,
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		StorageKey:  attachment.StorageKey,
+		CreatedAt:   attachment.CreatedAt,
+	}
+}
+
+func (o *PostgresRepository) CreatBlog(
+	ctx context.Context, authorEmail, name, slug, description string) (int32, error) {
+	inputData := postgres.CreateBlogParams{
+		Name:        sql.NullString{String: name, Valid: true},
+		Description: sql.NullString{String: description, Valid: true},
+		Slug:        slug,
+		AuthorEmail: sql.NullString{String: authorEmail, Valid: authorEmail != ""},
+	}
+
+	id, err := o.q(ctx).CreateBlog(ctx, inputData)
+
+	val, ok := err.(*pgconn.PgError)
+	if ok && pgerrcode.IsIntegrityConstraintViolation(val.Code) {
+		return 0, errBlogIsExist
+	}
+
+	if err != nil {
+		logger.DatabaseError("queries.CreateBlog", err, inputData)
+		return 0, otherError(err)
+	}
+
+	return id, nil
+}
+
+func (o *PostgresRepository) ReadBlog(ctx context.Context, id int32) (*models.Blog, error) {
+	blog, err := o.q(ctx).ReadBlog(ctx, id)
+	if err != nil {
+		return nil, o.blogError("queries.ReadBlog", err, id)
+	}
+
+	blogModels := []models.Blog{newBlogModel(blog)}
+	if err = o.attachBlogTags(ctx, blogModels); err != nil {
+		return nil, err
+	}
+
+	return &blogModels[0], nil
+}
+
+// ReadBlogBySlug return blog post by slug.
+func (o *PostgresRepository) ReadBlogBySlug(ctx context.Context, slug string) (*models.Blog, error) {
+	blog, err := o.q(ctx).ReadBlogBySlug(ctx, slug)
+	if err != nil {
+		return nil, o.blogError("queries.ReadBlogBySlug", err, slug)
+	}
+
+	blogModels := []models.Blog{newBlogModel(blogRow(blog))}
+	if err = o.attachBlogTags(ctx, blogModels); err != nil {
+		return nil, err
+	}
+
+	return &blogModels[0], nil
+}
+
+// ListBlogs return page of blog posts with status and tag slug, empty status or tag is not filtered.
+func (o *PostgresRepository) ListBlogs(
+	ctx context.Context, status, tag string, limit, offset int32) ([]models.Blog, error) {
+	inputData := postgres.ListBlogsParams{
+		Status:      status,
+		Tag:         tag,
+		LimitCount:  limit,
+		OffsetCount: offset,
+	}
+
+	blogs, err := o.q(ctx).ListBlogs(ctx, inputData)
+	if err != nil {
+		logger.DatabaseError("queries.ListBlogs", err, inputData)
+		o.metric.IncDbError()
+		return nil, otherError(err)
+	}
+
+	blogModels := make([]models.Blog, 0, len(blogs))
+	for _, blog := range blogs {
+		blogModels = append(blogModels, newBlogModel(blogRow(blog)))
+	}
+
+	if err = o.attachBlogTags(ctx, blogModels); err != nil {
+		return nil, err
+	}
+
+	return blogModels, nil
+}
+
+// CountBlogs return number of blog posts found by ListBlogs with the same filter.
+func (o *PostgresRepository) CountBlogs(ctx context.Context, status, tag string) (int64, error) {
+	inputData := postgres.CountBlogsParams{
+		Status: status,
+		Tag:    tag,
+	}
+
+	count, err := o.q(ctx).CountBlogs(ctx, inputData)
+	if err != nil {
+		logger.DatabaseError("queries.CountBlogs", err, inputData)
+		o.metric.IncDbError()
+		return 0, otherError(err)
+	}
+
+	return count, nil
+}
+
+// UpdateBlog replaces name, slug and description of the blog post.
+func (o *PostgresRepository) UpdateBlog(
+	ctx context.Context, id int32, name, slug, description string) (*models.Blog, error) {
+	inputData := postgres.UpdateBlogParams{
+		ID:          id,
+		Name:        sql.NullString{String: name, Valid: true},
+		Description: sql.NullString{String: description, Valid: true},
+		Slug:        slug,
+	}
+
+	blog, err := o.q(ctx).UpdateBlog(ctx, inputData)
+
+	val, ok := err.(*pgconn.PgError)
+	if ok && pgerrcode.IsIntegrityConstraintViolation(val.Code) {
+		return nil, errBlogIsExist
+	}
+
+	if err != nil {
+		return nil, o.blogError("queries.UpdateBlog", err, inputData)
+	}
+
+	blogModels := []models.Blog{newBlogModel(blogRow(blog))}
+	if err = o.attachBlogTags(ctx, blogModels); err != nil {
+		return nil, err
+	}
+
+	return &blogModels[0], nil
+}
+
+// UpdateBlogStatus moves the blog post to status, time of the first publication is kept.
+func (o *PostgresRepository) UpdateBlogStatus(ctx context.Context, id int32, status string) (*models.Blog, error) {
+	inputData := postgres.UpdateBlogStatusParams{
+		Status: status,
+		ID:     id,
+	}
+
+	blog, err := o.q(ctx).UpdateBlogStatus(ctx, inputData)
+	if err != nil {
+		return nil, o.blogError("queries.UpdateBlogStatus", err, inputData)
+	}
+
+	blogModels := []models.Blog{newBlogModel(blogRow(blog))}
+	if err = o.attachBlogTags(ctx, blogModels); err != nil {
+		return nil, err
+	}
+
+	return &blogModels[0], nil
+}
+
+// DeleteBlog removes the blog post.
+func (o *PostgresRepository) DeleteBlog(ctx context.Context, id int32) error {
+	rows, err := o.q(ctx).DeleteBlog(ctx, id)
+	if err != nil {
+		return o.blogError("queries.DeleteBlog", err, id)
+	}
+
+	if rows == 0 {
+		return errBlogNotFound
+	}
+
+	return nil
+}
+
+// SearchBlogs return page of published blog posts found by the full-text query, most relevant first.
+func (o *PostgresRepository) SearchBlogs(
+	ctx context.Context, query string, limit, offset int32) ([]models.BlogSearchResult, error) {
+	inputData := postgres.SearchBlogsParams{
+		Query:       query,
+		LimitCount:  limit,
+		OffsetCount: offset,
+	}
+
+	blogs, err := o.q(ctx).SearchBlogs(ctx, inputData)
+	if err != nil {
+		return nil, o.blogError("queries.SearchBlogs", err, inputData)
+	}
+
+	blogModels := make([]models.Blog, 0, len(blogs))
+	for _, blog := range blogs {
+		blogModels = append(blogModels, newBlogModel(blogRow{
+			ID:          blog.ID,
+			Name:        blog.Name,
+			Description: blog.Description,
+			Slug:        blog.Slug,
+			Status:      blog.Status,
+			AuthorEmail: blog.AuthorEmail,
+			CreatedAt:   blog.CreatedAt,
+			UpdatedAt:   blog.UpdatedAt,
+			PublishedAt: blog.PublishedAt,
+		}))
+	}
+
+	if err = o.attachBlogTags(ctx, blogModels); err != nil {
+		return nil, err
+	}
+
+	results := make([]models.BlogSearchResult, 0, len(blogs))
+	for i, blog := range blogs {
+		results = append(results, models.BlogSearchResult{
+			Blog:    blogModels[i],
+			Rank:    blog.Rank,
+			Snippet: highlightSnippet(blog.Snippet),
+		})
+	}
+
+	return results, nil
+}
+
+// CountSearchBlogs return number of blog posts found by SearchBlogs.
+func (o *PostgresRepository) CountSearchBlogs(ctx context.Context, query string) (int64, error) {
+	count, err := o.q(ctx).CountSearchBlogs(ctx, query)
+	if err != nil {
+		return 0, o.blogError("queries.CountSearchBlogs", err, query)
+	}
+
+	return count, nil
+}
+
+// SetBlogTags replaces tags of the blog post in one transaction, missing tags are created.
+func (o *PostgresRepository) SetBlogTags(ctx context.Context, blogID int32, tags []models.Tag) ([]models.Tag, error) {
+	var tagModels []models.Tag
+
+	err := o.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		tagModels, err = o.setBlogTags(ctx, blogID, tags)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tagModels, nil
+}
+
+func (o *PostgresRepository) setBlogTags(ctx context.Context, blogID int32, tags []models.Tag) ([]models.Tag, error) {
+	if err := o.q(ctx).DeleteBlogTags(ctx, blogID); err != nil {
+		return nil, o.blogError("queries.DeleteBlogTags", err, blogID)
+	}
+
+	if len(tags) == 0 {
+		return []models.Tag{}, nil
+	}
+
+	inputData := postgres.UpsertTagsParams{
+		Names: make([]string, 0, len(tags)),
+		Slugs: make([]string, 0, len(tags)),
+	}
+
+	for _, tag := range tags {
+		inputData.Names = append(inputData.Names, tag.Name)
+		inputData.Slugs = append(inputData.Slugs, tag.Slug)
+	}
+
+	storedTags, err := o.q(ctx).UpsertTags(ctx, inputData)
+	if err != nil {
+		return nil, o.blogError("queries.UpsertTags", err, inputData)
+	}
+
+	links := postgres.AddBlogTagsParams{BlogID: blogID, TagIds: make([]int32, 0, len(storedTags))}
+	tagModels := make([]models.Tag, 0, len(storedTags))
+
+	for _, tag := range storedTags {
+		links.TagIds = append(links.TagIds, tag.ID)
+		tagModels = append(tagModels, models.Tag{ID: tag.ID, Name: tag.Name, Slug: tag.Slug})
+	}
+
+	err = o.q(ctx).AddBlogTags(ctx, links)
+
+	val, ok := err.(*pgconn.PgError)
+	if ok && val.Code == pgerrcode.ForeignKeyViolation {
+		return nil, errBlogNotFound
+	}
+
+	if err != nil {
+		return nil, o.blogError("queries.AddBlogTags", err, links)
+	}
+
+	return tagModels, nil
+}
+
+// ListTags return all tags with number of published posts.
+func (o *PostgresRepository) ListTags(ctx context.Context) ([]models.Tag, error) {
+	tags, err := o.q(ctx).ListTags(ctx)
+	if err != nil {
+		logger.DatabaseError("queries.ListTags", err, nil)
+		o.metric.IncDbError()
+		return nil, otherError(err)
+	}
+
+	tagModels := make([]models.Tag, 0, len(tags))
+	for _, tag := range tags {
+		tagModels = append(tagModels, models.Tag{ID: tag.ID, Name: tag.Name, Slug: tag.Slug, Posts: tag.Posts})
+	}
+
+	return tagModels, nil
+}
+
+// attachBlogTags loads tags of all blogs by one query.
+func (o *PostgresRepository) attachBlogTags(ctx context.Context, blogs []models.Blog) error {
+	if len(blogs) == 0 {
+		return nil
+	}
+
+	ids := make([]int32, 0, len(blogs))
+	positions := make(map[int32]int, len(blogs))
+
+	for i := range blogs {
+		blogs[i].Tags = []models.Tag{}
+		ids = append(ids, blogs[i].ID)
+		positions[blogs[i].ID] = i
+	}
+
+	tags, err := o.q(ctx).ListBlogsTags(ctx, ids)
+	if err != nil {
+		return o.blogError("queries.ListBlogsTags", err, ids)
+	}
+
+	for _, tag := range tags {
+		i := positions[tag.BlogID]
+		blogs[i].Tags = append(blogs[i].Tags, models.Tag{ID: tag.ID, Name: tag.Name, Slug: tag.Slug})
+	}
+
+	return nil
+}
+
+// blogError converts error of the blog queries.
+func (o *PostgresRepository) blogError(query string, err error, inputData interface{}) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errBlogNotFound
+	}
+
+	logger.DatabaseError(query, err, inputData)
+	o.metric.IncDbError()
+
+	return otherError(err)
+}
+
+// blogRow blog columns without search vector, rows of all blog queries are converted into it.
+type blogRow = postgres.ReadBlogRow
+
+func newBlogModel(blog blogRow) models.Blog {
+	blogModel := models.Blog{
+		ID:          blog.ID,
+		Name:        blog.Name.String,
+		Description: blog.Description.String,
+		Slug:        blog.Slug,
+		Status:      blog.Status,
+		AuthorEmail: blog.AuthorEmail.String,
+		CreatedAt:   blog.CreatedAt,
+		UpdatedAt:   blog.UpdatedAt,
+	}
+
+	if blog.PublishedAt.Valid {
+		publishedAt := blog.PublishedAt.Time
+		blogModel.PublishedAt = &publishedAt
+	}
+
+	return blogModel
+}
+
+// CreateComment adds comment of the user to the blog post, parentID is 0 for top-level comments.
+func (o *PostgresRepository) CreateComment(
+	ctx context.Context, blogID, parentID int32, authorEmail, body string) (*models.Comment, error) {
+	inputData := postgres.CreateCommentParams{
+		BlogID:      blogID,
+		ParentID:    sql.NullInt32{Int32: parentID, Valid: parentID != 0},
+		AuthorEmail: sql.NullString{String: authorEmail, Valid: true},
+		Body:        body,
+	}
+
+	comment, err := o.q(ctx).CreateComment(ctx, inputData)
+
+	val, ok := err.(*pgconn.PgError)
+	if ok && val.Code == pgerrcode.ForeignKeyViolation {
+		return nil, errBlogNotFound
+	}
+
+	if err != nil {
+		logger.DatabaseError("queries.CreateComment", err, inputData)
+		return nil, otherError(err)
+	}
+
+	commentModel := newCommentModel(comment)
+
+	return &commentModel, nil
+}
+
+func (o *PostgresRepository) ReadComment(ctx context.Context, id int32) (*models.Comment, error) {
+	comment, err := o.q(ctx).ReadComment(ctx, id)
+	if err != nil {
+		return nil, commentError("queries.ReadComment", err, id)
+	}
+
+	commentModel := newCommentModel(comment)
+
+	return &commentModel, nil
+}
+
+// UpdateCommentBody changes text of the author comment, the comment goes back to moderation.
+func (o *PostgresRepository) UpdateCommentBody(
+	ctx context.Context, id int32, authorEmail, body string) (*models.Comment, error) {
+	inputData := postgres.UpdateCommentBodyParams{
+		ID:          id,
+		AuthorEmail: sql.NullString{String: authorEmail, Valid: true},
+		Body:        body,
+	}
+
+	comment, err := o.q(ctx).UpdateCommentBody(ctx, inputData)
+	if err != nil {
+		return nil, commentError("queries.UpdateCommentBody", err, inputData)
+	}
+
+	commentModel := newCommentModel(comment)
+
+	return &commentModel, nil
+}
+
+// DeleteComment marks the author comment as deleted, replies are kept.
+func (o *PostgresRepository) DeleteComment(ctx context.Context, id int32, authorEmail string) error {
+	inputData := postgres.DeleteCommentParams{
+		ID:          id,
+		AuthorEmail: sql.NullString{String: authorEmail, Valid: true},
+	}
+
+	rows, err := o.q(ctx).DeleteComment(ctx, inputData)
+	if err != nil {
+		return commentError("queries.DeleteComment", err, inputData)
+	}
+
+	if rows == 0 {
+		return errCommentNotFound
+	}
+
+	return nil
+}
+
+// UpdateCommentStatus sets moderation status of the comment.
+func (o *PostgresRepository) UpdateCommentStatus(ctx context.Context, id int32, status string) (*models.Comment, error) {
+	inputData := postgres.UpdateCommentStatusParams{
+		ID:     id,
+		Status: status,
+	}
+
+	comment, err := o.q(ctx).UpdateCommentStatus(ctx, inputData)
+	if err != nil {
+		return nil, commentError("queries.UpdateCommentStatus", err, inputData)
+	}
+
+	commentModel := newCommentModel(comment)
+
+	return &commentModel, nil
+}
+
+// ListBlogComments return page of approved top-level comments of the blog post with approved replies.
+func (o *PostgresRepository) ListBlogComments(
+	ctx context.Context, blogID, limit, offset int32) ([]models.Comment, error) {
+	inputData := postgres.ListBlogCommentsParams{
+		BlogID:      blogID,
+		LimitCount:  limit,
+		OffsetCount: offset,
+	}
+
+	comments, err := o.q(ctx).ListBlogComments(ctx, inputData)
+	if err != nil {
+		return nil, commentError("queries.ListBlogComments", err, inputData)
+	}
+
+	commentModels := make([]models.Comment, 0, len(comments))
+	parentIDs := make([]int32, 0, len(comments))
+	positions := make(map[int32]int, len(comments))
+
+	for i, comment := range comments {
+		commentModels = append(commentModels, newThreadCommentModel(comment))
+		commentModels[i].Replies = []models.Comment{}
+		parentIDs = append(parentIDs, comment.ID)
+		positions[comment.ID] = i
+	}
+
+	if len(parentIDs) == 0 {
+		return commentModels, nil
+	}
+
+	replies, err := o.q(ctx).ListCommentReplies(ctx, parentIDs)
+	if err != nil {
+		return nil, commentError("queries.ListCommentReplies", err, parentIDs)
+	}
+
+	for _, reply := range replies {
+		i := positions[reply.ParentID.Int32]
+		commentModels[i].Replies = append(commentModels[i].Replies,
+			newThreadCommentModel(postgres.ListBlogCommentsRow(reply)))
+	}
+
+	return commentModels, nil
+}
+
+// CountBlogComments return number of comments found by ListBlogComments.
+func (o *PostgresRepository) CountBlogComments(ctx context.Context, blogID int32) (int64, error) {
+	count, err := o.q(ctx).CountBlogComments(ctx, blogID)
+	if err != nil {
+		return 0, commentError("queries.CountBlogComments", err, blogID)
+	}
+
+	return count, nil
+}
+
+// ListCommentsByStatus return page of comments with status, oldest first.
+func (o *PostgresRepository) ListCommentsByStatus(
+	ctx context.Context, status string, limit, offset int32) ([]models.Comment, error) {
+	inputData := postgres.ListCommentsByStatusParams{
+		Status:      status,
+		LimitCount:  limit,
+		OffsetCount: offset,
+	}
+
+	comments, err := o.q(ctx).ListCommentsByStatus(ctx, inputData)
+	if err != nil {
+		return nil, commentError("queries.ListCommentsByStatus", err, inputData)
+	}
+
+	commentModels := make([]models.Comment, 0, len(comments))
+	for _, comment := range comments {
+		commentModels = append(commentModels, newCommentModel(comment))
+	}
+
+	return commentModels, nil
+}
+
+// CountCommentsByStatus return number of comments found by ListCommentsByStatus.
+func (o *PostgresRepository) CountCommentsByStatus(ctx context.Context, status string) (int64, error) {
+	count, err := o.q(ctx).CountCommentsByStatus(ctx, status)
+	if err != nil {
+		return 0, commentError("queries.CountCommentsByStatus", err, status)
+	}
+
+	return count, nil
+}
+
+// newThreadCommentModel converts comment of the public thread with the author name.
+func newThreadCommentModel(comment postgres.ListBlogCommentsRow) models.Comment {
+	commentModel := newCommentModel(postgres.Comment{
+		ID:          comment.ID,
+		BlogID:      comment.BlogID,
+		ParentID:    comment.ParentID,
+		AuthorEmail: comment.AuthorEmail,
+		Body:        comment.Body,
+		Status:      comment.Status,
+		IsDeleted:   comment.IsDeleted,
+		CreatedAt:   comment.CreatedAt,
+		UpdatedAt:   comment.UpdatedAt,
+	})
+	commentModel.AuthorName = comment.AuthorName
+
+	return commentModel
+}
+
+// commentError converts error of the comment queries.
+func commentError(query string, err error, inputData interface{}) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errCommentNotFound
+	}
+
+	logger.DatabaseError(query, err, inputData)
+
+	return otherError(err)
+}
+
+func newCommentModel(comment postgres.Comment) models.Comment {
+	commentModel := models.Comment{
+		ID:          comment.ID,
+		BlogID:      comment.BlogID,
+		ParentID:    comment.ParentID.Int32,
+		AuthorEmail: comment.AuthorEmail.String,
+		Body:        comment.Body,
+		Status:      comment.Status,
+		IsDeleted:   comment.IsDeleted,
+		CreatedAt:   comment.CreatedAt,
+		UpdatedAt:   comment.UpdatedAt,
+	}
+
+	// text of the deleted comment is not shown
+	if comment.IsDeleted {
+		commentModel.Body = ""
+	}
+
+	return commentModel
+}
+
+// ListRolePermissions return mapping of roles to permissions.
+func (o *PostgresRepository) ListRolePermissions(ctx context.Context) ([]models.RolePermission, error) {
+	rolePermissions, err := o.q(ctx).ListRolePermissions(ctx)
+	if err != nil {
+		logger.DatabaseError("queries.ListRolePermissions", err, nil)
+		return nil, otherError(err)
+	}
+
+	rolePermissionModels := make([]models.RolePermission, 0, len(rolePermissions))
+	for _, rolePermission := range rolePermissions {
+		rolePermissionModels = append(rolePermissionModels, models.RolePermission{
+			Role:       rolePermission.Role,
+			Permission: rolePermission.Permission,
+		})
+	}
+
+	return rolePermissionModels, nil
+}
+
+// ReadLoginAttempts return failed sign-in attempts by key, empty attempts if the key is missing or expired.
+func (o *PostgresRepository) ReadLoginAttempts(ctx context.Context, key string) (*models.LoginAttempts, error) {
+	inputData := postgres.ReadLoginAttemptsParams{
+		Key:       key,
+		ExpiresIn: time.Now().Unix(),
+	}
+
+	attempts, err := o.q(ctx).ReadLoginAttempts(ctx, inputData)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &models.LoginAttempts{}, nil
+		}
+
+		logger.DatabaseError("queries.ReadLoginAttempts", err, inputData)
+		return nil, otherError(err)
+	}
+
+	attemptsModel := &models.LoginAttempts{
+		Failures:    attempts.Failures,
+		LockedUntil: attempts.LockedUntil,
+	}
+
+	return attemptsModel, nil
+}
+
+// IncrementLoginAttempts adds one failed sign-in attempt in one statement, so concurrent failures
+// are not lost, the counter of the expired key starts again.
+func (o *PostgresRepository) IncrementLoginAttempts(
+	ctx context.Context, key string, window time.Duration) (models.LoginAttempts, error) {
+	now := time.Now()
+	inputData := postgres.IncrementLoginAttemptsParams{
+		Key:       key,
+		ExpiresIn: now.Add(window).Unix(),
+		Now:       now.Unix(),
+	}
+
+	attempts, err := o.q(ctx).IncrementLoginAttempts(ctx, inputData)
+	if err != nil {
+		logger.DatabaseError("queries.IncrementLoginAttempts", err, inputData)
+		return models.LoginAttempts{}, otherError(err)
+	}
+
+	attemptsModel := models.LoginAttempts{
+		Failures:    attempts.Failures,
+		LockedUntil: attempts.LockedUntil,
+	}
+
+	return attemptsModel, nil
+}
+
+// LockLoginAttempts locks the key until lockedUntil, the longer lockout is kept.
+func (o *PostgresRepository) LockLoginAttempts(ctx context.Context, key string, lockedUntil int64) error {
+	inputData := postgres.LockLoginAttemptsParams{
+		LockedUntil: lockedUntil,
+		Key:         key,
+	}
+
+	if err := o.q(ctx).LockLoginAttempts(ctx, inputData); err != nil {
+		logger.DatabaseError("queries.LockLoginAttempts", err, inputData)
+		return otherError(err)
+	}
+
+	return nil
+}
+
+func (o *PostgresRepository) DeleteLoginAttempts(ctx context.Context, key string) error {
+	if err := o.q(ctx).DeleteLoginAttempts(ctx, key); err != nil {
+		logger.DatabaseError("queries.DeleteLoginAttempts", err, key)
+		return otherError(err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredLoginAttempts removes counters after the window, they are the same as missing ones.
+func (o *PostgresRepository) DeleteExpiredLoginAttempts(ctx context.Context) error {
+	now := time.Now().Unix()
+	if err := o.q(ctx).DeleteExpiredLoginAttempts(ctx, now); err != nil {
+		logger.DatabaseError("queries.DeleteExpiredLoginAttempts", err, now)
+		return otherError(err)
+	}
+
+	return nil
 }
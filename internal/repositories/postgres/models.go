@@ -9,28 +9,136 @@ import (
 	"time"
 )
 
+type Attachment struct {
+	ID          int32
+	ContentID   int32
+	UserEmail   sql.NullString
+	FileName    string
+	ContentType string
+	Size        int64
+	StorageKey  string
+	CreatedAt   time.Time
+}
+
 type Blog struct {
+	ID           int32
+	Name         sql.NullString
+	Description  sql.NullString
+	Slug         string
+	Status       string
+	AuthorEmail  sql.NullString
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	PublishedAt  sql.NullTime
+	SearchVector interface{}
+}
+
+type BlogTag struct {
+	BlogID int32
+	TagID  int32
+}
+
+type Comment struct {
 	ID          int32
-	Name        sql.NullString
-	Description sql.NullString
+	BlogID      int32
+	ParentID    sql.NullInt32
+	AuthorEmail sql.NullString
+	Body        string
+	Status      string
+	IsDeleted   bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 }
 
 type Content struct {
+	ID           int32
+	UserEmail    sql.NullString
+	Name         sql.NullString
+	Description  sql.NullString
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	SearchVector interface{}
+}
+
+type ContentLink struct {
+	ID        int32
+	ContentID int32
+	TokenHash string
+	ExpiresIn int64
+	CreatedAt time.Time
+}
+
+type ContentRevision struct {
 	ID          int32
-	UserEmail   sql.NullString
+	ContentID   int32
+	Revision    int32
+	AuthorEmail sql.NullString
 	Name        sql.NullString
 	Description sql.NullString
+	Summary     string
+	CreatedAt   time.Time
+}
+
+type ContentShare struct {
+	ContentID  int32
+	UserEmail  string
+	Permission string
+	CreatedAt  time.Time
+}
+
+type LoginAttempt struct {
+	Key         string
+	Failures    int32
+	LockedUntil int64
+	ExpiresIn   int64
+}
+
+type PasswordResetToken struct {
+	TokenHash string
+	UserEmail string
+	ExpiresIn int64
+	UsedAt    sql.NullTime
+	CreatedAt time.Time
+}
+
+type Permission struct {
+	Name        string
+	Description sql.NullString
+}
+
+type RetiredRefreshToken struct {
+	Token     string
+	SessionID int32
+	UserEmail sql.NullString
+	RetiredAt time.Time
+}
+
+type Role struct {
+	Name        string
+	Description sql.NullString
+}
+
+type RolePermission struct {
+	Role       string
+	Permission string
 }
 
 type Session struct {
-	ID           int32
-	UserEmail    sql.NullString
-	RefreshToken sql.NullString
-	AccessToken  sql.NullString
-	UserAgent    sql.NullString
-	Ip           sql.NullString
-	ExpiresIn    int64
-	CreatedAt    time.Time
+	ID              int32
+	UserEmail       sql.NullString
+	RefreshToken    sql.NullString
+	AccessToken     sql.NullString
+	UserAgent       sql.NullString
+	Ip              sql.NullString
+	ExpiresIn       int64
+	CreatedAt       time.Time
+	AccessExpiresIn int64
+}
+
+type Tag struct {
+	ID   int32
+	Name string
+	Slug string
 }
 
 type User struct {
@@ -8,26 +8,244 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
-const createBlog = `-- name: CreateBlog :exec
-INSERT INTO blog(name, description)
-VALUES ($1, $2)
+const addBlogTags = `-- name: AddBlogTags :exec
+INSERT INTO blog_tags(blog_id, tag_id)
+SELECT $1, unnest($2::int[])
+ON CONFLICT DO NOTHING
+`
+
+type AddBlogTagsParams struct {
+	BlogID int32
+	TagIds []int32
+}
+
+func (q *Queries) AddBlogTags(ctx context.Context, arg AddBlogTagsParams) error {
+	_, err := q.db.Exec(ctx, addBlogTags, arg.BlogID, arg.TagIds)
+	return err
+}
+
+const countBlogComments = `-- name: CountBlogComments :one
+SELECT count(*) FROM comments c
+WHERE c.blog_id = $1 AND c.parent_id IS NULL AND c.status = 'approved'
+  AND (NOT c.is_deleted OR EXISTS (SELECT 1 FROM comments r
+                                   WHERE r.parent_id = c.id AND r.status = 'approved' AND NOT r.is_deleted))
+`
+
+func (q *Queries) CountBlogComments(ctx context.Context, blogID int32) (int64, error) {
+	row := q.db.QueryRow(ctx, countBlogComments, blogID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countBlogs = `-- name: CountBlogs :one
+SELECT count(*) FROM blog
+WHERE ($1::text = '' OR status = $1::text)
+  AND ($2::text = '' OR EXISTS (SELECT 1 FROM blog_tags bt JOIN tags t ON t.id = bt.tag_id
+                                           WHERE bt.blog_id = blog.id AND t.slug = $2::text))
+`
+
+type CountBlogsParams struct {
+	Status string
+	Tag    string
+}
+
+func (q *Queries) CountBlogs(ctx context.Context, arg CountBlogsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countBlogs, arg.Status, arg.Tag)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countCommentsByStatus = `-- name: CountCommentsByStatus :one
+SELECT count(*) FROM comments
+WHERE status = $1 AND NOT is_deleted
+`
+
+func (q *Queries) CountCommentsByStatus(ctx context.Context, status string) (int64, error) {
+	row := q.db.QueryRow(ctx, countCommentsByStatus, status)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countContentRevisions = `-- name: CountContentRevisions :one
+SELECT count(*) FROM content_revisions
+WHERE content_id=$1
+`
+
+func (q *Queries) CountContentRevisions(ctx context.Context, contentID int32) (int64, error) {
+	row := q.db.QueryRow(ctx, countContentRevisions, contentID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSearchBlogs = `-- name: CountSearchBlogs :one
+SELECT count(*) FROM blog
+WHERE status = 'published' AND search_vector @@ websearch_to_tsquery('russian', $1)
+`
+
+func (q *Queries) CountSearchBlogs(ctx context.Context, query string) (int64, error) {
+	row := q.db.QueryRow(ctx, countSearchBlogs, query)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSearchContent = `-- name: CountSearchContent :one
+SELECT count(*) FROM content
+WHERE user_email = $1 AND search_vector @@ websearch_to_tsquery('russian', $2)
+`
+
+type CountSearchContentParams struct {
+	UserEmail sql.NullString
+	Query     string
+}
+
+func (q *Queries) CountSearchContent(ctx context.Context, arg CountSearchContentParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countSearchContent, arg.UserEmail, arg.Query)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSharedContent = `-- name: CountSharedContent :one
+SELECT count(*) FROM content_shares
+WHERE user_email=$1
+`
+
+func (q *Queries) CountSharedContent(ctx context.Context, userEmail string) (int64, error) {
+	row := q.db.QueryRow(ctx, countSharedContent, userEmail)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUsers = `-- name: CountUsers :one
+SELECT count(*) FROM users
+WHERE ($1::text = '' OR email ILIKE '%' || $1::text || '%'
+    OR name ILIKE '%' || $1::text || '%')
+  AND ($2::bool OR is_deleted IS NOT TRUE)
+`
+
+type CountUsersParams struct {
+	Search      string
+	WithDeleted bool
+}
+
+func (q *Queries) CountUsers(ctx context.Context, arg CountUsersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUsers, arg.Search, arg.WithDeleted)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAttachment = `-- name: CreateAttachment :one
+INSERT INTO attachments(content_id, user_email, file_name, content_type, size, storage_key)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, content_id, user_email, file_name, content_type, size, storage_key, created_at
+`
+
+type CreateAttachmentParams struct {
+	ContentID   int32
+	UserEmail   sql.NullString
+	FileName    string
+	ContentType string
+	Size        int64
+	StorageKey  string
+}
+
+func (q *Queries) CreateAttachment(ctx context.Context, arg CreateAttachmentParams) (Attachment, error) {
+	row := q.db.QueryRow(ctx, createAttachment,
+		arg.ContentID,
+		arg.UserEmail,
+		arg.FileName,
+		arg.ContentType,
+		arg.Size,
+		arg.StorageKey,
+	)
+	var i Attachment
+	err := row.Scan(
+		&i.ID,
+		&i.ContentID,
+		&i.UserEmail,
+		&i.FileName,
+		&i.ContentType,
+		&i.Size,
+		&i.StorageKey,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createBlog = `-- name: CreateBlog :one
+INSERT INTO blog(name, description, slug, author_email)
+VALUES ($1, $2, $3, $4)
+RETURNING id
 `
 
 type CreateBlogParams struct {
 	Name        sql.NullString
 	Description sql.NullString
+	Slug        string
+	AuthorEmail sql.NullString
 }
 
-func (q *Queries) CreateBlog(ctx context.Context, arg CreateBlogParams) error {
-	_, err := q.db.Exec(ctx, createBlog, arg.Name, arg.Description)
-	return err
+func (q *Queries) CreateBlog(ctx context.Context, arg CreateBlogParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createBlog,
+		arg.Name,
+		arg.Description,
+		arg.Slug,
+		arg.AuthorEmail,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
 }
 
-const createContent = `-- name: CreateContent :exec
+const createComment = `-- name: CreateComment :one
+INSERT INTO comments(blog_id, parent_id, author_email, body)
+VALUES ($1, $2, $3, $4)
+RETURNING id, blog_id, parent_id, author_email, body, status, is_deleted, created_at, updated_at
+`
+
+type CreateCommentParams struct {
+	BlogID      int32
+	ParentID    sql.NullInt32
+	AuthorEmail sql.NullString
+	Body        string
+}
+
+func (q *Queries) CreateComment(ctx context.Context, arg CreateCommentParams) (Comment, error) {
+	row := q.db.QueryRow(ctx, createComment,
+		arg.BlogID,
+		arg.ParentID,
+		arg.AuthorEmail,
+		arg.Body,
+	)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.BlogID,
+		&i.ParentID,
+		&i.AuthorEmail,
+		&i.Body,
+		&i.Status,
+		&i.IsDeleted,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createContent = `-- name: CreateContent :one
 INSERT INTO content(user_email, name, description)
 VALUES ($1, $2, $3)
+RETURNING id
 `
 
 type CreateContentParams struct {
@@ -36,14 +254,110 @@ type CreateContentParams struct {
 	Description sql.NullString
 }
 
-func (q *Queries) CreateContent(ctx context.Context, arg CreateContentParams) error {
-	_, err := q.db.Exec(ctx, createContent, arg.UserEmail, arg.Name, arg.Description)
+func (q *Queries) CreateContent(ctx context.Context, arg CreateContentParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createContent, arg.UserEmail, arg.Name, arg.Description)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createContentLink = `-- name: CreateContentLink :one
+INSERT INTO content_links(content_id, token_hash, expires_in)
+VALUES ($1, $2, $3)
+RETURNING id, content_id, token_hash, expires_in, created_at
+`
+
+type CreateContentLinkParams struct {
+	ContentID int32
+	TokenHash string
+	ExpiresIn int64
+}
+
+func (q *Queries) CreateContentLink(ctx context.Context, arg CreateContentLinkParams) (ContentLink, error) {
+	row := q.db.QueryRow(ctx, createContentLink, arg.ContentID, arg.TokenHash, arg.ExpiresIn)
+	var i ContentLink
+	err := row.Scan(
+		&i.ID,
+		&i.ContentID,
+		&i.TokenHash,
+		&i.ExpiresIn,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createContentRevision = `-- name: CreateContentRevision :one
+INSERT INTO content_revisions(content_id, revision, author_email, name, description, summary)
+VALUES ($1, (SELECT coalesce(max(revision), 0) + 1 FROM content_revisions WHERE content_id = $1), $2, $3, $4, $5)
+RETURNING id, content_id, revision, author_email, name, description, summary, created_at
+`
+
+type CreateContentRevisionParams struct {
+	ContentID   int32
+	AuthorEmail sql.NullString
+	Name        sql.NullString
+	Description sql.NullString
+	Summary     string
+}
+
+func (q *Queries) CreateContentRevision(ctx context.Context, arg CreateContentRevisionParams) (ContentRevision, error) {
+	row := q.db.QueryRow(ctx, createContentRevision,
+		arg.ContentID,
+		arg.AuthorEmail,
+		arg.Name,
+		arg.Description,
+		arg.Summary,
+	)
+	var i ContentRevision
+	err := row.Scan(
+		&i.ID,
+		&i.ContentID,
+		&i.Revision,
+		&i.AuthorEmail,
+		&i.Name,
+		&i.Description,
+		&i.Summary,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createPasswordResetToken = `-- name: CreatePasswordResetToken :exec
+INSERT INTO password_reset_tokens(token_hash, user_email, expires_in)
+VALUES ($1, $2, $3)
+`
+
+type CreatePasswordResetTokenParams struct {
+	TokenHash string
+	UserEmail string
+	ExpiresIn int64
+}
+
+func (q *Queries) CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) error {
+	_, err := q.db.Exec(ctx, createPasswordResetToken, arg.TokenHash, arg.UserEmail, arg.ExpiresIn)
 	return err
 }
 
-const createSession = `-- name: CreateSession :exec
+const createRetiredRefreshToken = `-- name: CreateRetiredRefreshToken :exec
+INSERT INTO retired_refresh_tokens(token, session_id, user_email, retired_at)
+VALUES ($1, $2, $3, DEFAULT)
+`
+
+type CreateRetiredRefreshTokenParams struct {
+	Token     string
+	SessionID int32
+	UserEmail sql.NullString
+}
+
+func (q *Queries) CreateRetiredRefreshToken(ctx context.Context, arg CreateRetiredRefreshTokenParams) error {
+	_, err := q.db.Exec(ctx, createRetiredRefreshToken, arg.Token, arg.SessionID, arg.UserEmail)
+	return err
+}
+
+const createSession = `-- name: CreateSession :one
 INSERT INTO sessions(user_email, refresh_token, access_token, user_agent, ip, expires_in, created_at)
 VALUES ($1, $2, $3, $4, $5, $6, DEFAULT)
+RETURNING id
 `
 
 type CreateSessionParams struct {
@@ -55,8 +369,8 @@ type CreateSessionParams struct {
 	ExpiresIn    int64
 }
 
-func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) error {
-	_, err := q.db.Exec(ctx, createSession,
+func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createSession,
 		arg.UserEmail,
 		arg.RefreshToken,
 		arg.AccessToken,
@@ -64,12 +378,15 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) er
 		arg.Ip,
 		arg.ExpiresIn,
 	)
-	return err
+	var id int32
+	err := row.Scan(&id)
+	return id, err
 }
 
-const createUser = `-- name: CreateUser :exec
+const createUser = `-- name: CreateUser :one
 INSERT INTO users(name, password, email, role)
 VALUES ($1, $2, $3, $4)
+RETURNING id
 `
 
 type CreateUserParams struct {
@@ -79,112 +396,1367 @@ type CreateUserParams struct {
 	Role     string
 }
 
-func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) error {
-	_, err := q.db.Exec(ctx, createUser,
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createUser,
 		arg.Name,
 		arg.Password,
 		arg.Email,
 		arg.Role,
 	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const deleteAttachment = `-- name: DeleteAttachment :one
+DELETE FROM attachments
+WHERE id=$1 and content_id=$2
+RETURNING storage_key
+`
+
+type DeleteAttachmentParams struct {
+	ID        int32
+	ContentID int32
+}
+
+func (q *Queries) DeleteAttachment(ctx context.Context, arg DeleteAttachmentParams) (string, error) {
+	row := q.db.QueryRow(ctx, deleteAttachment, arg.ID, arg.ContentID)
+	var storage_key string
+	err := row.Scan(&storage_key)
+	return storage_key, err
+}
+
+const deleteBlog = `-- name: DeleteBlog :execrows
+DELETE FROM blog
+WHERE id=$1
+`
+
+func (q *Queries) DeleteBlog(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteBlog, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteBlogTags = `-- name: DeleteBlogTags :exec
+DELETE FROM blog_tags
+WHERE blog_id=$1
+`
+
+func (q *Queries) DeleteBlogTags(ctx context.Context, blogID int32) error {
+	_, err := q.db.Exec(ctx, deleteBlogTags, blogID)
 	return err
 }
 
-const deleteContent = `-- name: DeleteContent :exec
+const deleteComment = `-- name: DeleteComment :execrows
+UPDATE comments SET is_deleted=true, updated_at=now()
+WHERE id=$1 AND author_email=$2 AND NOT is_deleted
+`
+
+type DeleteCommentParams struct {
+	ID          int32
+	AuthorEmail sql.NullString
+}
+
+func (q *Queries) DeleteComment(ctx context.Context, arg DeleteCommentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteComment, arg.ID, arg.AuthorEmail)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteContent = `-- name: DeleteContent :execrows
 DELETE FROM content
-WHERE user_email=$1 and name=$2
+WHERE user_email=$1 and id=$2
 `
 
 type DeleteContentParams struct {
 	UserEmail sql.NullString
+	ID        int32
+}
+
+func (q *Queries) DeleteContent(ctx context.Context, arg DeleteContentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteContent, arg.UserEmail, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteContentLink = `-- name: DeleteContentLink :execrows
+DELETE FROM content_links
+WHERE id=$1 and content_id=$2
+`
+
+type DeleteContentLinkParams struct {
+	ID        int32
+	ContentID int32
+}
+
+func (q *Queries) DeleteContentLink(ctx context.Context, arg DeleteContentLinkParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteContentLink, arg.ID, arg.ContentID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteContentShare = `-- name: DeleteContentShare :execrows
+DELETE FROM content_shares
+WHERE content_id=$1 and user_email=$2
+`
+
+type DeleteContentShareParams struct {
+	ContentID int32
+	UserEmail string
+}
+
+func (q *Queries) DeleteContentShare(ctx context.Context, arg DeleteContentShareParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteContentShare, arg.ContentID, arg.UserEmail)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteExpiredLoginAttempts = `-- name: DeleteExpiredLoginAttempts :exec
+DELETE FROM login_attempts
+WHERE expires_in < $1
+`
+
+func (q *Queries) DeleteExpiredLoginAttempts(ctx context.Context, expiresIn int64) error {
+	_, err := q.db.Exec(ctx, deleteExpiredLoginAttempts, expiresIn)
+	return err
+}
+
+const deleteLoginAttempts = `-- name: DeleteLoginAttempts :exec
+DELETE FROM login_attempts
+WHERE key=$1
+`
+
+func (q *Queries) DeleteLoginAttempts(ctx context.Context, key string) error {
+	_, err := q.db.Exec(ctx, deleteLoginAttempts, key)
+	return err
+}
+
+const deleteOtherSessions = `-- name: DeleteOtherSessions :many
+DELETE FROM sessions
+WHERE user_email=$1 and id <> $2
+RETURNING id, user_email, refresh_token, access_token, user_agent, ip, expires_in, created_at, access_expires_in
+`
+
+type DeleteOtherSessionsParams struct {
+	UserEmail sql.NullString
+	ID        int32
+}
+
+func (q *Queries) DeleteOtherSessions(ctx context.Context, arg DeleteOtherSessionsParams) ([]Session, error) {
+	rows, err := q.db.Query(ctx, deleteOtherSessions, arg.UserEmail, arg.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Session
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserEmail,
+			&i.RefreshToken,
+			&i.AccessToken,
+			&i.UserAgent,
+			&i.Ip,
+			&i.ExpiresIn,
+			&i.CreatedAt,
+			&i.AccessExpiresIn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteSession = `-- name: DeleteSession :exec
+DELETE FROM sessions
+WHERE user_agent=$1 and ip=$2 and user_email=$3
+`
+
+type DeleteSessionParams struct {
+	UserAgent sql.NullString
+	Ip        sql.NullString
+	UserEmail sql.NullString
+}
+
+func (q *Queries) DeleteSession(ctx context.Context, arg DeleteSessionParams) error {
+	_, err := q.db.Exec(ctx, deleteSession, arg.UserAgent, arg.Ip, arg.UserEmail)
+	return err
+}
+
+const deleteSessionByID = `-- name: DeleteSessionByID :one
+DELETE FROM sessions
+WHERE id=$1 and user_email=$2
+RETURNING id, user_email, refresh_token, access_token, user_agent, ip, expires_in, created_at, access_expires_in
+`
+
+type DeleteSessionByIDParams struct {
+	ID        int32
+	UserEmail sql.NullString
+}
+
+func (q *Queries) DeleteSessionByID(ctx context.Context, arg DeleteSessionByIDParams) (Session, error) {
+	row := q.db.QueryRow(ctx, deleteSessionByID, arg.ID, arg.UserEmail)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.UserEmail,
+		&i.RefreshToken,
+		&i.AccessToken,
+		&i.UserAgent,
+		&i.Ip,
+		&i.ExpiresIn,
+		&i.CreatedAt,
+		&i.AccessExpiresIn,
+	)
+	return i, err
+}
+
+const deleteUser = `-- name: DeleteUser :exec
+UPDATE users SET is_deleted=true
+WHERE email = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, email string) error {
+	_, err := q.db.Exec(ctx, deleteUser, email)
+	return err
+}
+
+const deleteUserByID = `-- name: DeleteUserByID :one
+UPDATE users SET is_deleted=true
+WHERE id = $1
+RETURNING email
+`
+
+func (q *Queries) DeleteUserByID(ctx context.Context, id int32) (string, error) {
+	row := q.db.QueryRow(ctx, deleteUserByID, id)
+	var email string
+	err := row.Scan(&email)
+	return email, err
+}
+
+const deleteUserSessions = `-- name: DeleteUserSessions :many
+DELETE FROM sessions
+WHERE user_email=$1
+RETURNING id, user_email, refresh_token, access_token, user_agent, ip, expires_in, created_at, access_expires_in
+`
+
+func (q *Queries) DeleteUserSessions(ctx context.Context, userEmail sql.NullString) ([]Session, error) {
+	rows, err := q.db.Query(ctx, deleteUserSessions, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Session
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserEmail,
+			&i.RefreshToken,
+			&i.AccessToken,
+			&i.UserAgent,
+			&i.Ip,
+			&i.ExpiresIn,
+			&i.CreatedAt,
+			&i.AccessExpiresIn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const incrementLoginAttempts = `-- name: IncrementLoginAttempts :one
+INSERT INTO login_attempts(key, failures, locked_until, expires_in)
+VALUES ($1, 1, 0, $2)
+ON CONFLICT (key) DO UPDATE
+SET failures=CASE WHEN login_attempts.expires_in > $3 THEN login_attempts.failures + 1 ELSE 1 END,
+    locked_until=CASE WHEN login_attempts.expires_in > $3 THEN login_attempts.locked_until ELSE 0 END,
+    expires_in=GREATEST(login_attempts.expires_in, EXCLUDED.expires_in)
+RETURNING key, failures, locked_until, expires_in
+`
+
+type IncrementLoginAttemptsParams struct {
+	Key       string
+	ExpiresIn int64
+	Now       int64
+}
+
+func (q *Queries) IncrementLoginAttempts(ctx context.Context, arg IncrementLoginAttemptsParams) (LoginAttempt, error) {
+	row := q.db.QueryRow(ctx, incrementLoginAttempts, arg.Key, arg.ExpiresIn, arg.Now)
+	var i LoginAttempt
+	err := row.Scan(
+		&i.Key,
+		&i.Failures,
+		&i.LockedUntil,
+		&i.ExpiresIn,
+	)
+	return i, err
+}
+
+const listAttachmentKeys = `-- name: ListAttachmentKeys :many
+SELECT storage_key FROM attachments
+WHERE content_id=$1
+`
+
+func (q *Queries) ListAttachmentKeys(ctx context.Context, contentID int32) ([]string, error) {
+	rows, err := q.db.Query(ctx, listAttachmentKeys, contentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var storage_key string
+		if err := rows.Scan(&storage_key); err != nil {
+			return nil, err
+		}
+		items = append(items, storage_key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAttachments = `-- name: ListAttachments :many
+SELECT id, content_id, user_email, file_name, content_type, size, storage_key, created_at FROM attachments
+WHERE content_id=$1
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListAttachments(ctx context.Context, contentID int32) ([]Attachment, error) {
+	rows, err := q.db.Query(ctx, listAttachments, contentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Attachment
+	for rows.Next() {
+		var i Attachment
+		if err := rows.Scan(
+			&i.ID,
+			&i.ContentID,
+			&i.UserEmail,
+			&i.FileName,
+			&i.ContentType,
+			&i.Size,
+			&i.StorageKey,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBlogComments = `-- name: ListBlogComments :many
+SELECT c.id, c.blog_id, c.parent_id, c.author_email, c.body, c.status, c.is_deleted, c.created_at, c.updated_at, coalesce(u.name, '')::text AS author_name FROM comments c
+LEFT JOIN users u ON u.email = c.author_email
+WHERE c.blog_id = $1 AND c.parent_id IS NULL AND c.status = 'approved'
+  AND (NOT c.is_deleted OR EXISTS (SELECT 1 FROM comments r
+                                   WHERE r.parent_id = c.id AND r.status = 'approved' AND NOT r.is_deleted))
+ORDER BY c.created_at, c.id
+LIMIT $2 OFFSET $3
+`
+
+type ListBlogCommentsParams struct {
+	BlogID      int32
+	LimitCount  int32
+	OffsetCount int32
+}
+
+type ListBlogCommentsRow struct {
+	ID          int32
+	BlogID      int32
+	ParentID    sql.NullInt32
+	AuthorEmail sql.NullString
+	Body        string
+	Status      string
+	IsDeleted   bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	AuthorName  string
+}
+
+func (q *Queries) ListBlogComments(ctx context.Context, arg ListBlogCommentsParams) ([]ListBlogCommentsRow, error) {
+	rows, err := q.db.Query(ctx, listBlogComments, arg.BlogID, arg.LimitCount, arg.OffsetCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBlogCommentsRow
+	for rows.Next() {
+		var i ListBlogCommentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.BlogID,
+			&i.ParentID,
+			&i.AuthorEmail,
+			&i.Body,
+			&i.Status,
+			&i.IsDeleted,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.AuthorName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBlogs = `-- name: ListBlogs :many
+SELECT id, name, description, slug, status, author_email, created_at, updated_at, published_at FROM blog
+WHERE ($1::text = '' OR status = $1::text)
+  AND ($2::text = '' OR EXISTS (SELECT 1 FROM blog_tags bt JOIN tags t ON t.id = bt.tag_id
+                                           WHERE bt.blog_id = blog.id AND t.slug = $2::text))
+ORDER BY coalesce(published_at, created_at) DESC, id DESC
+LIMIT $3 OFFSET $4
+`
+
+type ListBlogsParams struct {
+	Status      string
+	Tag         string
+	LimitCount  int32
+	OffsetCount int32
+}
+
+type ListBlogsRow struct {
+	ID          int32
+	Name        sql.NullString
+	Description sql.NullString
+	Slug        string
+	Status      string
+	AuthorEmail sql.NullString
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	PublishedAt sql.NullTime
+}
+
+func (q *Queries) ListBlogs(ctx context.Context, arg ListBlogsParams) ([]ListBlogsRow, error) {
+	rows, err := q.db.Query(ctx, listBlogs,
+		arg.Status,
+		arg.Tag,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBlogsRow
+	for rows.Next() {
+		var i ListBlogsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Slug,
+			&i.Status,
+			&i.AuthorEmail,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.PublishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBlogsTags = `-- name: ListBlogsTags :many
+SELECT bt.blog_id, t.id, t.name, t.slug FROM blog_tags bt
+JOIN tags t ON t.id = bt.tag_id
+WHERE bt.blog_id = ANY($1::int[])
+ORDER BY t.name
+`
+
+type ListBlogsTagsRow struct {
+	BlogID int32
+	ID     int32
+	Name   string
+	Slug   string
+}
+
+func (q *Queries) ListBlogsTags(ctx context.Context, blogIds []int32) ([]ListBlogsTagsRow, error) {
+	rows, err := q.db.Query(ctx, listBlogsTags, blogIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBlogsTagsRow
+	for rows.Next() {
+		var i ListBlogsTagsRow
+		if err := rows.Scan(
+			&i.BlogID,
+			&i.ID,
+			&i.Name,
+			&i.Slug,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCommentReplies = `-- name: ListCommentReplies :many
+SELECT c.id, c.blog_id, c.parent_id, c.author_email, c.body, c.status, c.is_deleted, c.created_at, c.updated_at, coalesce(u.name, '')::text AS author_name FROM comments c
+LEFT JOIN users u ON u.email = c.author_email
+WHERE c.parent_id = ANY($1::int[]) AND c.status = 'approved' AND NOT c.is_deleted
+ORDER BY c.created_at, c.id
+`
+
+type ListCommentRepliesRow struct {
+	ID          int32
+	BlogID      int32
+	ParentID    sql.NullInt32
+	AuthorEmail sql.NullString
+	Body        string
+	Status      string
+	IsDeleted   bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	AuthorName  string
+}
+
+func (q *Queries) ListCommentReplies(ctx context.Context, parentIds []int32) ([]ListCommentRepliesRow, error) {
+	rows, err := q.db.Query(ctx, listCommentReplies, parentIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCommentRepliesRow
+	for rows.Next() {
+		var i ListCommentRepliesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.BlogID,
+			&i.ParentID,
+			&i.AuthorEmail,
+			&i.Body,
+			&i.Status,
+			&i.IsDeleted,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.AuthorName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCommentsByStatus = `-- name: ListCommentsByStatus :many
+SELECT id, blog_id, parent_id, author_email, body, status, is_deleted, created_at, updated_at FROM comments
+WHERE status = $1 AND NOT is_deleted
+ORDER BY created_at, id
+LIMIT $2 OFFSET $3
+`
+
+type ListCommentsByStatusParams struct {
+	Status      string
+	LimitCount  int32
+	OffsetCount int32
+}
+
+func (q *Queries) ListCommentsByStatus(ctx context.Context, arg ListCommentsByStatusParams) ([]Comment, error) {
+	rows, err := q.db.Query(ctx, listCommentsByStatus, arg.Status, arg.LimitCount, arg.OffsetCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Comment
+	for rows.Next() {
+		var i Comment
+		if err := rows.Scan(
+			&i.ID,
+			&i.BlogID,
+			&i.ParentID,
+			&i.AuthorEmail,
+			&i.Body,
+			&i.Status,
+			&i.IsDeleted,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listContent = `-- name: ListContent :many
+SELECT id, user_email, name, description, created_at, updated_at FROM content
+WHERE user_email = $1
+  AND ($2::text = '' OR name ILIKE '%' || $2::text || '%' ESCAPE '\')
+  AND ($3::int = 0
+    OR ($4::text = 'name' AND NOT $5::bool
+        AND (coalesce(name, ''), id) > ($6::text, $3::int))
+    OR ($4::text = 'name' AND $5::bool
+        AND (coalesce(name, ''), id) < ($6::text, $3::int))
+    OR ($4::text = 'created_at' AND NOT $5::bool
+        AND (created_at, id) > ($7::timestamptz, $3::int))
+    OR ($4::text = 'created_at' AND $5::bool
+        AND (created_at, id) < ($7::timestamptz, $3::int))
+    OR ($4::text = 'updated_at' AND NOT $5::bool
+        AND (updated_at, id) > ($7::timestamptz, $3::int))
+    OR ($4::text = 'updated_at' AND $5::bool
+        AND (updated_at, id) < ($7::timestamptz, $3::int)))
+ORDER BY
+    CASE WHEN $4::text = 'name' AND NOT $5::bool THEN coalesce(name, '') END,
+    CASE WHEN $4::text = 'name' AND $5::bool THEN coalesce(name, '') END DESC,
+    CASE WHEN $4::text = 'created_at' AND NOT $5::bool THEN created_at END,
+    CASE WHEN $4::text = 'created_at' AND $5::bool THEN created_at END DESC,
+    CASE WHEN $4::text = 'updated_at' AND NOT $5::bool THEN updated_at END,
+    CASE WHEN $4::text = 'updated_at' AND $5::bool THEN updated_at END DESC,
+    CASE WHEN NOT $5::bool THEN id END,
+    CASE WHEN $5::bool THEN id END DESC
+LIMIT $8
+`
+
+type ListContentParams struct {
+	UserEmail  sql.NullString
+	NameFilter string
+	CursorID   int32
+	SortBy     string
+	SortDesc   bool
+	CursorName string
+	CursorTime time.Time
+	LimitCount int32
+}
+
+type ListContentRow struct {
+	ID          int32
+	UserEmail   sql.NullString
+	Name        sql.NullString
+	Description sql.NullString
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (q *Queries) ListContent(ctx context.Context, arg ListContentParams) ([]ListContentRow, error) {
+	rows, err := q.db.Query(ctx, listContent,
+		arg.UserEmail,
+		arg.NameFilter,
+		arg.CursorID,
+		arg.SortBy,
+		arg.SortDesc,
+		arg.CursorName,
+		arg.CursorTime,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListContentRow
+	for rows.Next() {
+		var i ListContentRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserEmail,
+			&i.Name,
+			&i.Description,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listContentLinks = `-- name: ListContentLinks :many
+SELECT id, content_id, token_hash, expires_in, created_at FROM content_links
+WHERE content_id=$1 and expires_in > $2
+ORDER BY created_at, id
+`
+
+type ListContentLinksParams struct {
+	ContentID int32
+	ExpiresIn int64
+}
+
+func (q *Queries) ListContentLinks(ctx context.Context, arg ListContentLinksParams) ([]ContentLink, error) {
+	rows, err := q.db.Query(ctx, listContentLinks, arg.ContentID, arg.ExpiresIn)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ContentLink
+	for rows.Next() {
+		var i ContentLink
+		if err := rows.Scan(
+			&i.ID,
+			&i.ContentID,
+			&i.TokenHash,
+			&i.ExpiresIn,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listContentRevisions = `-- name: ListContentRevisions :many
+SELECT id, content_id, revision, author_email, name, description, summary, created_at FROM content_revisions
+WHERE content_id = $1
+ORDER BY revision DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListContentRevisionsParams struct {
+	ContentID   int32
+	LimitCount  int32
+	OffsetCount int32
+}
+
+func (q *Queries) ListContentRevisions(ctx context.Context, arg ListContentRevisionsParams) ([]ContentRevision, error) {
+	rows, err := q.db.Query(ctx, listContentRevisions, arg.ContentID, arg.LimitCount, arg.OffsetCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ContentRevision
+	for rows.Next() {
+		var i ContentRevision
+		if err := rows.Scan(
+			&i.ID,
+			&i.ContentID,
+			&i.Revision,
+			&i.AuthorEmail,
+			&i.Name,
+			&i.Description,
+			&i.Summary,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listContentShares = `-- name: ListContentShares :many
+SELECT content_id, user_email, permission, created_at FROM content_shares
+WHERE content_id=$1
+ORDER BY created_at, user_email
+`
+
+func (q *Queries) ListContentShares(ctx context.Context, contentID int32) ([]ContentShare, error) {
+	rows, err := q.db.Query(ctx, listContentShares, contentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ContentShare
+	for rows.Next() {
+		var i ContentShare
+		if err := rows.Scan(
+			&i.ContentID,
+			&i.UserEmail,
+			&i.Permission,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRolePermissions = `-- name: ListRolePermissions :many
+SELECT role, permission FROM role_permissions
+ORDER BY role, permission
+`
+
+func (q *Queries) ListRolePermissions(ctx context.Context) ([]RolePermission, error) {
+	rows, err := q.db.Query(ctx, listRolePermissions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RolePermission
+	for rows.Next() {
+		var i RolePermission
+		if err := rows.Scan(&i.Role, &i.Permission); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSessions = `-- name: ListSessions :many
+SELECT id, user_email, refresh_token, access_token, user_agent, ip, expires_in, created_at, access_expires_in FROM sessions
+WHERE user_email=$1 and expires_in > $2
+ORDER BY created_at DESC
+`
+
+type ListSessionsParams struct {
+	UserEmail sql.NullString
+	ExpiresIn int64
+}
+
+func (q *Queries) ListSessions(ctx context.Context, arg ListSessionsParams) ([]Session, error) {
+	rows, err := q.db.Query(ctx, listSessions, arg.UserEmail, arg.ExpiresIn)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Session
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserEmail,
+			&i.RefreshToken,
+			&i.AccessToken,
+			&i.UserAgent,
+			&i.Ip,
+			&i.ExpiresIn,
+			&i.CreatedAt,
+			&i.AccessExpiresIn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSharedContent = `-- name: ListSharedContent :many
+SELECT c.id, c.user_email, c.name, c.description, c.created_at, c.updated_at, s.permission FROM content_shares s
+INNER JOIN content c ON c.id = s.content_id
+WHERE s.user_email = $1
+ORDER BY s.created_at DESC, c.id DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListSharedContentParams struct {
+	UserEmail   string
+	LimitCount  int32
+	OffsetCount int32
+}
+
+type ListSharedContentRow struct {
+	ID          int32
+	UserEmail   sql.NullString
+	Name        sql.NullString
+	Description sql.NullString
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Permission  string
+}
+
+func (q *Queries) ListSharedContent(ctx context.Context, arg ListSharedContentParams) ([]ListSharedContentRow, error) {
+	rows, err := q.db.Query(ctx, listSharedContent, arg.UserEmail, arg.LimitCount, arg.OffsetCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSharedContentRow
+	for rows.Next() {
+		var i ListSharedContentRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserEmail,
+			&i.Name,
+			&i.Description,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Permission,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTags = `-- name: ListTags :many
+SELECT t.id, t.name, t.slug, count(b.id) AS posts FROM tags t
+LEFT JOIN blog_tags bt ON bt.tag_id = t.id
+LEFT JOIN blog b ON b.id = bt.blog_id AND b.status = 'published'
+GROUP BY t.id
+ORDER BY posts DESC, t.name
+`
+
+type ListTagsRow struct {
+	ID    int32
+	Name  string
+	Slug  string
+	Posts int64
+}
+
+func (q *Queries) ListTags(ctx context.Context) ([]ListTagsRow, error) {
+	rows, err := q.db.Query(ctx, listTags)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTagsRow
+	for rows.Next() {
+		var i ListTagsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Slug,
+			&i.Posts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT id, name, email, role, is_deleted, verifay FROM users
+WHERE ($1::text = '' OR email ILIKE '%' || $1::text || '%'
+    OR name ILIKE '%' || $1::text || '%')
+  AND ($2::bool OR is_deleted IS NOT TRUE)
+ORDER BY id
+LIMIT $3 OFFSET $4
+`
+
+type ListUsersParams struct {
+	Search      string
+	WithDeleted bool
+	LimitCount  int32
+	OffsetCount int32
+}
+
+type ListUsersRow struct {
+	ID        int32
 	Name      sql.NullString
+	Email     string
+	Role      string
+	IsDeleted sql.NullBool
+	Verifay   bool
 }
 
-func (q *Queries) DeleteContent(ctx context.Context, arg DeleteContentParams) error {
-	_, err := q.db.Exec(ctx, deleteContent, arg.UserEmail, arg.Name)
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error) {
+	rows, err := q.db.Query(ctx, listUsers,
+		arg.Search,
+		arg.WithDeleted,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUsersRow
+	for rows.Next() {
+		var i ListUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.Role,
+			&i.IsDeleted,
+			&i.Verifay,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockLoginAttempts = `-- name: LockLoginAttempts :exec
+UPDATE login_attempts
+SET locked_until=GREATEST(locked_until, $1), expires_in=GREATEST(expires_in, $1)
+WHERE key=$2
+`
+
+type LockLoginAttemptsParams struct {
+	LockedUntil int64
+	Key         string
+}
+
+func (q *Queries) LockLoginAttempts(ctx context.Context, arg LockLoginAttemptsParams) error {
+	_, err := q.db.Exec(ctx, lockLoginAttempts, arg.LockedUntil, arg.Key)
 	return err
 }
 
-const deleteSession = `-- name: DeleteSession :exec
-DELETE FROM sessions
-WHERE user_agent=$1 and ip=$2 and user_email=$3
+const readAttachment = `-- name: ReadAttachment :one
+SELECT id, content_id, user_email, file_name, content_type, size, storage_key, created_at FROM attachments
+WHERE id=$1 and content_id=$2
+`
+
+type ReadAttachmentParams struct {
+	ID        int32
+	ContentID int32
+}
+
+func (q *Queries) ReadAttachment(ctx context.Context, arg ReadAttachmentParams) (Attachment, error) {
+	row := q.db.QueryRow(ctx, readAttachment, arg.ID, arg.ContentID)
+	var i Attachment
+	err := row.Scan(
+		&i.ID,
+		&i.ContentID,
+		&i.UserEmail,
+		&i.FileName,
+		&i.ContentType,
+		&i.Size,
+		&i.StorageKey,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const readBlog = `-- name: ReadBlog :one
+SELECT id, name, description, slug, status, author_email, created_at, updated_at, published_at FROM blog
+WHERE id=$1
+`
+
+type ReadBlogRow struct {
+	ID          int32
+	Name        sql.NullString
+	Description sql.NullString
+	Slug        string
+	Status      string
+	AuthorEmail sql.NullString
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	PublishedAt sql.NullTime
+}
+
+func (q *Queries) ReadBlog(ctx context.Context, id int32) (ReadBlogRow, error) {
+	row := q.db.QueryRow(ctx, readBlog, id)
+	var i ReadBlogRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Slug,
+		&i.Status,
+		&i.AuthorEmail,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const readBlogBySlug = `-- name: ReadBlogBySlug :one
+SELECT id, name, description, slug, status, author_email, created_at, updated_at, published_at FROM blog
+WHERE slug=$1
+`
+
+type ReadBlogBySlugRow struct {
+	ID          int32
+	Name        sql.NullString
+	Description sql.NullString
+	Slug        string
+	Status      string
+	AuthorEmail sql.NullString
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	PublishedAt sql.NullTime
+}
+
+func (q *Queries) ReadBlogBySlug(ctx context.Context, slug string) (ReadBlogBySlugRow, error) {
+	row := q.db.QueryRow(ctx, readBlogBySlug, slug)
+	var i ReadBlogBySlugRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Slug,
+		&i.Status,
+		&i.AuthorEmail,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const readComment = `-- name: ReadComment :one
+SELECT id, blog_id, parent_id, author_email, body, status, is_deleted, created_at, updated_at FROM comments
+WHERE id=$1
+`
+
+func (q *Queries) ReadComment(ctx context.Context, id int32) (Comment, error) {
+	row := q.db.QueryRow(ctx, readComment, id)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.BlogID,
+		&i.ParentID,
+		&i.AuthorEmail,
+		&i.Body,
+		&i.Status,
+		&i.IsDeleted,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const readContent = `-- name: ReadContent :one
+SELECT c.id, c.user_email, c.name, c.description, c.created_at, c.updated_at,
+       (CASE WHEN c.user_email = $1 THEN 'owner' ELSE s.permission END)::text AS access
+FROM content c
+LEFT JOIN content_shares s ON s.content_id = c.id AND s.user_email = $1
+WHERE c.id = $2 AND (c.user_email = $1 OR s.permission IS NOT NULL)
+`
+
+type ReadContentParams struct {
+	UserEmail sql.NullString
+	ID        int32
+}
+
+type ReadContentRow struct {
+	ID          int32
+	UserEmail   sql.NullString
+	Name        sql.NullString
+	Description sql.NullString
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Access      string
+}
+
+func (q *Queries) ReadContent(ctx context.Context, arg ReadContentParams) (ReadContentRow, error) {
+	row := q.db.QueryRow(ctx, readContent, arg.UserEmail, arg.ID)
+	var i ReadContentRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserEmail,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Access,
+	)
+	return i, err
+}
+
+const readContentByLink = `-- name: ReadContentByLink :one
+SELECT c.id, c.user_email, c.name, c.description, c.created_at, c.updated_at, l.expires_in FROM content_links l
+INNER JOIN content c ON c.id = l.content_id
+WHERE l.token_hash=$1
 `
 
-type DeleteSessionParams struct {
-	UserAgent sql.NullString
-	Ip        sql.NullString
-	UserEmail sql.NullString
+type ReadContentByLinkRow struct {
+	ID          int32
+	UserEmail   sql.NullString
+	Name        sql.NullString
+	Description sql.NullString
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ExpiresIn   int64
 }
 
-func (q *Queries) DeleteSession(ctx context.Context, arg DeleteSessionParams) error {
-	_, err := q.db.Exec(ctx, deleteSession, arg.UserAgent, arg.Ip, arg.UserEmail)
-	return err
+func (q *Queries) ReadContentByLink(ctx context.Context, tokenHash string) (ReadContentByLinkRow, error) {
+	row := q.db.QueryRow(ctx, readContentByLink, tokenHash)
+	var i ReadContentByLinkRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserEmail,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresIn,
+	)
+	return i, err
 }
 
-const deleteUser = `-- name: DeleteUser :exec
-UPDATE users SET is_deleted=true
-WHERE email = $1
+const readContentForUpdate = `-- name: ReadContentForUpdate :one
+SELECT id, name, description FROM content
+WHERE id=$1
+FOR UPDATE
 `
 
-func (q *Queries) DeleteUser(ctx context.Context, email string) error {
-	_, err := q.db.Exec(ctx, deleteUser, email)
-	return err
+type ReadContentForUpdateRow struct {
+	ID          int32
+	Name        sql.NullString
+	Description sql.NullString
 }
 
-const readBlog = `-- name: ReadBlog :one
-SELECT id, name, description FROM blog
-WHERE id=$1
-`
-
-func (q *Queries) ReadBlog(ctx context.Context, id int32) (Blog, error) {
-	row := q.db.QueryRow(ctx, readBlog, id)
-	var i Blog
+func (q *Queries) ReadContentForUpdate(ctx context.Context, id int32) (ReadContentForUpdateRow, error) {
+	row := q.db.QueryRow(ctx, readContentForUpdate, id)
+	var i ReadContentForUpdateRow
 	err := row.Scan(&i.ID, &i.Name, &i.Description)
 	return i, err
 }
 
-const readContent = `-- name: ReadContent :one
-SELECT id, user_email, name, description FROM content
-WHERE user_email=$1 and id=$2
+const readContentRevision = `-- name: ReadContentRevision :one
+SELECT id, content_id, revision, author_email, name, description, summary, created_at FROM content_revisions
+WHERE content_id=$1 and revision=$2
 `
 
-type ReadContentParams struct {
-	UserEmail sql.NullString
-	ID        int32
+type ReadContentRevisionParams struct {
+	ContentID int32
+	Revision  int32
 }
 
-func (q *Queries) ReadContent(ctx context.Context, arg ReadContentParams) (Content, error) {
-	row := q.db.QueryRow(ctx, readContent, arg.UserEmail, arg.ID)
-	var i Content
+func (q *Queries) ReadContentRevision(ctx context.Context, arg ReadContentRevisionParams) (ContentRevision, error) {
+	row := q.db.QueryRow(ctx, readContentRevision, arg.ContentID, arg.Revision)
+	var i ContentRevision
 	err := row.Scan(
 		&i.ID,
-		&i.UserEmail,
+		&i.ContentID,
+		&i.Revision,
+		&i.AuthorEmail,
 		&i.Name,
 		&i.Description,
+		&i.Summary,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
 const readEmailRoleFromSessions = `-- name: ReadEmailRoleFromSessions :one
-SELECT email, role, s.expires_in
+SELECT users.id as user_id, email, role, s.expires_in, s.id as session_id, s.access_token, s.access_expires_in
 FROM users INNER JOIN sessions s on users.email = s.user_email
 WHERE s.refresh_token = $1
 `
 
 type ReadEmailRoleFromSessionsRow struct {
-	Email     string
-	Role      string
-	ExpiresIn int64
+	UserID          int32
+	Email           string
+	Role            string
+	ExpiresIn       int64
+	SessionID       int32
+	AccessToken     sql.NullString
+	AccessExpiresIn int64
 }
 
 func (q *Queries) ReadEmailRoleFromSessions(ctx context.Context, refreshToken sql.NullString) (ReadEmailRoleFromSessionsRow, error) {
 	row := q.db.QueryRow(ctx, readEmailRoleFromSessions, refreshToken)
 	var i ReadEmailRoleFromSessionsRow
-	err := row.Scan(&i.Email, &i.Role, &i.ExpiresIn)
+	err := row.Scan(
+		&i.UserID,
+		&i.Email,
+		&i.Role,
+		&i.ExpiresIn,
+		&i.SessionID,
+		&i.AccessToken,
+		&i.AccessExpiresIn,
+	)
+	return i, err
+}
+
+const readLoginAttempts = `-- name: ReadLoginAttempts :one
+SELECT key, failures, locked_until, expires_in FROM login_attempts
+WHERE key=$1 and expires_in > $2
+`
+
+type ReadLoginAttemptsParams struct {
+	Key       string
+	ExpiresIn int64
+}
+
+func (q *Queries) ReadLoginAttempts(ctx context.Context, arg ReadLoginAttemptsParams) (LoginAttempt, error) {
+	row := q.db.QueryRow(ctx, readLoginAttempts, arg.Key, arg.ExpiresIn)
+	var i LoginAttempt
+	err := row.Scan(
+		&i.Key,
+		&i.Failures,
+		&i.LockedUntil,
+		&i.ExpiresIn,
+	)
+	return i, err
+}
+
+const readRetiredRefreshToken = `-- name: ReadRetiredRefreshToken :one
+SELECT token, session_id, user_email, retired_at FROM retired_refresh_tokens
+WHERE token=$1
+`
+
+func (q *Queries) ReadRetiredRefreshToken(ctx context.Context, token string) (RetiredRefreshToken, error) {
+	row := q.db.QueryRow(ctx, readRetiredRefreshToken, token)
+	var i RetiredRefreshToken
+	err := row.Scan(
+		&i.Token,
+		&i.SessionID,
+		&i.UserEmail,
+		&i.RetiredAt,
+	)
 	return i, err
 }
 
 const readSession = `-- name: ReadSession :one
-SELECT id, user_email, refresh_token, access_token, user_agent, ip, expires_in, created_at FROM sessions
+SELECT id, user_email, refresh_token, access_token, user_agent, ip, expires_in, created_at, access_expires_in FROM sessions
 WHERE user_email=$1 and  user_agent=$2 and ip=$3
 `
 
@@ -206,6 +1778,7 @@ func (q *Queries) ReadSession(ctx context.Context, arg ReadSessionParams) (Sessi
 		&i.Ip,
 		&i.ExpiresIn,
 		&i.CreatedAt,
+		&i.AccessExpiresIn,
 	)
 	return i, err
 }
@@ -230,26 +1803,371 @@ func (q *Queries) ReadUser(ctx context.Context, email string) (User, error) {
 	return i, err
 }
 
-const updateContent = `-- name: UpdateContent :exec
-UPDATE content SET user_email=$1, name=$3, description=$4
-WHERE user_email= $1 and name =$2
+const readUserByID = `-- name: ReadUserByID :one
+SELECT id, name, email, role, is_deleted, verifay FROM users
+WHERE id = $1
+`
+
+type ReadUserByIDRow struct {
+	ID        int32
+	Name      sql.NullString
+	Email     string
+	Role      string
+	IsDeleted sql.NullBool
+	Verifay   bool
+}
+
+func (q *Queries) ReadUserByID(ctx context.Context, id int32) (ReadUserByIDRow, error) {
+	row := q.db.QueryRow(ctx, readUserByID, id)
+	var i ReadUserByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Role,
+		&i.IsDeleted,
+		&i.Verifay,
+	)
+	return i, err
+}
+
+const restoreUser = `-- name: RestoreUser :execrows
+UPDATE users SET is_deleted=false
+WHERE id = $1
+`
+
+func (q *Queries) RestoreUser(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const revokePasswordResetTokens = `-- name: RevokePasswordResetTokens :exec
+UPDATE password_reset_tokens SET used_at=now()
+WHERE user_email=$1 and used_at IS NULL
+`
+
+func (q *Queries) RevokePasswordResetTokens(ctx context.Context, userEmail string) error {
+	_, err := q.db.Exec(ctx, revokePasswordResetTokens, userEmail)
+	return err
+}
+
+const searchBlogs = `-- name: SearchBlogs :many
+SELECT id, name, description, slug, status, author_email, created_at, updated_at, published_at,
+       ts_rank(search_vector, query)::real AS rank,
+       ts_headline('russian', coalesce(description, ''), query,
+                   'StartSel=' || chr(1) || ', StopSel=' || chr(2) || ', MaxFragments=2, MaxWords=25, MinWords=10')::text AS snippet
+FROM blog, websearch_to_tsquery('russian', $1) query
+WHERE status = 'published' AND search_vector @@ query
+ORDER BY rank DESC, id DESC
+LIMIT $2 OFFSET $3
+`
+
+type SearchBlogsParams struct {
+	Query       string
+	LimitCount  int32
+	OffsetCount int32
+}
+
+type SearchBlogsRow struct {
+	ID          int32
+	Name        sql.NullString
+	Description sql.NullString
+	Slug        string
+	Status      string
+	AuthorEmail sql.NullString
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	PublishedAt sql.NullTime
+	Rank        float32
+	Snippet     string
+}
+
+func (q *Queries) SearchBlogs(ctx context.Context, arg SearchBlogsParams) ([]SearchBlogsRow, error) {
+	rows, err := q.db.Query(ctx, searchBlogs, arg.Query, arg.LimitCount, arg.OffsetCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchBlogsRow
+	for rows.Next() {
+		var i SearchBlogsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Slug,
+			&i.Status,
+			&i.AuthorEmail,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.PublishedAt,
+			&i.Rank,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchContent = `-- name: SearchContent :many
+SELECT id, user_email, name, description, created_at, updated_at,
+       ts_rank(search_vector, query)::real AS rank,
+       ts_headline('russian', coalesce(description, ''), query,
+                   'StartSel=' || chr(1) || ', StopSel=' || chr(2) || ', MaxFragments=2, MaxWords=25, MinWords=10')::text AS snippet
+FROM content, websearch_to_tsquery('russian', $1) query
+WHERE user_email = $2 AND search_vector @@ query
+ORDER BY rank DESC, id DESC
+LIMIT $3 OFFSET $4
+`
+
+type SearchContentParams struct {
+	Query       string
+	UserEmail   sql.NullString
+	LimitCount  int32
+	OffsetCount int32
+}
+
+type SearchContentRow struct {
+	ID          int32
+	UserEmail   sql.NullString
+	Name        sql.NullString
+	Description sql.NullString
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Rank        float32
+	Snippet     string
+}
+
+func (q *Queries) SearchContent(ctx context.Context, arg SearchContentParams) ([]SearchContentRow, error) {
+	rows, err := q.db.Query(ctx, searchContent,
+		arg.Query,
+		arg.UserEmail,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchContentRow
+	for rows.Next() {
+		var i SearchContentRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserEmail,
+			&i.Name,
+			&i.Description,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Rank,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateBlog = `-- name: UpdateBlog :one
+UPDATE blog SET name=$2, description=$3, slug=$4, updated_at=now()
+WHERE id=$1
+RETURNING id, name, description, slug, status, author_email, created_at, updated_at, published_at
+`
+
+type UpdateBlogParams struct {
+	ID          int32
+	Name        sql.NullString
+	Description sql.NullString
+	Slug        string
+}
+
+type UpdateBlogRow struct {
+	ID          int32
+	Name        sql.NullString
+	Description sql.NullString
+	Slug        string
+	Status      string
+	AuthorEmail sql.NullString
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	PublishedAt sql.NullTime
+}
+
+func (q *Queries) UpdateBlog(ctx context.Context, arg UpdateBlogParams) (UpdateBlogRow, error) {
+	row := q.db.QueryRow(ctx, updateBlog,
+		arg.ID,
+		arg.Name,
+		arg.Description,
+		arg.Slug,
+	)
+	var i UpdateBlogRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Slug,
+		&i.Status,
+		&i.AuthorEmail,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const updateBlogStatus = `-- name: UpdateBlogStatus :one
+UPDATE blog SET status=$1, updated_at=now(),
+    published_at = CASE WHEN $1 = 'published' THEN coalesce(published_at, now())
+                        ELSE published_at END
+WHERE id=$2
+RETURNING id, name, description, slug, status, author_email, created_at, updated_at, published_at
+`
+
+type UpdateBlogStatusParams struct {
+	Status string
+	ID     int32
+}
+
+type UpdateBlogStatusRow struct {
+	ID          int32
+	Name        sql.NullString
+	Description sql.NullString
+	Slug        string
+	Status      string
+	AuthorEmail sql.NullString
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	PublishedAt sql.NullTime
+}
+
+func (q *Queries) UpdateBlogStatus(ctx context.Context, arg UpdateBlogStatusParams) (UpdateBlogStatusRow, error) {
+	row := q.db.QueryRow(ctx, updateBlogStatus, arg.Status, arg.ID)
+	var i UpdateBlogStatusRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Slug,
+		&i.Status,
+		&i.AuthorEmail,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const updateCommentBody = `-- name: UpdateCommentBody :one
+UPDATE comments SET body=$3, updated_at=now(),
+    status = CASE WHEN status = 'hidden' THEN status ELSE 'pending' END
+WHERE id=$1 AND author_email=$2 AND NOT is_deleted
+RETURNING id, blog_id, parent_id, author_email, body, status, is_deleted, created_at, updated_at
+`
+
+type UpdateCommentBodyParams struct {
+	ID          int32
+	AuthorEmail sql.NullString
+	Body        string
+}
+
+func (q *Queries) UpdateCommentBody(ctx context.Context, arg UpdateCommentBodyParams) (Comment, error) {
+	row := q.db.QueryRow(ctx, updateCommentBody, arg.ID, arg.AuthorEmail, arg.Body)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.BlogID,
+		&i.ParentID,
+		&i.AuthorEmail,
+		&i.Body,
+		&i.Status,
+		&i.IsDeleted,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateCommentStatus = `-- name: UpdateCommentStatus :one
+UPDATE comments SET status=$2, updated_at=now()
+WHERE id=$1 AND NOT is_deleted
+RETURNING id, blog_id, parent_id, author_email, body, status, is_deleted, created_at, updated_at
+`
+
+type UpdateCommentStatusParams struct {
+	ID     int32
+	Status string
+}
+
+func (q *Queries) UpdateCommentStatus(ctx context.Context, arg UpdateCommentStatusParams) (Comment, error) {
+	row := q.db.QueryRow(ctx, updateCommentStatus, arg.ID, arg.Status)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.BlogID,
+		&i.ParentID,
+		&i.AuthorEmail,
+		&i.Body,
+		&i.Status,
+		&i.IsDeleted,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateContent = `-- name: UpdateContent :one
+UPDATE content SET name=$3, description=$4, updated_at=now()
+WHERE id=$2 and (user_email=$1 OR EXISTS (SELECT 1 FROM content_shares s
+                                         WHERE s.content_id = content.id AND s.user_email = $1
+                                           AND s.permission = 'edit'))
+RETURNING id, user_email, name, description, created_at, updated_at
 `
 
 type UpdateContentParams struct {
+	UserEmail   sql.NullString
+	ID          int32
+	Name        sql.NullString
+	Description sql.NullString
+}
+
+type UpdateContentRow struct {
+	ID          int32
 	UserEmail   sql.NullString
 	Name        sql.NullString
-	Name_2      sql.NullString
 	Description sql.NullString
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 }
 
-func (q *Queries) UpdateContent(ctx context.Context, arg UpdateContentParams) error {
-	_, err := q.db.Exec(ctx, updateContent,
+func (q *Queries) UpdateContent(ctx context.Context, arg UpdateContentParams) (UpdateContentRow, error) {
+	row := q.db.QueryRow(ctx, updateContent,
 		arg.UserEmail,
+		arg.ID,
 		arg.Name,
-		arg.Name_2,
 		arg.Description,
 	)
-	return err
+	var i UpdateContentRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserEmail,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
 }
 
 const updateSession = `-- name: UpdateSession :exec
@@ -274,7 +2192,23 @@ func (q *Queries) UpdateSession(ctx context.Context, arg UpdateSessionParams) er
 	return err
 }
 
-const updateSessionTokenOnly = `-- name: UpdateSessionTokenOnly :exec
+const updateSessionAccessToken = `-- name: UpdateSessionAccessToken :exec
+UPDATE sessions SET access_token=$2, access_expires_in=$3
+WHERE id=$1
+`
+
+type UpdateSessionAccessTokenParams struct {
+	ID              int32
+	AccessToken     sql.NullString
+	AccessExpiresIn int64
+}
+
+func (q *Queries) UpdateSessionAccessToken(ctx context.Context, arg UpdateSessionAccessTokenParams) error {
+	_, err := q.db.Exec(ctx, updateSessionAccessToken, arg.ID, arg.AccessToken, arg.AccessExpiresIn)
+	return err
+}
+
+const updateSessionTokenOnly = `-- name: UpdateSessionTokenOnly :execrows
 UPDATE sessions SET refresh_token=$2, expires_in=$3, created_at=DEFAULT
 WHERE refresh_token=$1
 `
@@ -285,9 +2219,12 @@ type UpdateSessionTokenOnlyParams struct {
 	ExpiresIn      int64
 }
 
-func (q *Queries) UpdateSessionTokenOnly(ctx context.Context, arg UpdateSessionTokenOnlyParams) error {
-	_, err := q.db.Exec(ctx, updateSessionTokenOnly, arg.RefreshToken, arg.RefreshToken_2, arg.ExpiresIn)
-	return err
+func (q *Queries) UpdateSessionTokenOnly(ctx context.Context, arg UpdateSessionTokenOnlyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateSessionTokenOnly, arg.RefreshToken, arg.RefreshToken_2, arg.ExpiresIn)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const updateUser = `-- name: UpdateUser :exec
@@ -313,3 +2250,127 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
 	)
 	return err
 }
+
+const updateUserPassword = `-- name: UpdateUserPassword :execrows
+UPDATE users SET password=$2
+WHERE email = $1 and is_deleted IS NOT TRUE
+`
+
+type UpdateUserPasswordParams struct {
+	Email    string
+	Password string
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateUserPassword, arg.Email, arg.Password)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users SET role=$2
+WHERE id = $1
+RETURNING email
+`
+
+type UpdateUserRoleParams struct {
+	ID   int32
+	Role string
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (string, error) {
+	row := q.db.QueryRow(ctx, updateUserRole, arg.ID, arg.Role)
+	var email string
+	err := row.Scan(&email)
+	return email, err
+}
+
+const upsertContentShare = `-- name: UpsertContentShare :one
+INSERT INTO content_shares(content_id, user_email, permission)
+VALUES ($1, $2, $3)
+ON CONFLICT (content_id, user_email) DO UPDATE SET permission = excluded.permission
+RETURNING content_id, user_email, permission, created_at
+`
+
+type UpsertContentShareParams struct {
+	ContentID  int32
+	UserEmail  string
+	Permission string
+}
+
+func (q *Queries) UpsertContentShare(ctx context.Context, arg UpsertContentShareParams) (ContentShare, error) {
+	row := q.db.QueryRow(ctx, upsertContentShare, arg.ContentID, arg.UserEmail, arg.Permission)
+	var i ContentShare
+	err := row.Scan(
+		&i.ContentID,
+		&i.UserEmail,
+		&i.Permission,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const upsertTags = `-- name: UpsertTags :many
+INSERT INTO tags(name, slug)
+SELECT unnest($1::text[]), unnest($2::text[])
+ON CONFLICT (slug) DO UPDATE SET slug = excluded.slug
+RETURNING id, name, slug
+`
+
+type UpsertTagsParams struct {
+	Names []string
+	Slugs []string
+}
+
+func (q *Queries) UpsertTags(ctx context.Context, arg UpsertTagsParams) ([]Tag, error) {
+	rows, err := q.db.Query(ctx, upsertTags, arg.Names, arg.Slugs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Tag
+	for rows.Next() {
+		var i Tag
+		if err := rows.Scan(&i.ID, &i.Name, &i.Slug); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const usePasswordResetToken = `-- name: UsePasswordResetToken :one
+UPDATE password_reset_tokens SET used_at=now()
+WHERE token_hash=$1 and used_at IS NULL and expires_in > $2
+RETURNING user_email
+`
+
+type UsePasswordResetTokenParams struct {
+	TokenHash string
+	ExpiresIn int64
+}
+
+func (q *Queries) UsePasswordResetToken(ctx context.Context, arg UsePasswordResetTokenParams) (string, error) {
+	row := q.db.QueryRow(ctx, usePasswordResetToken, arg.TokenHash, arg.ExpiresIn)
+	var user_email string
+	err := row.Scan(&user_email)
+	return user_email, err
+}
+
+const verifyUser = `-- name: VerifyUser :execrows
+UPDATE users SET verifay=true
+WHERE email = $1 and is_deleted IS NOT TRUE
+`
+
+func (q *Queries) VerifyUser(ctx context.Context, email string) (int64, error) {
+	result, err := q.db.Exec(ctx, verifyUser, email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Dsmit05/metida/pkg/diff"
+)
+
+// Summaries of revisions written without comparing.
+const (
+	revisionCreated  = "created"
+	revisionRestored = "restored revision %d: "
+)
+
+// revisionSummary describes changes of the content for the history, e.g. "name changed, description +2 -1 lines".
+func revisionSummary(oldName, oldDescription, name, description string) string {
+	changes := make([]string, 0, 2)
+
+	if oldName != name {
+		changes = append(changes, "name changed")
+	}
+
+	inserted, deleted := diff.Stat(diff.Lines(oldDescription, description))
+	if inserted != 0 || deleted != 0 {
+		changes = append(changes, fmt.Sprintf("description +%d -%d lines", inserted, deleted))
+	}
+
+	if len(changes) == 0 {
+		return "no changes"
+	}
+
+	return strings.Join(changes, ", ")
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import "testing"
+
+func TestRevisionSummary(t *testing.T) {
+	var tests = []struct {
+		name           string
+		oldName        string
+		oldDescription string
+		newName        string
+		newDescription string
+		want           string
+	}{
+		{name: "Case-1: nothing changed",
+			oldName: "a", oldDescription: "text", newName: "a", newDescription: "text",
+			want: "no changes",
+		},
+		{name: "Case-2: only name",
+			oldName: "a", oldDescription: "text", newName: "b", newDescription: "text",
+			want: "name changed",
+		},
+		{name: "Case-3: line of description is changed",
+			oldName: "a", oldDescription: "one\ntwo", newName: "a", newDescription: "one\n2\nthree",
+			want: "description +2 -1 lines",
+		},
+		{name: "Case-4: both",
+			oldName: "a", oldDescription: "", newName: "b", newDescription: "one",
+			want: "name changed, description +1 -0 lines",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := revisionSummary(tt.oldName, tt.oldDescription, tt.newName, tt.newDescription)
+			if got != tt.want {
+				t.Errorf("revisionSummary() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"html"
+	"strings"
+)
+
+// Markers of found words, ts_headline puts them into the snippet instead of html tags,
+// so the text can be escaped before the tags are added.
+const (
+	highlightStart = "\x01"
+	highlightStop  = "\x02"
+)
+
+var highlightReplacer = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+
+// highlightSnippet escapes the snippet and marks found words with <mark> tags.
+func highlightSnippet(snippet string) string {
+	return highlightReplacer.Replace(html.EscapeString(snippet))
+}
+
+// likeReplacer escapes wildcards of LIKE, queries use ESCAPE '\'.
+var likeReplacer = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike makes the filter match literally, so % and _ typed by the user are not wildcards.
+func escapeLike(filter string) string {
+	return likeReplacer.Replace(filter)
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import "testing"
+
+func TestHighlightSnippet(t *testing.T) {
+	var tests = []struct {
+		name    string
+		snippet string
+		want    string
+	}{
+		{name: "Case-1: found words are marked",
+			snippet: "first \x01post\x02 of the \x01blog\x02",
+			want:    "first <mark>post</mark> of the <mark>blog</mark>",
+		},
+		{name: "Case-2: html of the text is escaped",
+			snippet: "<script>\x01alert\x02</script>",
+			want:    "&lt;script&gt;<mark>alert</mark>&lt;/script&gt;",
+		},
+		{name: "Case-3: nothing found",
+			snippet: "plain text",
+			want:    "plain text",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlightSnippet(tt.snippet); got != tt.want {
+				t.Errorf("highlightSnippet() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	var tests = []struct {
+		name   string
+		filter string
+		want   string
+	}{
+		{name: "Case-1: plain text", filter: "notes", want: "notes"},
+		{name: "Case-2: wildcards", filter: "100%_done", want: `100\%\_done`},
+		{name: "Case-3: escape symbol", filter: `a\b`, want: `a\\b`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeLike(tt.filter); got != tt.want {
+				t.Errorf("escapeLike() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Dsmit05/metida/internal/logger"
+	"github.com/Dsmit05/metida/internal/repositories/postgres"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	txMaxAttempts = 3
+	txRetryDelay  = 20 * time.Millisecond
+)
+
+type txBeginnerI interface {
+	BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error)
+}
+
+// txKey keeps the transaction of WithinTx in the context.
+type txKey struct{}
+
+// txConn is the transaction which remembers that the database asked to repeat it.
+type txConn struct {
+	pgx.Tx
+	retry bool
+}
+
+func (o *txConn) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	tag, err := o.Tx.Exec(ctx, sql, args...)
+	o.check(err)
+
+	return tag, err
+}
+
+func (o *txConn) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	rows, err := o.Tx.Query(ctx, sql, args...)
+	o.check(err)
+
+	if err != nil {
+		return rows, err
+	}
+
+	return &txRows{Rows: rows, conn: o}, nil
+}
+
+func (o *txConn) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return &txRow{row: o.Tx.QueryRow(ctx, sql, args...), conn: o}
+}
+
+func (o *txConn) check(err error) {
+	if isRetryable(err) {
+		o.retry = true
+	}
+}
+
+type txRows struct {
+	pgx.Rows
+	conn *txConn
+}
+
+func (o *txRows) Err() error {
+	err := o.Rows.Err()
+	o.conn.check(err)
+
+	return err
+}
+
+type txRow struct {
+	row  pgx.Row
+	conn *txConn
+}
+
+func (o *txRow) Scan(dest ...interface{}) error {
+	err := o.row.Scan(dest...)
+	o.conn.check(err)
+
+	return err
+}
+
+// WithinTx runs fn in one transaction: repository methods called with the ctx of fn use it.
+// The transaction is committed when fn returns nil and rolled back otherwise.
+// On serialization failure or deadlock fn is repeated, so it must not have side effects
+// outside the database. Nested calls join the outer transaction.
+// The transaction is READ COMMITTED, so a statement sees rows committed by others meanwhile.
+func (o *PostgresRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withinTx(ctx, o.pool, pgx.TxOptions{}, fn)
+}
+
+// WithinSerializableTx is WithinTx with SERIALIZABLE isolation: fn works as if it were alone,
+// concurrent transactions changing the same rows make it repeat. A nested call joins the outer
+// transaction with its isolation.
+func (o *PostgresRepository) WithinSerializableTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withinTx(ctx, o.pool, pgx.TxOptions{IsoLevel: pgx.Serializable}, fn)
+}
+
+// q returns queries bound to the transaction of WithinTx, if ctx has one.
+func (o *PostgresRepository) q(ctx context.Context) *postgres.Queries {
+	if conn, ok := ctx.Value(txKey{}).(*txConn); ok {
+		return o.queries.WithTx(conn)
+	}
+
+	return o.queries
+}
+
+func withinTx(ctx context.Context, db txBeginnerI, opts pgx.TxOptions, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*txConn); ok {
+		return fn(ctx)
+	}
+
+	var err error
+	for attempt := 0; attempt < txMaxAttempts; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, txRetryDelay<<(attempt-1)); err != nil {
+				return err
+			}
+		}
+
+		var retry bool
+		retry, err = runTx(ctx, db, opts, fn)
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if !retry {
+			return err
+		}
+	}
+
+	logger.DatabaseError("repositories.withinTx", err, txMaxAttempts)
+
+	return otherError(err)
+}
+
+// runTx makes one attempt of the transaction, retry reports that the database asked to repeat it.
+func runTx(
+	ctx context.Context, db txBeginnerI, opts pgx.TxOptions, fn func(ctx context.Context) error) (retry bool, err error) {
+	if err = ctx.Err(); err != nil {
+		return false, err
+	}
+
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		logger.DatabaseError("pool.BeginTx", err, nil)
+		return false, otherError(err)
+	}
+	defer rollback(ctx, tx)
+
+	conn := &txConn{Tx: tx}
+	if err = fn(context.WithValue(ctx, txKey{}, conn)); err != nil {
+		return conn.retry, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		if isRetryable(err) {
+			return true, err
+		}
+
+		logger.DatabaseError("tx.Commit", err, nil)
+		return false, otherError(err)
+	}
+
+	return false, nil
+}
+
+// rollback of the committed transaction does nothing.
+func rollback(ctx context.Context, tx pgx.Tx) {
+	err := tx.Rollback(ctx)
+	if err != nil && !errors.Is(err, pgx.ErrTxClosed) && ctx.Err() == nil {
+		logger.DatabaseError("tx.Rollback", err, nil)
+	}
+}
+
+// isRetryable the transaction failed only because of concurrent transactions.
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	return pgErr.Code == pgerrcode.SerializationFailure || pgErr.Code == pgerrcode.DeadlockDetected
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
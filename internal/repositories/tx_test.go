@@ -0,0 +1,224 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Dsmit05/metida/internal/logger"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+)
+
+type fakeTx struct {
+	pgx.Tx
+	db        *fakeBeginner
+	committed bool
+}
+
+func (o *fakeTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	if len(o.db.execErrs) == 0 {
+		return nil, nil
+	}
+
+	err := o.db.execErrs[0]
+	o.db.execErrs = o.db.execErrs[1:]
+
+	return nil, err
+}
+
+func (o *fakeTx) Commit(ctx context.Context) error {
+	if len(o.db.commitErrs) > 0 {
+		err := o.db.commitErrs[0]
+		o.db.commitErrs = o.db.commitErrs[1:]
+
+		if err != nil {
+			return err
+		}
+	}
+
+	o.committed = true
+	o.db.commits++
+
+	return nil
+}
+
+func (o *fakeTx) Rollback(ctx context.Context) error {
+	if o.committed {
+		return pgx.ErrTxClosed
+	}
+
+	o.db.rollbacks++
+
+	return nil
+}
+
+type fakeBeginner struct {
+	execErrs   []error
+	commitErrs []error
+	begins     int
+	commits    int
+	rollbacks  int
+	opts       pgx.TxOptions
+}
+
+func (o *fakeBeginner) BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	o.begins++
+	o.opts = opts
+	return &fakeTx{db: o}, nil
+}
+
+// exec runs a statement in the transaction of ctx, as the repository methods do.
+func exec(ctx context.Context) error {
+	_, err := ctx.Value(txKey{}).(*txConn).Exec(ctx, "SELECT 1")
+	if err != nil {
+		return errOther
+	}
+
+	return nil
+}
+
+func TestWithinTx(t *testing.T) {
+	logger.ZapLog = zap.NewNop()
+
+	serialization := &pgconn.PgError{Code: pgerrcode.SerializationFailure}
+	deadlock := &pgconn.PgError{Code: pgerrcode.DeadlockDetected}
+	unique := &pgconn.PgError{Code: pgerrcode.UniqueViolation}
+
+	var tests = []struct {
+		name          string
+		db            *fakeBeginner
+		fn            func(ctx context.Context) error
+		wantErr       error
+		wantBegins    int
+		wantCommits   int
+		wantRollbacks int
+	}{
+		{
+			name:        "Case-1: commit",
+			db:          &fakeBeginner{},
+			fn:          exec,
+			wantBegins:  1,
+			wantCommits: 1,
+		},
+		{
+			name:          "Case-2: error of fn rolls back",
+			db:            &fakeBeginner{},
+			fn:            func(ctx context.Context) error { return errUserIsExist },
+			wantErr:       errUserIsExist,
+			wantBegins:    1,
+			wantRollbacks: 1,
+		},
+		{
+			name:          "Case-3: not retryable error of query",
+			db:            &fakeBeginner{execErrs: []error{unique}},
+			fn:            exec,
+			wantErr:       errOther,
+			wantBegins:    1,
+			wantRollbacks: 1,
+		},
+		{
+			name:          "Case-4: serialization failure is retried",
+			db:            &fakeBeginner{execErrs: []error{serialization}},
+			fn:            exec,
+			wantBegins:    2,
+			wantCommits:   1,
+			wantRollbacks: 1,
+		},
+		{
+			name:          "Case-5: deadlock on commit is retried",
+			db:            &fakeBeginner{commitErrs: []error{deadlock}},
+			fn:            exec,
+			wantBegins:    2,
+			wantCommits:   1,
+			wantRollbacks: 1,
+		},
+		{
+			name:          "Case-6: retries are limited",
+			db:            &fakeBeginner{execErrs: []error{serialization, serialization, serialization}},
+			fn:            exec,
+			wantErr:       errOther,
+			wantBegins:    txMaxAttempts,
+			wantRollbacks: txMaxAttempts,
+		},
+		{
+			name: "Case-7: nested call joins the transaction",
+			db:   &fakeBeginner{},
+			fn: func(ctx context.Context) error {
+				return withinTx(ctx, nil, pgx.TxOptions{}, exec)
+			},
+			wantBegins:  1,
+			wantCommits: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := withinTx(context.Background(), tt.db, pgx.TxOptions{}, tt.fn)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("withinTx() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.db.begins != tt.wantBegins || tt.db.commits != tt.wantCommits || tt.db.rollbacks != tt.wantRollbacks {
+				t.Errorf("withinTx() begins = %v, commits = %v, rollbacks = %v, want %v, %v, %v",
+					tt.db.begins, tt.db.commits, tt.db.rollbacks, tt.wantBegins, tt.wantCommits, tt.wantRollbacks)
+			}
+		})
+	}
+}
+
+func TestWithinTxCanceled(t *testing.T) {
+	logger.ZapLog = zap.NewNop()
+
+	t.Run("Case-1: canceled before start", func(t *testing.T) {
+		db := &fakeBeginner{}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if err := withinTx(ctx, db, pgx.TxOptions{}, exec); !errors.Is(err, context.Canceled) {
+			t.Errorf("withinTx() error = %v, want %v", err, context.Canceled)
+		}
+		if db.begins != 0 {
+			t.Errorf("withinTx() begins = %v, want 0", db.begins)
+		}
+	})
+
+	t.Run("Case-2: canceled inside fn is not retried", func(t *testing.T) {
+		db := &fakeBeginner{execErrs: []error{&pgconn.PgError{Code: pgerrcode.SerializationFailure}}}
+		ctx, cancel := context.WithCancel(context.Background())
+
+		err := withinTx(ctx, db, pgx.TxOptions{}, func(ctx context.Context) error {
+			cancel()
+			return exec(ctx)
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("withinTx() error = %v, want %v", err, context.Canceled)
+		}
+		if db.begins != 1 || db.commits != 0 {
+			t.Errorf("withinTx() begins = %v, commits = %v, want 1, 0", db.begins, db.commits)
+		}
+	})
+}
+
+func TestWithinTxIsolation(t *testing.T) {
+	logger.ZapLog = zap.NewNop()
+
+	var tests = []struct {
+		name string
+		opts pgx.TxOptions
+	}{
+		{name: "Case-1: default read committed", opts: pgx.TxOptions{}},
+		{name: "Case-2: serializable", opts: pgx.TxOptions{IsoLevel: pgx.Serializable}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeBeginner{}
+			if err := withinTx(context.Background(), db, tt.opts, exec); err != nil {
+				t.Fatalf("withinTx() error = %v", err)
+			}
+			if db.opts != tt.opts {
+				t.Errorf("withinTx() opts = %+v, want %+v", db.opts, tt.opts)
+			}
+		})
+	}
+}
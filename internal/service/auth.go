@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/Dsmit05/metida/internal/apperr"
+	"github.com/Dsmit05/metida/internal/consts"
+	"github.com/Dsmit05/metida/internal/cryptography"
+	"github.com/Dsmit05/metida/internal/logger"
+	"github.com/Dsmit05/metida/internal/mail"
+	"github.com/Dsmit05/metida/internal/models"
+	"github.com/Dsmit05/metida/internal/validation"
+)
+
+var (
+	// errWrongCredentials is the same for unknown email and wrong password, so emails can't be enumerated.
+	errWrongCredentials  = errors.New("wrong email or password")
+	errTooManyAttempts   = errors.New("too many failed attempts")
+	errEmailNotVerified  = errors.New("email is not verified")
+	errRefreshExpired    = errors.New("refresh token is expired")
+	errBadVerifyToken    = errors.New("bad token")
+	errInvalidVerifyLink = errors.New("link is invalid or expired")
+)
+
+// maxVerifyTokenSize longer tokens are not parsed.
+const maxVerifyTokenSize = 500
+
+// dummyPasswordHash is checked when the user is not found, so the answer takes the same time.
+var dummyPasswordHash, _ = cryptography.HashPassword("dummy-password")
+
+type authRepositoryI interface {
+	CreateUser(ctx context.Context, name string, password string, email string, role string) (int32, error)
+	ReadUser(ctx context.Context, email string) (*models.User, error)
+	CreateSession(ctx context.Context, email string, refreshToken string, userAgent string, ip string, expiresIn int64) (int32, error)
+	RotateRefreshToken(ctx context.Context,
+		sessionID int32, email string, refreshToken string, newRefreshToken string, expiresIn int64) error
+	ReadRetiredRefreshToken(ctx context.Context, refreshToken string) (*models.RetiredRefreshToken, error)
+	DeleteSessionByID(ctx context.Context, email string, id int32) (*models.Session, error)
+	UpdateSessionAccessToken(ctx context.Context, sessionID int32, tokenID string, expiresIn int64) error
+	ReadEmailRoleWithRefreshToken(ctx context.Context, refreshToken string) (*models.UserEmailRole, error)
+	VerifyUser(ctx context.Context, email string) error
+	UpdateUserPassword(ctx context.Context, email string, password string) error
+	CreatePasswordResetToken(ctx context.Context, email string, tokenHash string, expiresIn int64) error
+	UsePasswordResetToken(ctx context.Context, tokenHash string) (string, error)
+	RevokePasswordResetTokens(ctx context.Context, email string) error
+	ListSessions(ctx context.Context, email string) ([]models.Session, error)
+	DeleteOtherSessions(ctx context.Context, email string, currentID int32) ([]models.Session, error)
+	DeleteUserSessions(ctx context.Context, email string) ([]models.Session, error)
+	ReadUserByID(ctx context.Context, id int32) (*models.User, error)
+	ListUsers(ctx context.Context, search string, withDeleted bool, limit, offset int32) ([]models.User, error)
+	CountUsers(ctx context.Context, search string, withDeleted bool) (int64, error)
+	UpdateUserRole(ctx context.Context, id int32, role string) (string, error)
+	DeleteUserByID(ctx context.Context, id int32) (string, error)
+	RestoreUser(ctx context.Context, id int32) error
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	WithinSerializableTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type tokensI interface {
+	CreateToken(subject cryptography.TokenSubject, ttl time.Duration) (string, string, error)
+	CreateRefreshToken() (string, error)
+	CreateVerificationToken(email string, ttl time.Duration) (string, error)
+	ParseVerificationToken(inputToken string) (email string, err error)
+	RevokeToken(tokenID string, expiresAt int64)
+}
+
+type loginGuardI interface {
+	Check(ctx context.Context, email, ip string) (time.Duration, error)
+	Fail(ctx context.Context, email, ip string) error
+	Success(ctx context.Context, email string) error
+}
+
+type mailSenderI interface {
+	Send(ctx context.Context, msg mail.Message) error
+}
+
+type authConfigI interface {
+	IsEmailVerificationRequired() bool
+	GetVerificationTokenTTL() time.Duration
+	GetVerificationURL() string
+	GetPasswordResetTokenTTL() time.Duration
+	GetPasswordResetURL() string
+}
+
+// Client is the device the session is opened from.
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+// Tokens pair of the session.
+type Tokens struct {
+	Access  string
+	Refresh string
+}
+
+// SignUpInput new user.
+type SignUpInput struct {
+	Name     string
+	Email    string
+	Password string
+}
+
+// AuthService holds the rules of sign up, sign in, sessions, passwords and users management.
+type AuthService struct {
+	db      authRepositoryI
+	token   tokensI
+	mail    mailSenderI
+	lockout loginGuardI
+	policy  validation.PasswordPolicy
+	cfg     authConfigI
+}
+
+func NewAuthService(
+	db authRepositoryI, token tokensI, mail mailSenderI, lockout loginGuardI,
+	policy validation.PasswordPolicy, cfg authConfigI) *AuthService {
+	return &AuthService{db: db, token: token, mail: mail, lockout: lockout, policy: policy, cfg: cfg}
+}
+
+// SignUp creates the user with the session in one transaction,
+// tokens are nil while the email must be confirmed.
+func (o *AuthService) SignUp(ctx context.Context, input SignUpInput, client Client) (*Tokens, error) {
+	if err := validateSignUp(input, o.policy); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := cryptography.HashPassword(input.Password)
+	if err != nil {
+		return nil, apperr.Wrap(apperr.Validation, "incorrect password", err)
+	}
+
+	var tokens *Tokens
+
+	err = o.db.WithinTx(ctx, func(ctx context.Context) error {
+		userID, err := o.db.CreateUser(ctx, input.Name, passwordHash, input.Email, consts.RoleUser)
+		if err != nil {
+			return err
+		}
+
+		// Пока почта не подтверждена, сессию не создаем
+		if o.cfg.IsEmailVerificationRequired() {
+			return nil
+		}
+
+		tokens, err = o.createSession(ctx, cryptography.TokenSubject{
+			UserID: userID, Email: input.Email, Role: consts.RoleUser}, client)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = o.sendVerificationMail(ctx, input.Email); err != nil {
+		logger.Error("AuthService.sendVerificationMail()", err)
+	}
+
+	return tokens, nil
+}
+
+// SignIn opens a new session, failed attempts are counted by the lockout.
+func (o *AuthService) SignIn(ctx context.Context, email, password string, client Client) (*Tokens, error) {
+	retryAfter, err := o.lockout.Check(ctx, email, client.IP)
+	if err != nil {
+		logger.Error("AuthService.lockout.Check()", err)
+	}
+
+	if retryAfter > 0 {
+		return nil, &apperr.Error{Kind: apperr.TooManyRequests, Msg: "please try again later",
+			Err: errTooManyAttempts, RetryAfter: retryAfter}
+	}
+
+	user, err := o.db.ReadUser(ctx, email)
+	if err != nil {
+		cryptography.CheckPassword(dummyPasswordHash, password)
+		return nil, o.failSignIn(ctx, email, client.IP)
+	}
+
+	// check password with hash
+	if !cryptography.CheckPassword(user.Password, password) {
+		return nil, o.failSignIn(ctx, email, client.IP)
+	}
+
+	if err = o.lockout.Success(ctx, email); err != nil {
+		logger.Error("AuthService.lockout.Success()", err)
+	}
+
+	if o.cfg.IsEmailVerificationRequired() && !user.Verified {
+		return nil, apperr.Wrap(apperr.Forbidden, "Please confirm your email", errEmailNotVerified)
+	}
+
+	// при каждом логине создаем новую сессию
+	return o.createSession(ctx, cryptography.TokenSubject{UserID: user.ID, Email: email, Role: user.Role}, client)
+}
+
+// Refresh exchanges the refresh token for a new pair,
+// reuse of the exchanged token revokes the whole session.
+func (o *AuthService) Refresh(ctx context.Context, refreshToken string, client Client) (*Tokens, error) {
+	userData, err := o.db.ReadEmailRoleWithRefreshToken(ctx, refreshToken)
+	if err != nil {
+		o.revokeReusedTokenFamily(ctx, refreshToken, client)
+		return nil, unauthorizedError("Please log in", err)
+	}
+
+	// Check ttl refresh token
+	if userData.ExpiresIn <= time.Now().Unix() {
+		return nil, unauthorizedError("Please log in", errRefreshExpired)
+	}
+
+	rToken, err := o.token.CreateRefreshToken()
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	var aToken string
+	var rotateErr error
+
+	// the refresh token is exchanged only together with the new access token
+	err = o.db.WithinTx(ctx, func(ctx context.Context) error {
+		rotateErr = o.db.RotateRefreshToken(ctx, userData.SessionID, userData.Email,
+			refreshToken, rToken, time.Now().Add(consts.RefreshTokenTTL).Unix())
+		if rotateErr != nil {
+			return rotateErr
+		}
+
+		var err error
+		aToken, err = o.createAccessToken(ctx, cryptography.TokenSubject{
+			UserID: userData.UserID, Email: userData.Email, Role: userData.Role, SessionID: userData.SessionID})
+
+		return err
+	})
+	if rotateErr != nil {
+		// the token could be exchanged by a parallel request
+		o.revokeReusedTokenFamily(ctx, refreshToken, client)
+		return nil, unauthorizedError("Please log in", rotateErr)
+	}
+
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	// the session keeps one valid access token, the previous one is revoked after commit
+	o.token.RevokeToken(userData.AccessTokenID, userData.AccessExpiresIn)
+
+	return &Tokens{Access: aToken, Refresh: rToken}, nil
+}
+
+// VerifyEmail confirms the email with the token from the letter.
+func (o *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	if token == "" || len(token) > maxVerifyTokenSize {
+		return apperr.Wrap(apperr.Validation, "bad data, try again", errBadVerifyToken)
+	}
+
+	email, err := o.token.ParseVerificationToken(token)
+	if err != nil {
+		return apperr.Wrap(apperr.Validation, errInvalidVerifyLink.Error(), err)
+	}
+
+	if err = o.db.VerifyUser(ctx, email); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// failSignIn registers failed attempt, the error is the same for unknown email and wrong password.
+func (o *AuthService) failSignIn(ctx context.Context, email, ip string) error {
+	if err := o.lockout.Fail(ctx, email, ip); err != nil {
+		logger.Error("AuthService.lockout.Fail()", err)
+	}
+
+	return unauthorizedError(errWrongCredentials.Error(), errWrongCredentials)
+}
+
+// createSession creates session of the subject with new refresh and access tokens.
+func (o *AuthService) createSession(
+	ctx context.Context, subject cryptography.TokenSubject, client Client) (*Tokens, error) {
+	rToken, err := o.token.CreateRefreshToken()
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	subject.SessionID, err = o.db.CreateSession(
+		ctx, subject.Email, rToken, client.UserAgent, client.IP, time.Now().Add(consts.RefreshTokenTTL).Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	aToken, err := o.createAccessToken(ctx, subject)
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	return &Tokens{Access: aToken, Refresh: rToken}, nil
+}
+
+// revokeReusedTokenFamily checks that the refresh token was already exchanged,
+// if so the token is stolen and the whole session with all its tokens is revoked.
+func (o *AuthService) revokeReusedTokenFamily(ctx context.Context, refreshToken string, client Client) {
+	retired, err := o.db.ReadRetiredRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return
+	}
+
+	logger.SecurityEvent("refresh token reuse", map[string]interface{}{
+		"email":     retired.UserEmail,
+		"sessionID": retired.SessionID,
+		"retiredAt": retired.RetiredAt,
+		"ip":        client.IP,
+		"userAgent": client.UserAgent,
+	})
+
+	session, err := o.db.DeleteSessionByID(ctx, retired.UserEmail, retired.SessionID)
+	if err != nil {
+		logger.Error("AuthService.revokeReusedTokenFamily()", err)
+		return
+	}
+
+	o.token.RevokeToken(session.AccessToken, session.AccessExpiresIn)
+}
+
+// createAccessToken create access token for the session and save its id, so the token can be revoked.
+func (o *AuthService) createAccessToken(ctx context.Context, subject cryptography.TokenSubject) (string, error) {
+	aToken, tokenID, err := o.token.CreateToken(subject, consts.AccessTokenTTL)
+	if err != nil {
+		return "", err
+	}
+
+	expiresIn := time.Now().Add(consts.AccessTokenTTL).Unix()
+	if err = o.db.UpdateSessionAccessToken(ctx, subject.SessionID, tokenID, expiresIn); err != nil {
+		return "", err
+	}
+
+	return aToken, nil
+}
+
+// sendVerificationMail create verification token and send letter with link to the user.
+func (o *AuthService) sendVerificationMail(ctx context.Context, email string) error {
+	vToken, err := o.token.CreateVerificationToken(email, o.cfg.GetVerificationTokenTTL())
+	if err != nil {
+		return err
+	}
+
+	link := o.cfg.GetVerificationURL() + "?token=" + url.QueryEscape(vToken)
+
+	return o.mail.Send(ctx, mail.Message{
+		To:      email,
+		Subject: "Confirm your email",
+		Body:    "To confirm your email follow the link: " + link,
+	})
+}
+
+// validateSignUp checks email, name and password of the new user.
+func validateSignUp(input SignUpInput, policy validation.PasswordPolicy) error {
+	v := validation.NewValidator(policy)
+	v.Email("email", input.Email)
+	v.UserName("name", input.Name)
+	v.Password("password", input.Password)
+
+	return v.Err()
+}
@@ -0,0 +1,373 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Dsmit05/metida/internal/apperr"
+	"github.com/Dsmit05/metida/internal/cryptography"
+	"github.com/Dsmit05/metida/internal/logger"
+	"github.com/Dsmit05/metida/internal/mail"
+	"github.com/Dsmit05/metida/internal/models"
+	"github.com/Dsmit05/metida/internal/validation"
+	"go.uber.org/zap"
+)
+
+var errFakeNotFound = errors.New("User Not Found")
+
+type fakeAuthRepository struct {
+	authRepositoryI
+	users    map[string]models.User
+	sessions int
+}
+
+func (o *fakeAuthRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (o *fakeAuthRepository) WithinSerializableTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (o *fakeAuthRepository) CreateUser(ctx context.Context, name, password, email, role string) (int32, error) {
+	o.users[email] = models.User{ID: int32(len(o.users) + 1), Email: email, Password: password, Role: role}
+	return int32(len(o.users)), nil
+}
+
+func (o *fakeAuthRepository) ReadUser(ctx context.Context, email string) (*models.User, error) {
+	user, ok := o.users[email]
+	if !ok {
+		return nil, errFakeNotFound
+	}
+
+	return &user, nil
+}
+
+func (o *fakeAuthRepository) CreateSession(
+	ctx context.Context, email, refreshToken, userAgent, ip string, expiresIn int64) (int32, error) {
+	o.sessions++
+	return int32(o.sessions), nil
+}
+
+func (o *fakeAuthRepository) UpdateSessionAccessToken(
+	ctx context.Context, sessionID int32, tokenID string, expiresIn int64) error {
+	return nil
+}
+
+type fakeRefreshRepository struct {
+	fakeAuthRepository
+	accessErr error
+	rotated   bool
+}
+
+func (o *fakeRefreshRepository) ReadEmailRoleWithRefreshToken(
+	ctx context.Context, refreshToken string) (*models.UserEmailRole, error) {
+	return &models.UserEmailRole{Email: "user@mail.com", SessionID: 1, ExpiresIn: time.Now().Add(time.Hour).Unix(),
+		AccessTokenID: "old", AccessExpiresIn: time.Now().Add(time.Minute).Unix()}, nil
+}
+
+func (o *fakeRefreshRepository) RotateRefreshToken(ctx context.Context,
+	sessionID int32, email string, refreshToken string, newRefreshToken string, expiresIn int64) error {
+	o.rotated = true
+	return nil
+}
+
+func (o *fakeRefreshRepository) UpdateSessionAccessToken(
+	ctx context.Context, sessionID int32, tokenID string, expiresIn int64) error {
+	return o.accessErr
+}
+
+type fakeTokens struct {
+	tokensI
+	revoked *[]string
+}
+
+func (o fakeTokens) RevokeToken(tokenID string, expiresAt int64) {
+	*o.revoked = append(*o.revoked, tokenID)
+}
+
+func (o fakeTokens) CreateToken(subject cryptography.TokenSubject, ttl time.Duration) (string, string, error) {
+	return "access", "id", nil
+}
+
+func (o fakeTokens) CreateRefreshToken() (string, error) {
+	return "refresh", nil
+}
+
+func (o fakeTokens) CreateVerificationToken(email string, ttl time.Duration) (string, error) {
+	return "verify", nil
+}
+
+type fakeLockout struct {
+	retryAfter time.Duration
+	fails      int
+}
+
+func (o *fakeLockout) Check(ctx context.Context, email, ip string) (time.Duration, error) {
+	return o.retryAfter, nil
+}
+
+func (o *fakeLockout) Fail(ctx context.Context, email, ip string) error {
+	o.fails++
+	return nil
+}
+
+func (o *fakeLockout) Success(ctx context.Context, email string) error {
+	return nil
+}
+
+type fakeMail struct {
+	sent []mail.Message
+}
+
+func (o *fakeMail) Send(ctx context.Context, msg mail.Message) error {
+	o.sent = append(o.sent, msg)
+	return nil
+}
+
+type fakeAuthConfig struct {
+	verification bool
+}
+
+func (o fakeAuthConfig) IsEmailVerificationRequired() bool {
+	return o.verification
+}
+
+func (o fakeAuthConfig) GetVerificationTokenTTL() time.Duration {
+	return time.Hour
+}
+
+func (o fakeAuthConfig) GetVerificationURL() string {
+	return "http://localhost/verify"
+}
+
+func (o fakeAuthConfig) GetPasswordResetTokenTTL() time.Duration {
+	return time.Hour
+}
+
+func (o fakeAuthConfig) GetPasswordResetURL() string {
+	return "http://localhost/reset"
+}
+
+func TestAuthServiceSignIn(t *testing.T) {
+	logger.ZapLog = zap.NewNop()
+
+	hash, err := cryptography.HashPassword("Q@werty1_23")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name         string
+		email        string
+		password     string
+		verified     bool
+		verification bool
+		retryAfter   time.Duration
+		wantKind     apperr.Kind
+		wantErr      bool
+		wantFails    int
+	}{
+		{name: "Case-1: success", email: "user@mail.com", password: "Q@werty1_23", verified: true},
+		{name: "Case-2: wrong password", email: "user@mail.com", password: "wrong",
+			wantKind: apperr.Unauthorized, wantErr: true, wantFails: 1},
+		{name: "Case-3: unknown email", email: "other@mail.com", password: "Q@werty1_23",
+			wantKind: apperr.Unauthorized, wantErr: true, wantFails: 1},
+		{name: "Case-4: locked out", email: "user@mail.com", password: "Q@werty1_23", retryAfter: time.Minute,
+			wantKind: apperr.TooManyRequests, wantErr: true},
+		{name: "Case-5: email is not verified", email: "user@mail.com", password: "Q@werty1_23", verification: true,
+			wantKind: apperr.Forbidden, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeAuthRepository{users: map[string]models.User{
+				"user@mail.com": {ID: 1, Email: "user@mail.com", Password: hash, Verified: tt.verified},
+			}}
+			lockout := &fakeLockout{retryAfter: tt.retryAfter}
+			auth := NewAuthService(db, fakeTokens{}, &fakeMail{}, lockout,
+				validation.DefaultPasswordPolicy(), fakeAuthConfig{verification: tt.verification})
+
+			tokens, err := auth.SignIn(context.Background(), tt.email, tt.password, Client{IP: "127.0.0.1"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SignIn() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && apperr.KindOf(err) != tt.wantKind {
+				t.Errorf("SignIn() kind = %v, want %v", apperr.KindOf(err), tt.wantKind)
+			}
+			if !tt.wantErr && (tokens == nil || tokens.Access != "access" || db.sessions != 1) {
+				t.Errorf("SignIn() tokens = %+v, sessions = %v", tokens, db.sessions)
+			}
+			if lockout.fails != tt.wantFails {
+				t.Errorf("SignIn() fails = %v, want %v", lockout.fails, tt.wantFails)
+			}
+		})
+	}
+}
+
+func TestAuthServiceSignUp(t *testing.T) {
+	logger.ZapLog = zap.NewNop()
+
+	var tests = []struct {
+		name         string
+		input        SignUpInput
+		verification bool
+		wantTokens   bool
+		wantErr      bool
+	}{
+		{name: "Case-1: session is created",
+			input:      SignUpInput{Name: "Ivan", Email: "ivan@mail.com", Password: "Q@werty1_23"},
+			wantTokens: true,
+		},
+		{name: "Case-2: no session until email is confirmed",
+			input:        SignUpInput{Name: "Ivan", Email: "ivan@mail.com", Password: "Q@werty1_23"},
+			verification: true,
+		},
+		{name: "Case-3: invalid email",
+			input:   SignUpInput{Name: "Ivan", Email: "ivan", Password: "Q@werty1_23"},
+			wantErr: true,
+		},
+		{name: "Case-4: weak password",
+			input:   SignUpInput{Name: "Ivan", Email: "ivan@mail.com", Password: "qwerty"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeAuthRepository{users: map[string]models.User{}}
+			mailer := &fakeMail{}
+			auth := NewAuthService(db, fakeTokens{}, mailer, &fakeLockout{},
+				validation.DefaultPasswordPolicy(), fakeAuthConfig{verification: tt.verification})
+
+			tokens, err := auth.SignUp(context.Background(), tt.input, Client{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SignUp() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if apperr.KindOf(err) != apperr.Validation || len(db.users) != 0 {
+					t.Errorf("SignUp() kind = %v, users = %v", apperr.KindOf(err), len(db.users))
+				}
+				return
+			}
+			if (tokens != nil) != tt.wantTokens {
+				t.Errorf("SignUp() tokens = %+v, want %v", tokens, tt.wantTokens)
+			}
+			if len(mailer.sent) != 1 || mailer.sent[0].To != tt.input.Email {
+				t.Errorf("SignUp() sent = %+v", mailer.sent)
+			}
+		})
+	}
+}
+
+func TestAuthServiceRefresh(t *testing.T) {
+	logger.ZapLog = zap.NewNop()
+
+	var tests = []struct {
+		name        string
+		accessErr   error
+		wantErr     bool
+		wantRevoked int
+	}{
+		{name: "Case-1: old access token is revoked", wantRevoked: 1},
+		{name: "Case-2: old access token is kept if the new one is not saved",
+			accessErr: errors.New("db is down"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeRefreshRepository{accessErr: tt.accessErr}
+			var revoked []string
+			auth := NewAuthService(db, fakeTokens{revoked: &revoked}, &fakeMail{}, &fakeLockout{},
+				validation.DefaultPasswordPolicy(), fakeAuthConfig{})
+
+			tokens, err := auth.Refresh(context.Background(), "refresh", Client{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Refresh() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && apperr.KindOf(err) != apperr.Internal {
+				t.Errorf("Refresh() kind = %v, want %v", apperr.KindOf(err), apperr.Internal)
+			}
+			if !tt.wantErr && (tokens == nil || !db.rotated) {
+				t.Errorf("Refresh() tokens = %+v, rotated = %v", tokens, db.rotated)
+			}
+			if len(revoked) != tt.wantRevoked {
+				t.Errorf("Refresh() revoked = %v, want %v", revoked, tt.wantRevoked)
+			}
+		})
+	}
+}
+
+type fakePasswordRepository struct {
+	fakeAuthRepository
+	updated       bool
+	resetsRevoked bool
+}
+
+func (o *fakePasswordRepository) UpdateUserPassword(ctx context.Context, email string, password string) error {
+	o.updated = true
+	return nil
+}
+
+func (o *fakePasswordRepository) RevokePasswordResetTokens(ctx context.Context, email string) error {
+	o.resetsRevoked = true
+	return nil
+}
+
+func (o *fakePasswordRepository) DeleteOtherSessions(
+	ctx context.Context, email string, currentID int32) ([]models.Session, error) {
+	return []models.Session{{ID: currentID + 1}}, nil
+}
+
+func TestAuthServiceChangePassword(t *testing.T) {
+	logger.ZapLog = zap.NewNop()
+
+	hash, err := cryptography.HashPassword("Q@werty1_23")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name        string
+		oldPassword string
+		newPassword string
+		wantKind    apperr.Kind
+		wantErr     bool
+		wantFails   int
+	}{
+		{name: "Case-1: success", oldPassword: "Q@werty1_23", newPassword: "N3w_P@ssword"},
+		{name: "Case-2: wrong old password", oldPassword: "wrong", newPassword: "N3w_P@ssword",
+			wantKind: apperr.Validation, wantErr: true, wantFails: 1},
+		{name: "Case-3: weak new password", oldPassword: "Q@werty1_23", newPassword: "123",
+			wantKind: apperr.Validation, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakePasswordRepository{fakeAuthRepository: fakeAuthRepository{users: map[string]models.User{
+				"user@mail.com": {ID: 1, Email: "user@mail.com", Password: hash},
+			}}}
+			lockout := &fakeLockout{}
+			var revokedTokens []string
+			auth := NewAuthService(db, fakeTokens{revoked: &revokedTokens}, &fakeMail{}, lockout,
+				validation.DefaultPasswordPolicy(), fakeAuthConfig{})
+
+			revoked, err := auth.ChangePassword(context.Background(), PasswordChange{Email: "user@mail.com",
+				SessionID: 1, OldPassword: tt.oldPassword, NewPassword: tt.newPassword}, Client{IP: "127.0.0.1"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ChangePassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && apperr.KindOf(err) != tt.wantKind {
+				t.Errorf("ChangePassword() kind = %v, want %v", apperr.KindOf(err), tt.wantKind)
+			}
+			if !tt.wantErr && (revoked != 1 || len(revokedTokens) != 1 || !db.updated || !db.resetsRevoked) {
+				t.Errorf("ChangePassword() revoked = %v, updated = %v, reset tokens revoked = %v",
+					revoked, db.updated, db.resetsRevoked)
+			}
+			if tt.wantErr && (db.updated || db.resetsRevoked) {
+				t.Errorf("ChangePassword() changed the password on error")
+			}
+			if lockout.fails != tt.wantFails {
+				t.Errorf("ChangePassword() fails = %v, want %v", lockout.fails, tt.wantFails)
+			}
+		})
+	}
+}
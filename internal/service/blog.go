@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Dsmit05/metida/internal/apperr"
+	"github.com/Dsmit05/metida/internal/consts"
+	"github.com/Dsmit05/metida/internal/models"
+	"github.com/Dsmit05/metida/internal/validation"
+	"github.com/Dsmit05/metida/pkg/slug"
+)
+
+const (
+	maxBlogTags    = 10
+	maxTagNameSize = 50
+
+	// rules of the post fields in validation errors
+	ruleSlug = "slug"
+	ruleTags = "tags"
+)
+
+var (
+	// errBlogNotPublished is the same as for missing post, so drafts can't be found by readers.
+	errBlogNotPublished = apperr.New(apperr.NotFound, "Blog Not Found")
+	errBlogStatus       = errors.New("status must be draft, published or archived")
+)
+
+type blogRepositoryI interface {
+	CreatBlog(ctx context.Context, authorEmail, name, slug, description string) (int32, error)
+	ReadBlog(ctx context.Context, id int32) (*models.Blog, error)
+	ReadBlogBySlug(ctx context.Context, slug string) (*models.Blog, error)
+	ListBlogs(ctx context.Context, status, tag string, limit, offset int32) ([]models.Blog, error)
+	CountBlogs(ctx context.Context, status, tag string) (int64, error)
+	UpdateBlog(ctx context.Context, id int32, name, slug, description string) (*models.Blog, error)
+	UpdateBlogStatus(ctx context.Context, id int32, status string) (*models.Blog, error)
+	DeleteBlog(ctx context.Context, id int32) error
+	SearchBlogs(ctx context.Context, query string, limit, offset int32) ([]models.BlogSearchResult, error)
+	CountSearchBlogs(ctx context.Context, query string) (int64, error)
+	SetBlogTags(ctx context.Context, blogID int32, tags []models.Tag) ([]models.Tag, error)
+	ListTags(ctx context.Context) ([]models.Tag, error)
+	CreateComment(ctx context.Context, blogID, parentID int32, authorEmail, body string) (*models.Comment, error)
+	ReadComment(ctx context.Context, id int32) (*models.Comment, error)
+	UpdateCommentBody(ctx context.Context, id int32, authorEmail, body string) (*models.Comment, error)
+	DeleteComment(ctx context.Context, id int32, authorEmail string) error
+	UpdateCommentStatus(ctx context.Context, id int32, status string) (*models.Comment, error)
+	ListBlogComments(ctx context.Context, blogID, limit, offset int32) ([]models.Comment, error)
+	CountBlogComments(ctx context.Context, blogID int32) (int64, error)
+	ListCommentsByStatus(ctx context.Context, status string, limit, offset int32) ([]models.Comment, error)
+	CountCommentsByStatus(ctx context.Context, status string) (int64, error)
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// BlogInput slug is made from the name if it is empty, tags are replaced by the passed list.
+type BlogInput struct {
+	Name        string
+	Slug        string
+	Description string
+	Tags        []string
+}
+
+// BlogService holds the rules of the blog: slugs, tags and statuses of posts, comments and their moderation.
+type BlogService struct {
+	db blogRepositoryI
+}
+
+func NewBlogService(db blogRepositoryI) *BlogService {
+	return &BlogService{db}
+}
+
+// Create adds the post as draft with its tags in one transaction.
+func (o *BlogService) Create(ctx context.Context, authorEmail string, input BlogInput) (*models.Blog, error) {
+	blogSlug, tags, err := makeBlogSlugAndTags(input)
+	if err != nil {
+		return nil, err
+	}
+
+	blog := &models.Blog{
+		Name:        input.Name,
+		Slug:        blogSlug,
+		Description: input.Description,
+		Status:      consts.BlogStatusDraft,
+		AuthorEmail: authorEmail,
+	}
+
+	err = o.db.WithinTx(ctx, func(ctx context.Context) error {
+		if blog.ID, err = o.db.CreatBlog(ctx, authorEmail, input.Name, blogSlug, input.Description); err != nil {
+			return err
+		}
+
+		if blog.Tags, err = o.db.SetBlogTags(ctx, blog.ID, tags); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return blog, nil
+}
+
+// Update replaces name, slug, description and tags of the post in one transaction.
+func (o *BlogService) Update(ctx context.Context, id int32, input BlogInput) (*models.Blog, error) {
+	blogSlug, tags, err := makeBlogSlugAndTags(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var blog *models.Blog
+
+	err = o.db.WithinTx(ctx, func(ctx context.Context) error {
+		if blog, err = o.db.UpdateBlog(ctx, id, input.Name, blogSlug, input.Description); err != nil {
+			return err
+		}
+
+		if blog.Tags, err = o.db.SetBlogTags(ctx, id, tags); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return blog, nil
+}
+
+// ListPublished return page of published posts and number of all of them.
+func (o *BlogService) ListPublished(ctx context.Context, tag string, limit, offset int32) ([]models.Blog, int64, error) {
+	return o.List(ctx, consts.BlogStatusPublished, tag, limit, offset)
+}
+
+// List return page of posts with the status, empty status means any.
+func (o *BlogService) List(ctx context.Context, status, tag string, limit, offset int32) ([]models.Blog, int64, error) {
+	if status != "" && !isBlogStatus(status) {
+		return nil, 0, invalidFieldError("status", validation.RuleOneOf, errBlogStatus)
+	}
+
+	tag = slug.Make(tag)
+
+	blogs, err := o.db.ListBlogs(ctx, status, tag, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := o.db.CountBlogs(ctx, status, tag)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return blogs, total, nil
+}
+
+// Search return page of found published posts and number of all found.
+func (o *BlogService) Search(ctx context.Context, query string, limit, offset int32) ([]models.BlogSearchResult, int64, error) {
+	blogs, err := o.db.SearchBlogs(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := o.db.CountSearchBlogs(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return blogs, total, nil
+}
+
+// Read return post with any status, for editors.
+func (o *BlogService) Read(ctx context.Context, id int32) (*models.Blog, error) {
+	return o.db.ReadBlog(ctx, id)
+}
+
+// ReadPublished drafts and archived posts are not found for readers.
+func (o *BlogService) ReadPublished(ctx context.Context, id int32) (*models.Blog, error) {
+	blog, err := o.db.ReadBlog(ctx, id)
+
+	return published(blog, err)
+}
+
+// ReadPublishedBySlug drafts and archived posts are not found for readers.
+func (o *BlogService) ReadPublishedBySlug(ctx context.Context, blogSlug string) (*models.Blog, error) {
+	blog, err := o.db.ReadBlogBySlug(ctx, blogSlug)
+
+	return published(blog, err)
+}
+
+// SetStatus moves the post to drafts, publishes or archives it.
+func (o *BlogService) SetStatus(ctx context.Context, id int32, status string) (*models.Blog, error) {
+	if !isBlogStatus(status) {
+		return nil, invalidFieldError("status", validation.RuleOneOf, errBlogStatus)
+	}
+
+	return o.db.UpdateBlogStatus(ctx, id, status)
+}
+
+func (o *BlogService) Delete(ctx context.Context, id int32) error {
+	return o.db.DeleteBlog(ctx, id)
+}
+
+// ListTags return all tags with number of published posts.
+func (o *BlogService) ListTags(ctx context.Context) ([]models.Tag, error) {
+	return o.db.ListTags(ctx)
+}
+
+func published(blog *models.Blog, err error) (*models.Blog, error) {
+	if err == nil && blog.Status != consts.BlogStatusPublished {
+		err = errBlogNotPublished
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return blog, nil
+}
+
+// makeBlogSlug checks passed slug or makes it from the name.
+func makeBlogSlug(input BlogInput) (string, error) {
+	if input.Slug != "" {
+		if !slug.IsValid(input.Slug) {
+			return "", fmt.Errorf("slug may contain only lower case latin letters, digits and hyphens")
+		}
+
+		return input.Slug, nil
+	}
+
+	blogSlug := slug.Make(input.Name)
+	if blogSlug == "" {
+		return "", fmt.Errorf("slug can not be made from the name, set it")
+	}
+
+	return blogSlug, nil
+}
+
+// makeBlogSlugAndTags makes slugs of the post and of its tags, errors are validation errors of the fields.
+func makeBlogSlugAndTags(input BlogInput) (string, []models.Tag, error) {
+	blogSlug, err := makeBlogSlug(input)
+	if err != nil {
+		return "", nil, invalidFieldError("slug", ruleSlug, err)
+	}
+
+	tags, err := makeBlogTags(input.Tags)
+	if err != nil {
+		return "", nil, invalidFieldError("tags", ruleTags, err)
+	}
+
+	return blogSlug, tags, nil
+}
+
+// makeBlogTags normalizes tag names into slugs, tags with the same slug are merged.
+func makeBlogTags(names []string) ([]models.Tag, error) {
+	tags := make([]models.Tag, 0, len(names))
+	seen := make(map[string]bool, len(names))
+
+	for _, name := range names {
+		name = strings.Join(strings.Fields(name), " ")
+		if utf8.RuneCountInString(name) > maxTagNameSize {
+			return nil, fmt.Errorf("tag must be shorter than %v symbols", maxTagNameSize)
+		}
+
+		tagSlug := slug.Make(name)
+		if tagSlug == "" {
+			return nil, fmt.Errorf("tag %q must contain letters or digits", name)
+		}
+
+		if seen[tagSlug] {
+			continue
+		}
+
+		seen[tagSlug] = true
+		tags = append(tags, models.Tag{Name: name, Slug: tagSlug})
+	}
+
+	if len(tags) > maxBlogTags {
+		return nil, fmt.Errorf("post can have at most %v tags", maxBlogTags)
+	}
+
+	return tags, nil
+}
+
+func isBlogStatus(status string) bool {
+	switch status {
+	case consts.BlogStatusDraft, consts.BlogStatusPublished, consts.BlogStatusArchived:
+		return true
+	}
+
+	return false
+}
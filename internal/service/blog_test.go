@@ -0,0 +1,56 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Dsmit05/metida/internal/models"
+)
+
+func TestMakeBlogTags(t *testing.T) {
+	var tests = []struct {
+		name    string
+		names   []string
+		want    []models.Tag
+		wantErr bool
+	}{
+		{name: "Case-1: names are normalized",
+			names: []string{"PostgreSQL", " Go  lang "},
+			want:  []models.Tag{{Name: "PostgreSQL", Slug: "postgresql"}, {Name: "Go lang", Slug: "go-lang"}},
+		},
+		{name: "Case-2: tags with the same slug are merged",
+			names: []string{"Базы данных", "базы-данных"},
+			want:  []models.Tag{{Name: "Базы данных", Slug: "bazy-dannyh"}},
+		},
+		{name: "Case-3: no tags",
+			names: nil,
+			want:  []models.Tag{},
+		},
+		{name: "Case-4: tag without letters",
+			names:   []string{"!!!"},
+			wantErr: true,
+		},
+		{name: "Case-5: too long tag",
+			names:   []string{strings.Repeat("a", maxTagNameSize+1)},
+			wantErr: true,
+		},
+		{name: "Case-6: too many tags",
+			names:   strings.Split("a b c d e f g h i j k", " "),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := makeBlogTags(tt.names)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("makeBlogTags() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("makeBlogTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
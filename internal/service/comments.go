@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Dsmit05/metida/internal/apperr"
+	"github.com/Dsmit05/metida/internal/consts"
+	"github.com/Dsmit05/metida/internal/models"
+	"github.com/Dsmit05/metida/internal/validation"
+)
+
+var (
+	// errWrongParent only approved top-level comments of the same post can be answered.
+	errWrongParent   = apperr.New(apperr.Validation, "reply is possible only to approved top-level comment of the post")
+	errCommentStatus = errors.New("status must be pending, approved, rejected or hidden")
+)
+
+// ListComments return page of approved top-level comments with replies of the published post
+// and number of all top-level comments.
+func (o *BlogService) ListComments(ctx context.Context, blogID, limit, offset int32) ([]models.Comment, int64, error) {
+	if _, err := o.ReadPublished(ctx, blogID); err != nil {
+		return nil, 0, err
+	}
+
+	comments, err := o.db.ListBlogComments(ctx, blogID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := o.db.CountBlogComments(ctx, blogID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return comments, total, nil
+}
+
+// CreateComment comments the published post or replies to a top-level comment,
+// the comment waits for moderation.
+func (o *BlogService) CreateComment(
+	ctx context.Context, blogID, parentID int32, authorEmail, body string) (*models.Comment, error) {
+	if _, err := o.ReadPublished(ctx, blogID); err != nil {
+		return nil, err
+	}
+
+	if parentID != 0 {
+		parent, err := o.db.ReadComment(ctx, parentID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !canReply(parent, blogID) {
+			return nil, errWrongParent
+		}
+	}
+
+	return o.db.CreateComment(ctx, blogID, parentID, authorEmail, strings.TrimSpace(body))
+}
+
+// UpdateComment changes text of own comment, the comment goes back to moderation.
+func (o *BlogService) UpdateComment(ctx context.Context, id int32, authorEmail, body string) (*models.Comment, error) {
+	return o.db.UpdateCommentBody(ctx, id, authorEmail, strings.TrimSpace(body))
+}
+
+// DeleteComment deletes own comment, replies to it are kept.
+func (o *BlogService) DeleteComment(ctx context.Context, id int32, authorEmail string) error {
+	return o.db.DeleteComment(ctx, id, authorEmail)
+}
+
+// ListCommentsByStatus return page of the moderation queue and number of all comments with the status.
+func (o *BlogService) ListCommentsByStatus(
+	ctx context.Context, status string, limit, offset int32) ([]models.Comment, int64, error) {
+	if !isCommentStatus(status) {
+		return nil, 0, invalidFieldError("status", validation.RuleOneOf, errCommentStatus)
+	}
+
+	comments, err := o.db.ListCommentsByStatus(ctx, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := o.db.CountCommentsByStatus(ctx, status)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return comments, total, nil
+}
+
+// SetCommentStatus approves, rejects or hides the comment.
+func (o *BlogService) SetCommentStatus(ctx context.Context, id int32, status string) (*models.Comment, error) {
+	if !isCommentStatus(status) {
+		return nil, invalidFieldError("status", validation.RuleOneOf, errCommentStatus)
+	}
+
+	return o.db.UpdateCommentStatus(ctx, id, status)
+}
+
+// canReply checks that parent is approved top-level comment of the post.
+func canReply(parent *models.Comment, blogID int32) bool {
+	return parent.BlogID == blogID && parent.ParentID == 0 &&
+		parent.Status == consts.CommentStatusApproved && !parent.IsDeleted
+}
+
+func isCommentStatus(status string) bool {
+	switch status {
+	case consts.CommentStatusPending, consts.CommentStatusApproved,
+		consts.CommentStatusRejected, consts.CommentStatusHidden:
+		return true
+	}
+
+	return false
+}
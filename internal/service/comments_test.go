@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/Dsmit05/metida/internal/models"
+)
+
+func TestCanReply(t *testing.T) {
+	var tests = []struct {
+		name   string
+		parent models.Comment
+		blogID int32
+		want   bool
+	}{
+		{name: "Case-1: approved top-level comment",
+			parent: models.Comment{BlogID: 1, Status: "approved"},
+			blogID: 1,
+			want:   true,
+		},
+		{name: "Case-2: comment of another post",
+			parent: models.Comment{BlogID: 2, Status: "approved"},
+			blogID: 1,
+			want:   false,
+		},
+		{name: "Case-3: reply can not be answered",
+			parent: models.Comment{BlogID: 1, ParentID: 5, Status: "approved"},
+			blogID: 1,
+			want:   false,
+		},
+		{name: "Case-4: comment waits for moderation",
+			parent: models.Comment{BlogID: 1, Status: "pending"},
+			blogID: 1,
+			want:   false,
+		},
+		{name: "Case-5: deleted comment",
+			parent: models.Comment{BlogID: 1, Status: "approved", IsDeleted: true},
+			blogID: 1,
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canReply(&tt.parent, tt.blogID); got != tt.want {
+				t.Errorf("canReply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
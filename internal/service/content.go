@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Dsmit05/metida/internal/consts"
+	"github.com/Dsmit05/metida/internal/logger"
+	"github.com/Dsmit05/metida/internal/models"
+)
+
+var errContentAccessDenied = errors.New("not enough rights to the content")
+
+type contentRepositoryI interface {
+	CreatContent(ctx context.Context, email string, name string, description string) (int32, error)
+	ReadContent(ctx context.Context, email string, id int32) (*models.Content, error)
+	ListContent(ctx context.Context, email string, filter models.ContentFilter) ([]models.Content, error)
+	UpdateContent(ctx context.Context, email string, id int32, name, description string) (*models.Content, error)
+	DeleteContent(ctx context.Context, email string, id int32) error
+	SearchContent(ctx context.Context, email, query string, limit, offset int32) ([]models.ContentSearchResult, error)
+	CountSearchContent(ctx context.Context, email, query string) (int64, error)
+	ListAttachmentKeys(ctx context.Context, contentID int32) ([]string, error)
+	ListAttachments(ctx context.Context, contentID int32) ([]models.Attachment, error)
+	ShareContent(ctx context.Context, contentID int32, email, permission string) (*models.ContentShare, error)
+	UnshareContent(ctx context.Context, contentID int32, email string) error
+	ListContentShares(ctx context.Context, contentID int32) ([]models.ContentShare, error)
+	ListSharedContent(ctx context.Context, email string, limit, offset int32) ([]models.Content, error)
+	CountSharedContent(ctx context.Context, email string) (int64, error)
+	CreateContentLink(ctx context.Context, contentID int32, tokenHash string, expiresIn int64) (*models.ContentLink, error)
+	ListContentLinks(ctx context.Context, contentID int32) ([]models.ContentLink, error)
+	DeleteContentLink(ctx context.Context, contentID, id int32) error
+	ReadContentByLink(ctx context.Context, tokenHash string) (*models.Content, int64, error)
+	ReadAttachment(ctx context.Context, contentID, id int32) (*models.Attachment, error)
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type contentStorageI interface {
+	Delete(ctx context.Context, key string) error
+}
+
+// ContentPatch only not nil fields are changed.
+type ContentPatch struct {
+	Name        *string
+	Description *string
+}
+
+// ContentService holds the rules of the user content, its sharing and public links.
+type ContentService struct {
+	db      contentRepositoryI
+	storage contentStorageI
+}
+
+func NewContentService(db contentRepositoryI, storage contentStorageI) *ContentService {
+	return &ContentService{db: db, storage: storage}
+}
+
+func (o *ContentService) Create(ctx context.Context, email, name, description string) (int32, error) {
+	return o.db.CreatContent(ctx, email, name, description)
+}
+
+// List return page of the user content, more shows that the next page exists.
+func (o *ContentService) List(
+	ctx context.Context, email string, filter models.ContentFilter) (contents []models.Content, more bool, err error) {
+	// one more item shows if the next page exists
+	limit := filter.Limit
+	filter.Limit++
+
+	contents, err = o.db.ListContent(ctx, email, filter)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if int32(len(contents)) > limit {
+		return contents[:limit], true, nil
+	}
+
+	return contents, false, nil
+}
+
+// Search return page of found content and number of all found.
+func (o *ContentService) Search(ctx context.Context,
+	email, query string, limit, offset int32) ([]models.ContentSearchResult, int64, error) {
+	contents, err := o.db.SearchContent(ctx, email, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := o.db.CountSearchContent(ctx, email, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return contents, total, nil
+}
+
+// Read return content owned by the user or shared with the user.
+func (o *ContentService) Read(ctx context.Context, email string, id int32) (*models.Content, error) {
+	return o.ReadWithAccess(ctx, email, id, consts.ContentAccessRead)
+}
+
+// ReadWithAccess return content, if the user has the needed access to it.
+func (o *ContentService) ReadWithAccess(ctx context.Context, email string, id int32, need string) (*models.Content, error) {
+	content, err := o.db.ReadContent(ctx, email, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasContentAccess(content.Access, need) {
+		return nil, forbiddenError(errContentAccessDenied)
+	}
+
+	return content, nil
+}
+
+// Update replaces name and description, the repository checks rights of the user.
+func (o *ContentService) Update(
+	ctx context.Context, email string, id int32, name, description string) (*models.Content, error) {
+	return o.db.UpdateContent(ctx, email, id, name, description)
+}
+
+// Patch changes only passed fields of own or shared for edit content.
+func (o *ContentService) Patch(ctx context.Context, email string, id int32, patch ContentPatch) (*models.Content, error) {
+	content, err := o.ReadWithAccess(ctx, email, id, consts.ContentAccessEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	if patch.Name != nil {
+		content.Name = *patch.Name
+	}
+
+	if patch.Description != nil {
+		content.Description = *patch.Description
+	}
+
+	return o.Update(ctx, email, id, content.Name, content.Description)
+}
+
+// Delete removes own content with its files, the files are removed from the storage
+// only after the content is deleted.
+func (o *ContentService) Delete(ctx context.Context, email string, id int32) error {
+	var keys []string
+
+	err := o.db.WithinTx(ctx, func(ctx context.Context) error {
+		content, err := o.ReadWithAccess(ctx, email, id, consts.ContentAccessOwner)
+		if err != nil {
+			return err
+		}
+
+		// metadata of files is removed with the content, so keys are read before
+		keys, err = o.db.ListAttachmentKeys(ctx, content.ID)
+		if err != nil {
+			return err
+		}
+
+		return o.db.DeleteContent(ctx, email, content.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err = o.storage.Delete(ctx, key); err != nil {
+			logger.Error("ContentService.storage.Delete()", err)
+		}
+	}
+
+	return nil
+}
+
+// hasContentAccess owners can do everything, editors change the content and its files, readers only read.
+func hasContentAccess(access, need string) bool {
+	switch need {
+	case consts.ContentAccessRead:
+		return access == consts.ContentAccessOwner || access == consts.ContentAccessEdit ||
+			access == consts.ContentAccessRead
+	case consts.ContentAccessEdit:
+		return access == consts.ContentAccessOwner || access == consts.ContentAccessEdit
+	default:
+		return access == consts.ContentAccessOwner
+	}
+}
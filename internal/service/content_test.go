@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Dsmit05/metida/internal/apperr"
+	"github.com/Dsmit05/metida/internal/consts"
+	"github.com/Dsmit05/metida/internal/cryptography"
+	"github.com/Dsmit05/metida/internal/models"
+)
+
+func TestHasContentAccess(t *testing.T) {
+	var tests = []struct {
+		name   string
+		access string
+		need   string
+		want   bool
+	}{
+		{name: "Case-1: owner can share", access: consts.ContentAccessOwner, need: consts.ContentAccessOwner, want: true},
+		{name: "Case-2: editor can't share", access: consts.ContentAccessEdit, need: consts.ContentAccessOwner, want: false},
+		{name: "Case-3: editor can edit", access: consts.ContentAccessEdit, need: consts.ContentAccessEdit, want: true},
+		{name: "Case-4: reader can't edit", access: consts.ContentAccessRead, need: consts.ContentAccessEdit, want: false},
+		{name: "Case-5: reader can read", access: consts.ContentAccessRead, need: consts.ContentAccessRead, want: true},
+		{name: "Case-6: owner can read", access: consts.ContentAccessOwner, need: consts.ContentAccessRead, want: true},
+		{name: "Case-7: unknown access", access: "", need: consts.ContentAccessRead, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasContentAccess(tt.access, tt.need); got != tt.want {
+				t.Errorf("hasContentAccess() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeContentRepository struct {
+	contentRepositoryI
+	contents  []models.Content
+	access    string
+	updated   *models.Content
+	keys      []string
+	deleteErr error
+	deleted   bool
+}
+
+func (o *fakeContentRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (o *fakeContentRepository) ListAttachmentKeys(ctx context.Context, contentID int32) ([]string, error) {
+	return o.keys, nil
+}
+
+func (o *fakeContentRepository) DeleteContent(ctx context.Context, email string, id int32) error {
+	if o.deleteErr != nil {
+		return o.deleteErr
+	}
+
+	o.deleted = true
+
+	return nil
+}
+
+// fakeLinkRepository keeps one public link of content 1 with the file 1.
+type fakeLinkRepository struct {
+	contentRepositoryI
+	tokenHash string
+	expiresIn int64
+}
+
+func (o *fakeLinkRepository) ReadContentByLink(ctx context.Context, tokenHash string) (*models.Content, int64, error) {
+	if tokenHash != o.tokenHash {
+		return nil, 0, apperr.New(apperr.NotFound, "Link Not Found")
+	}
+
+	return &models.Content{ID: 1, Access: consts.ContentAccessRead}, o.expiresIn, nil
+}
+
+func (o *fakeLinkRepository) ListAttachments(ctx context.Context, contentID int32) ([]models.Attachment, error) {
+	return []models.Attachment{{ID: 1, ContentID: contentID}}, nil
+}
+
+func (o *fakeLinkRepository) ReadAttachment(ctx context.Context, contentID, id int32) (*models.Attachment, error) {
+	if contentID != 1 || id != 1 {
+		return nil, apperr.New(apperr.NotFound, "File Not Found")
+	}
+
+	return &models.Attachment{ID: id, ContentID: contentID}, nil
+}
+
+type fakeContentStorage struct {
+	deleted []string
+}
+
+func (o *fakeContentStorage) Delete(ctx context.Context, key string) error {
+	o.deleted = append(o.deleted, key)
+	return nil
+}
+
+func (o *fakeContentRepository) ListContent(
+	ctx context.Context, email string, filter models.ContentFilter) ([]models.Content, error) {
+	if int(filter.Limit) < len(o.contents) {
+		return o.contents[:filter.Limit], nil
+	}
+
+	return o.contents, nil
+}
+
+func (o *fakeContentRepository) ReadContent(ctx context.Context, email string, id int32) (*models.Content, error) {
+	return &models.Content{ID: id, Name: "name", Description: "description", Access: o.access}, nil
+}
+
+func (o *fakeContentRepository) UpdateContent(
+	ctx context.Context, email string, id int32, name, description string) (*models.Content, error) {
+	o.updated = &models.Content{ID: id, Name: name, Description: description}
+	return o.updated, nil
+}
+
+func TestContentServiceList(t *testing.T) {
+	var tests = []struct {
+		name     string
+		stored   int
+		limit    int32
+		wantLen  int
+		wantMore bool
+	}{
+		{name: "Case-1: full page with next", stored: 3, limit: 2, wantLen: 2, wantMore: true},
+		{name: "Case-2: last full page", stored: 2, limit: 2, wantLen: 2, wantMore: false},
+		{name: "Case-3: short page", stored: 1, limit: 2, wantLen: 1, wantMore: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeContentRepository{contents: make([]models.Content, tt.stored)}
+
+			got, more, err := NewContentService(db, nil).List(context.Background(), "", models.ContentFilter{Limit: tt.limit})
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(got) != tt.wantLen || more != tt.wantMore {
+				t.Errorf("List() len = %v, more = %v, want %v, %v", len(got), more, tt.wantLen, tt.wantMore)
+			}
+		})
+	}
+}
+
+func TestContentServicePatch(t *testing.T) {
+	name := "new name"
+
+	var tests = []struct {
+		name     string
+		access   string
+		wantKind apperr.Kind
+		wantErr  bool
+	}{
+		{name: "Case-1: owner changes only name", access: consts.ContentAccessOwner},
+		{name: "Case-2: editor changes only name", access: consts.ContentAccessEdit},
+		{name: "Case-3: reader is forbidden", access: consts.ContentAccessRead, wantKind: apperr.Forbidden, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeContentRepository{access: tt.access}
+
+			_, err := NewContentService(db, nil).Patch(context.Background(), "", 1, ContentPatch{Name: &name})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Patch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				if apperr.KindOf(err) != tt.wantKind || !errors.Is(err, errContentAccessDenied) {
+					t.Errorf("Patch() error = %v, want kind %v", err, tt.wantKind)
+				}
+				return
+			}
+
+			if db.updated == nil || db.updated.Name != name || db.updated.Description != "description" {
+				t.Errorf("Patch() updated = %+v", db.updated)
+			}
+		})
+	}
+}
+
+func TestContentServiceDelete(t *testing.T) {
+	errDB := errors.New("db is down")
+
+	var tests = []struct {
+		name        string
+		access      string
+		deleteErr   error
+		wantErr     error
+		wantDeleted int
+	}{
+		{name: "Case-1: owner deletes content and files", access: consts.ContentAccessOwner, wantDeleted: 2},
+		{name: "Case-2: editor is forbidden", access: consts.ContentAccessEdit, wantErr: errContentAccessDenied},
+		{name: "Case-3: files are kept if content is not deleted", access: consts.ContentAccessOwner,
+			deleteErr: errDB, wantErr: errDB},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeContentRepository{access: tt.access, keys: []string{"1/a", "1/b"}, deleteErr: tt.deleteErr}
+			storage := &fakeContentStorage{}
+
+			err := NewContentService(db, storage).Delete(context.Background(), "", 1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Delete() error = %v, want %v", err, tt.wantErr)
+			}
+			if db.deleted != (tt.wantErr == nil) {
+				t.Errorf("Delete() content deleted = %v", db.deleted)
+			}
+			if len(storage.deleted) != tt.wantDeleted {
+				t.Errorf("Delete() deleted files = %v, want %v", storage.deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestContentServiceReadWithAccess(t *testing.T) {
+	var tests = []struct {
+		name    string
+		access  string
+		need    string
+		wantErr bool
+	}{
+		{name: "Case-1: reader reads", access: consts.ContentAccessRead, need: consts.ContentAccessRead},
+		{name: "Case-2: reader can't edit", access: consts.ContentAccessRead, need: consts.ContentAccessEdit, wantErr: true},
+		{name: "Case-3: editor edits", access: consts.ContentAccessEdit, need: consts.ContentAccessEdit},
+		{name: "Case-4: editor is not owner", access: consts.ContentAccessEdit, need: consts.ContentAccessOwner,
+			wantErr: true},
+		{name: "Case-5: owner manages", access: consts.ContentAccessOwner, need: consts.ContentAccessOwner},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeContentRepository{access: tt.access}
+
+			content, err := NewContentService(db, nil).ReadWithAccess(context.Background(), "", 1, tt.need)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadWithAccess() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && (apperr.KindOf(err) != apperr.Forbidden || !errors.Is(err, errContentAccessDenied)) {
+				t.Errorf("ReadWithAccess() error = %v, want forbidden", err)
+			}
+			if !tt.wantErr && content.ID != 1 {
+				t.Errorf("ReadWithAccess() content = %+v", content)
+			}
+		})
+	}
+}
+
+func TestContentServiceReadByLink(t *testing.T) {
+	var tests = []struct {
+		name      string
+		token     string
+		expiresIn int64
+		wantErr   bool
+	}{
+		{name: "Case-1: unexpired link", token: "token", expiresIn: time.Now().Add(time.Hour).Unix()},
+		{name: "Case-2: expired link", token: "token", expiresIn: time.Now().Add(-time.Minute).Unix(), wantErr: true},
+		{name: "Case-3: unknown token", token: "other", expiresIn: time.Now().Add(time.Hour).Unix(), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeLinkRepository{tokenHash: cryptography.HashToken("token"), expiresIn: tt.expiresIn}
+
+			content, attachments, err := NewContentService(db, nil).ReadByLink(context.Background(), tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadByLink() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && apperr.KindOf(err) != apperr.NotFound {
+				t.Errorf("ReadByLink() kind = %v, want %v", apperr.KindOf(err), apperr.NotFound)
+			}
+			if !tt.wantErr && (content.Access != consts.ContentAccessRead || len(attachments) != 1) {
+				t.Errorf("ReadByLink() content = %+v, attachments = %v", content, attachments)
+			}
+		})
+	}
+}
+
+func TestContentServiceReadSharedAttachment(t *testing.T) {
+	var tests = []struct {
+		name      string
+		token     string
+		fileID    int32
+		expiresIn int64
+		wantErr   bool
+	}{
+		{name: "Case-1: file of the link content", token: "token", fileID: 1,
+			expiresIn: time.Now().Add(time.Hour).Unix()},
+		{name: "Case-2: file of other content", token: "token", fileID: 2,
+			expiresIn: time.Now().Add(time.Hour).Unix(), wantErr: true},
+		{name: "Case-3: expired link", token: "token", fileID: 1,
+			expiresIn: time.Now().Add(-time.Minute).Unix(), wantErr: true},
+		{name: "Case-4: unknown token", token: "other", fileID: 1,
+			expiresIn: time.Now().Add(time.Hour).Unix(), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeLinkRepository{tokenHash: cryptography.HashToken("token"), expiresIn: tt.expiresIn}
+
+			attachment, err := NewContentService(db, nil).ReadSharedAttachment(context.Background(), tt.token, tt.fileID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadSharedAttachment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && apperr.KindOf(err) != apperr.NotFound {
+				t.Errorf("ReadSharedAttachment() kind = %v, want %v", apperr.KindOf(err), apperr.NotFound)
+			}
+			if !tt.wantErr && attachment.ContentID != 1 {
+				t.Errorf("ReadSharedAttachment() attachment = %+v", attachment)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"github.com/Dsmit05/metida/internal/apperr"
+)
+
+// invalidFieldError validation error of one field of the input.
+func invalidFieldError(field, rule string, err error) error {
+	return apperr.Invalid(apperr.FieldError{Field: field, Rule: rule, Message: err.Error()})
+}
+
+func unauthorizedError(msg string, err error) error {
+	return apperr.Wrap(apperr.Unauthorized, msg, err)
+}
+
+func forbiddenError(err error) error {
+	return apperr.Wrap(apperr.Forbidden, err.Error(), err)
+}
+
+// internalError the cause is only logged.
+func internalError(err error) error {
+	return apperr.Wrap(apperr.Internal, "", err)
+}
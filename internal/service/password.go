@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/Dsmit05/metida/internal/apperr"
+	"github.com/Dsmit05/metida/internal/cryptography"
+	"github.com/Dsmit05/metida/internal/logger"
+	"github.com/Dsmit05/metida/internal/mail"
+	"github.com/Dsmit05/metida/internal/models"
+	"github.com/Dsmit05/metida/internal/validation"
+)
+
+// resetMailTimeout limits the letter sending which is not bound to the request.
+const resetMailTimeout = time.Minute
+
+var (
+	errWrongPassword = apperr.New(apperr.Validation, "wrong password")
+	errBadResetToken = errors.New("invalid or expired token")
+)
+
+// PasswordChange the old password is checked before the new one is set.
+type PasswordChange struct {
+	Email       string
+	SessionID   int32
+	OldPassword string
+	NewPassword string
+}
+
+// ChangePassword sets the new password and revokes other sessions of the user,
+// return number of revoked sessions.
+func (o *AuthService) ChangePassword(ctx context.Context, input PasswordChange, client Client) (int, error) {
+	if err := validateNewPassword(input.NewPassword, o.policy); err != nil {
+		return 0, err
+	}
+
+	if err := o.checkOldPassword(ctx, input.Email, input.OldPassword, client); err != nil {
+		return 0, err
+	}
+
+	passwordHash, err := cryptography.HashPassword(input.NewPassword)
+	if err != nil {
+		return 0, apperr.Wrap(apperr.Validation, "incorrect password", err)
+	}
+
+	var sessions []models.Session
+
+	// a session created by a parallel login is either removed or makes the transaction repeat
+	err = o.db.WithinSerializableTx(ctx, func(ctx context.Context) error {
+		if err := o.db.UpdateUserPassword(ctx, input.Email, passwordHash); err != nil {
+			return err
+		}
+
+		// a letter with reset link must not change the password back
+		if err := o.db.RevokePasswordResetTokens(ctx, input.Email); err != nil {
+			return err
+		}
+
+		var err error
+		sessions, err = o.db.DeleteOtherSessions(ctx, input.Email, input.SessionID)
+
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	o.revokeAccessTokens(sessions...)
+
+	return len(sessions), nil
+}
+
+// ForgotPassword sends letter with reset link in background and errors are only logged,
+// so neither the answer nor its time depends on the email existence.
+func (o *AuthService) ForgotPassword(email string) {
+	go o.forgotPassword(email)
+}
+
+func (o *AuthService) forgotPassword(email string) {
+	ctx, cancel := context.WithTimeout(context.Background(), resetMailTimeout)
+	defer cancel()
+
+	if _, err := o.db.ReadUser(ctx, email); err != nil {
+		return
+	}
+
+	if err := o.sendResetMail(ctx, email); err != nil {
+		logger.Error("AuthService.sendResetMail()", err)
+	}
+}
+
+// ResetPassword sets the new password with token from the letter and revokes all sessions of the user.
+func (o *AuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if err := validateNewPassword(newPassword, o.policy); err != nil {
+		return err
+	}
+
+	passwordHash, err := cryptography.HashPassword(newPassword)
+	if err != nil {
+		return apperr.Wrap(apperr.Validation, "incorrect password", err)
+	}
+
+	var sessions []models.Session
+
+	// the token is used only if the password is changed and the sessions are removed
+	err = o.db.WithinSerializableTx(ctx, func(ctx context.Context) error {
+		email, err := o.db.UsePasswordResetToken(ctx, cryptography.HashToken(token))
+		if err != nil {
+			return err
+		}
+
+		if err = o.db.UpdateUserPassword(ctx, email, passwordHash); err != nil {
+			return err
+		}
+
+		// other letters with reset links are not valid anymore
+		if err = o.db.RevokePasswordResetTokens(ctx, email); err != nil {
+			return err
+		}
+
+		sessions, err = o.db.DeleteUserSessions(ctx, email)
+
+		return err
+	})
+	if apperr.KindOf(err) == apperr.NotFound {
+		return apperr.Wrap(apperr.Validation, errBadResetToken.Error(), err)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	o.revokeAccessTokens(sessions...)
+
+	return nil
+}
+
+// checkOldPassword wrong passwords are counted by the lockout, so a stolen access token
+// does not allow to guess the password.
+func (o *AuthService) checkOldPassword(ctx context.Context, email, password string, client Client) error {
+	retryAfter, err := o.lockout.Check(ctx, email, client.IP)
+	if err != nil {
+		logger.Error("AuthService.lockout.Check()", err)
+	}
+
+	if retryAfter > 0 {
+		return &apperr.Error{Kind: apperr.TooManyRequests, Msg: "please try again later",
+			Err: errTooManyAttempts, RetryAfter: retryAfter}
+	}
+
+	user, err := o.db.ReadUser(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	if !cryptography.CheckPassword(user.Password, password) {
+		if err = o.lockout.Fail(ctx, email, client.IP); err != nil {
+			logger.Error("AuthService.lockout.Fail()", err)
+		}
+
+		return errWrongPassword
+	}
+
+	if err = o.lockout.Success(ctx, email); err != nil {
+		logger.Error("AuthService.lockout.Success()", err)
+	}
+
+	return nil
+}
+
+// sendResetMail create reset token and send letter with link to the user.
+func (o *AuthService) sendResetMail(ctx context.Context, email string) error {
+	rToken, tokenHash, err := cryptography.RandomToken()
+	if err != nil {
+		return err
+	}
+
+	expiresIn := time.Now().Add(o.cfg.GetPasswordResetTokenTTL()).Unix()
+	if err = o.db.CreatePasswordResetToken(ctx, email, tokenHash, expiresIn); err != nil {
+		return err
+	}
+
+	link := o.cfg.GetPasswordResetURL() + "?token=" + url.QueryEscape(rToken)
+
+	return o.mail.Send(ctx, mail.Message{
+		To:      email,
+		Subject: "Password reset",
+		Body:    "To set a new password follow the link: " + link,
+	})
+}
+
+// validateNewPassword checks the new password by the policy.
+func validateNewPassword(password string, policy validation.PasswordPolicy) error {
+	v := validation.NewValidator(policy)
+	v.Password("newPassword", password)
+
+	return v.Err()
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Dsmit05/metida/internal/models"
+)
+
+// ListSessions return active sessions of the user.
+func (o *AuthService) ListSessions(ctx context.Context, email string) ([]models.Session, error) {
+	return o.db.ListSessions(ctx, email)
+}
+
+// RevokeSession logs out the device of the user session.
+func (o *AuthService) RevokeSession(ctx context.Context, email string, id int32) error {
+	session, err := o.db.DeleteSessionByID(ctx, email, id)
+	if err != nil {
+		return err
+	}
+
+	o.revokeAccessTokens(*session)
+
+	return nil
+}
+
+// Logout closes the current session, the current token is revoked even if the session was already removed.
+func (o *AuthService) Logout(ctx context.Context, email string, sessionID int32, tokenID string, tokenExpiresAt int64) error {
+	o.token.RevokeToken(tokenID, tokenExpiresAt)
+
+	return o.RevokeSession(ctx, email, sessionID)
+}
+
+// RevokeOtherSessions logs out everywhere except the current session, return number of revoked sessions.
+func (o *AuthService) RevokeOtherSessions(ctx context.Context, email string, currentID int32) (int, error) {
+	sessions, err := o.db.DeleteOtherSessions(ctx, email, currentID)
+	if err != nil {
+		return 0, err
+	}
+
+	o.revokeAccessTokens(sessions...)
+
+	return len(sessions), nil
+}
+
+// revokeAccessTokens push access tokens of removed sessions to the denylist.
+func (o *AuthService) revokeAccessTokens(sessions ...models.Session) {
+	for _, session := range sessions {
+		o.token.RevokeToken(session.AccessToken, session.AccessExpiresIn)
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Dsmit05/metida/internal/apperr"
+	"github.com/Dsmit05/metida/internal/consts"
+	"github.com/Dsmit05/metida/internal/cryptography"
+	"github.com/Dsmit05/metida/internal/models"
+)
+
+var (
+	errShareWithSelf = apperr.New(apperr.Validation, "content can't be shared with its owner")
+	// errLinkExpired the same answer as for unknown link.
+	errLinkExpired = apperr.New(apperr.NotFound, "Link Not Found")
+)
+
+// Share grants another user read or edit access to own content, existing access is replaced.
+func (o *ContentService) Share(
+	ctx context.Context, email string, id int32, userEmail, permission string) (*models.ContentShare, error) {
+	content, err := o.ReadWithAccess(ctx, email, id, consts.ContentAccessOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	if userEmail == content.UserEmail {
+		return nil, errShareWithSelf
+	}
+
+	return o.db.ShareContent(ctx, content.ID, userEmail, permission)
+}
+
+// ListShares return users having access to own content.
+func (o *ContentService) ListShares(ctx context.Context, email string, id int32) ([]models.ContentShare, error) {
+	content, err := o.ReadWithAccess(ctx, email, id, consts.ContentAccessOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.db.ListContentShares(ctx, content.ID)
+}
+
+// Unshare revokes access of the user to own content.
+func (o *ContentService) Unshare(ctx context.Context, email string, id int32, userEmail string) error {
+	content, err := o.ReadWithAccess(ctx, email, id, consts.ContentAccessOwner)
+	if err != nil {
+		return err
+	}
+
+	return o.db.UnshareContent(ctx, content.ID, userEmail)
+}
+
+// ListShared return page of content of other users shared with the user and number of all of it.
+func (o *ContentService) ListShared(ctx context.Context, email string, limit, offset int32) ([]models.Content, int64, error) {
+	contents, err := o.db.ListSharedContent(ctx, email, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := o.db.CountSharedContent(ctx, email)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return contents, total, nil
+}
+
+// CreateLink creates public link to own content, only the hash of the token is saved,
+// so the token is returned only once.
+func (o *ContentService) CreateLink(
+	ctx context.Context, email string, id int32, ttl time.Duration) (*models.ContentLink, string, error) {
+	content, err := o.ReadWithAccess(ctx, email, id, consts.ContentAccessOwner)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, tokenHash, err := cryptography.RandomToken()
+	if err != nil {
+		return nil, "", internalError(err)
+	}
+
+	link, err := o.db.CreateContentLink(ctx, content.ID, tokenHash, time.Now().Add(ttl).Unix())
+	if err != nil {
+		return nil, "", err
+	}
+
+	return link, token, nil
+}
+
+// ListLinks return unexpired public links to own content.
+func (o *ContentService) ListLinks(ctx context.Context, email string, id int32) ([]models.ContentLink, error) {
+	content, err := o.ReadWithAccess(ctx, email, id, consts.ContentAccessOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.db.ListContentLinks(ctx, content.ID)
+}
+
+// DeleteLink revokes public link to own content.
+func (o *ContentService) DeleteLink(ctx context.Context, email string, id, linkID int32) error {
+	content, err := o.ReadWithAccess(ctx, email, id, consts.ContentAccessOwner)
+	if err != nil {
+		return err
+	}
+
+	return o.db.DeleteContentLink(ctx, content.ID, linkID)
+}
+
+// ReadByLink return content and its files by token of the public link, expired links are not found.
+func (o *ContentService) ReadByLink(ctx context.Context, token string) (*models.Content, []models.Attachment, error) {
+	content, err := o.readContentByLink(ctx, token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attachments, err := o.db.ListAttachments(ctx, content.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return content, attachments, nil
+}
+
+// ReadSharedAttachment return file by token of the public link, only files of the content of the link are found.
+func (o *ContentService) ReadSharedAttachment(ctx context.Context, token string, fileID int32) (*models.Attachment, error) {
+	content, err := o.readContentByLink(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.db.ReadAttachment(ctx, content.ID, fileID)
+}
+
+// readContentByLink return content of the unexpired public link.
+func (o *ContentService) readContentByLink(ctx context.Context, token string) (*models.Content, error) {
+	content, expiresIn, err := o.db.ReadContentByLink(ctx, cryptography.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresIn <= time.Now().Unix() {
+		return nil, errLinkExpired
+	}
+
+	return content, nil
+}
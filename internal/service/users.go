@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Dsmit05/metida/internal/apperr"
+	"github.com/Dsmit05/metida/internal/models"
+)
+
+var errDeleteSelf = errors.New("admin can not delete own account")
+
+// ListUsers return page of users found by email or name and number of all found.
+func (o *AuthService) ListUsers(ctx context.Context,
+	search string, withDeleted bool, limit, offset int32) ([]models.User, int64, error) {
+	users, err := o.db.ListUsers(ctx, search, withDeleted, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := o.db.CountUsers(ctx, search, withDeleted)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func (o *AuthService) ReadUserByID(ctx context.Context, id int32) (*models.User, error) {
+	return o.db.ReadUserByID(ctx, id)
+}
+
+// UpdateUserRole changes role of the user, the role is inside access tokens,
+// so they are revoked and the user gets a new one by refresh token.
+func (o *AuthService) UpdateUserRole(ctx context.Context, id int32, role string) error {
+	var sessions []models.Session
+
+	// the role is changed and the sessions to revoke are read in one transaction
+	err := o.db.WithinSerializableTx(ctx, func(ctx context.Context) error {
+		email, err := o.db.UpdateUserRole(ctx, id, role)
+		if err != nil {
+			return err
+		}
+
+		sessions, err = o.db.ListSessions(ctx, email)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	o.revokeAccessTokens(sessions...)
+
+	return nil
+}
+
+// DeleteUser marks the user as deleted and removes all user sessions, return number of revoked sessions.
+func (o *AuthService) DeleteUser(ctx context.Context, adminID, id int32) (int, error) {
+	if id == adminID {
+		return 0, apperr.Wrap(apperr.Validation, errDeleteSelf.Error(), errDeleteSelf)
+	}
+
+	var sessions []models.Session
+
+	// a deleted user must not keep live sessions
+	err := o.db.WithinSerializableTx(ctx, func(ctx context.Context) error {
+		email, err := o.db.DeleteUserByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		sessions, err = o.db.DeleteUserSessions(ctx, email)
+
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	o.revokeAccessTokens(sessions...)
+
+	return len(sessions), nil
+}
+
+func (o *AuthService) RestoreUser(ctx context.Context, id int32) error {
+	return o.db.RestoreUser(ctx, id)
+}
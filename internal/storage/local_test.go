@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+
+	o, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStorage error = %v", err)
+	}
+
+	if err = o.Put(ctx, "content/1/file", strings.NewReader("data"), 4, "text/plain"); err != nil {
+		t.Fatalf("Put error = %v", err)
+	}
+
+	r, err := o.Get(ctx, "content/1/file")
+	if err != nil {
+		t.Fatalf("Get error = %v", err)
+	}
+
+	data, _ := io.ReadAll(r)
+	r.Close()
+
+	if string(data) != "data" {
+		t.Errorf("Get() = %s, want data", data)
+	}
+
+	if err = o.Delete(ctx, "content/1/file"); err != nil {
+		t.Fatalf("Delete error = %v", err)
+	}
+
+	if _, err = o.Get(ctx, "content/1/file"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete error = %v, want %v", err, ErrNotFound)
+	}
+
+	if err = o.Delete(ctx, "content/1/file"); err != nil {
+		t.Errorf("Delete of missing file error = %v", err)
+	}
+}
+
+func TestCheckKey(t *testing.T) {
+	var tests = []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "Case-1: valid key", key: "content/1/0a1b-2c3d", wantErr: false},
+		{name: "Case-2: empty key", key: "", wantErr: true},
+		{name: "Case-3: parent directory", key: "content/../../etc/passwd", wantErr: true},
+		{name: "Case-4: absolute path", key: "/etc/passwd", wantErr: true},
+		{name: "Case-5: empty segment", key: "content//1", wantErr: true},
+		{name: "Case-6: forbidden symbols", key: "content/1/file name", wantErr: true},
+		{name: "Case-7: backslash", key: `content\1`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkKey(tt.key); (err != nil) != tt.wantErr {
+				t.Errorf("checkKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrNotFound object with the key does not exist.
+var ErrNotFound = errors.New("object not found")
+
+var errInvalidKey = errors.New("invalid object key")
+
+// Storage keeps contents of files by keys, metadata of files is stored in the database.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type configStorageI interface {
+	GetStorageType() string
+	GetStorageLocalPath() string
+	GetStorageS3Options() S3Options
+}
+
+// NewStorage return Storage selected in config.
+func NewStorage(cfg configStorageI) (Storage, error) {
+	switch cfg.GetStorageType() {
+	case "", "local":
+		return NewLocalStorage(cfg.GetStorageLocalPath())
+	case "s3":
+		return NewS3Storage(cfg.GetStorageS3Options())
+	default:
+		return nil, fmt.Errorf("unknown storage type: %v", cfg.GetStorageType())
+	}
+}
+
+// checkKey allows keys of latin letters, digits and "-_./" symbols without empty or relative segments.
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") {
+		return errInvalidKey
+	}
+
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return errInvalidKey
+		}
+	}
+
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == '/':
+		default:
+			return errInvalidKey
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// bodyField name of the field for errors of the whole body.
+const bodyField = "body"
+
+// AddBindError turns error of gin binding into violations: tags of the struct fields,
+// wrong types of JSON values and broken JSON, input is the pointer passed to binding.
+// It return true if the body was decoded and only tags are broken, so other rules can be checked too.
+func (o *Validator) AddBindError(input interface{}, err error) (decoded bool) {
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+
+	switch {
+	case errors.As(err, &validationErrs):
+		for _, fieldErr := range validationErrs {
+			field := jsonFieldName(input, fieldErr.StructField())
+			if o.Has(field) {
+				continue
+			}
+
+			if fieldErr.Tag() == RuleRequired {
+				o.Add(field, RuleRequired, "must not be empty", nil)
+				continue
+			}
+
+			o.Add(field, fieldErr.Tag(), "must satisfy the rule "+fieldErr.Tag(),
+				map[string]interface{}{"param": fieldErr.Param()})
+		}
+
+		return true
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = bodyField
+		}
+
+		o.Add(field, RuleType, "must be "+typeErr.Type.String(),
+			map[string]interface{}{"type": typeErr.Type.String(), "value": typeErr.Value})
+	case errors.Is(err, io.EOF):
+		o.Add(bodyField, RuleRequired, "request body is empty", nil)
+	case errors.As(err, &syntaxErr):
+		o.Add(bodyField, RuleJSON, "request body is not valid JSON",
+			map[string]interface{}{"offset": syntaxErr.Offset})
+	default:
+		o.Add(bodyField, RuleJSON, "request body is not valid JSON", nil)
+	}
+
+	return false
+}
+
+// jsonFieldName return name of the field in JSON, the struct name is used if there is no json tag.
+func jsonFieldName(input interface{}, structField string) string {
+	t := reflect.TypeOf(input)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return structField
+	}
+
+	field, ok := t.FieldByName(structField)
+	if !ok {
+		return structField
+	}
+
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return structField
+	}
+
+	return name
+}
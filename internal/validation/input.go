@@ -0,0 +1,28 @@
+package validation
+
+import (
+	"net/mail"
+)
+
+const (
+	minUserNameLength = 4
+	maxUserNameLength = 24
+)
+
+// Email checks the correct user email.
+func (o *Validator) Email(field, email string) {
+	o.Required(field, email)
+	if o.Has(field) {
+		return
+	}
+
+	if _, err := mail.ParseAddress(email); err != nil {
+		o.Add(field, RuleEmail, "must be a valid email address", nil)
+	}
+}
+
+// UserName checks the correct user name.
+func (o *Validator) UserName(field, userName string) {
+	o.Required(field, userName)
+	o.Length(field, userName, minUserNameLength, maxUserNameLength)
+}
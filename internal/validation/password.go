@@ -0,0 +1,116 @@
+package validation
+
+import (
+	// bundled list of common passwords
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Dsmit05/metida/internal/consts"
+)
+
+// PasswordPolicy limits of user passwords in symbols.
+type PasswordPolicy struct {
+	MinLength int
+	MaxLength int
+}
+
+// DefaultPasswordPolicy is used by the validator created without a policy.
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{MinLength: consts.PasswordMinLength, MaxLength: consts.PasswordMaxLength}
+}
+
+//go:embed common-passwords.txt
+var commonPasswordsFile string
+
+var (
+	commonPasswordsOnce sync.Once
+	commonPasswords     map[string]struct{}
+)
+
+// Password checks password for next rules:
+// length is set by PasswordPolicy of the validator and is not above 72 bytes
+// at least 1 number
+// at least 1 upper case
+// at least 1 lower case
+// at least 1 special character
+// password is not in the list of common passwords.
+// Every broken rule is a separate violation.
+func (o *Validator) Password(field, password string) {
+	o.Required(field, password)
+	if o.Has(field) {
+		return
+	}
+
+	var uppercasePresent bool
+	var lowercasePresent bool
+	var numberPresent bool
+	var specialCharPresent bool
+
+	for _, ch := range password {
+		switch {
+		case unicode.IsNumber(ch):
+			numberPresent = true
+		case unicode.IsUpper(ch):
+			uppercasePresent = true
+		case unicode.IsLower(ch):
+			lowercasePresent = true
+		case unicode.IsPunct(ch) || unicode.IsSymbol(ch):
+			specialCharPresent = true
+		}
+	}
+
+	policy := o.passwordPolicy
+	if policy == (PasswordPolicy{}) {
+		policy = DefaultPasswordPolicy()
+	}
+
+	if length := utf8.RuneCountInString(password); length < policy.MinLength || length > policy.MaxLength {
+		message := fmt.Sprintf("password length must be between %d to %d characters long",
+			policy.MinLength, policy.MaxLength)
+		o.Add(field, RuleLength, message, map[string]interface{}{"min": policy.MinLength, "max": policy.MaxLength})
+	} else if len(password) > consts.PasswordMaxBytes {
+		// bcrypt ignores the rest of the password
+		o.Add(field, RuleLength, fmt.Sprintf("password must be at most %d bytes long", consts.PasswordMaxBytes),
+			map[string]interface{}{"maxBytes": consts.PasswordMaxBytes})
+	}
+	if !lowercasePresent {
+		o.Add(field, RuleLowercase, "lowercase letter missing", nil)
+	}
+	if !uppercasePresent {
+		o.Add(field, RuleUppercase, "uppercase letter missing", nil)
+	}
+	if !numberPresent {
+		o.Add(field, RuleDigit, "at least one numeric character required", nil)
+	}
+	if !specialCharPresent {
+		o.Add(field, RuleSpecial, "special character missing", nil)
+	}
+	if IsCommonPassword(password) {
+		o.Add(field, RuleCommon, "password is too common", nil)
+	}
+}
+
+// IsCommonPassword checks password in the bundled list, the case is ignored.
+func IsCommonPassword(password string) bool {
+	commonPasswordsOnce.Do(func() {
+		lines := strings.Split(commonPasswordsFile, "\n")
+		commonPasswords = make(map[string]struct{}, len(lines))
+
+		for _, line := range lines {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			commonPasswords[strings.ToLower(line)] = struct{}{}
+		}
+	})
+
+	_, ok := commonPasswords[strings.ToLower(password)]
+
+	return ok
+}
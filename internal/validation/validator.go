@@ -0,0 +1,128 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Dsmit05/metida/internal/apperr"
+)
+
+// Names of the rules in violations.
+const (
+	RuleRequired  = "required"
+	RuleLength    = "length"
+	RuleRange     = "range"
+	RuleOneOf     = "oneOf"
+	RuleEmail     = "email"
+	RuleType      = "type"
+	RuleJSON      = "json"
+	RuleLowercase = "lowercase"
+	RuleUppercase = "uppercase"
+	RuleDigit     = "digit"
+	RuleSpecial   = "special"
+	RuleCommon    = "common"
+)
+
+// Validator collects violations of the rules by fields of one input,
+// a field with a violation is not checked by the next rules.
+type Validator struct {
+	violations     []apperr.FieldError
+	passwordPolicy PasswordPolicy
+}
+
+// NewValidator return Validator checking passwords by the policy,
+// the zero Validator uses DefaultPasswordPolicy.
+func NewValidator(passwordPolicy PasswordPolicy) *Validator {
+	return &Validator{passwordPolicy: passwordPolicy}
+}
+
+// Add violation of the rule, params are shown to the client with the message.
+func (o *Validator) Add(field, rule, message string, params map[string]interface{}) {
+	o.violations = append(o.violations, apperr.FieldError{Field: field, Rule: rule, Message: message, Params: params})
+}
+
+// Has reports whether the field already has a violation.
+func (o *Validator) Has(field string) bool {
+	for _, violation := range o.violations {
+		if violation.Field == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Violations return all violations in the order they were found.
+func (o *Validator) Violations() []apperr.FieldError {
+	return o.violations
+}
+
+// Err return validation error with all violations or nil.
+func (o *Validator) Err() error {
+	if len(o.violations) == 0 {
+		return nil
+	}
+
+	return apperr.Invalid(o.violations...)
+}
+
+// Required value must contain not only spaces.
+func (o *Validator) Required(field, value string) {
+	if o.Has(field) {
+		return
+	}
+
+	if strings.TrimSpace(value) == "" {
+		o.Add(field, RuleRequired, "must not be empty", nil)
+	}
+}
+
+// Length of the value in symbols must be from min to max, zero max means no upper limit.
+func (o *Validator) Length(field, value string, min, max int) {
+	if o.Has(field) {
+		return
+	}
+
+	length := utf8.RuneCountInString(value)
+	if length >= min && (max == 0 || length <= max) {
+		return
+	}
+
+	if max == 0 {
+		o.Add(field, RuleLength, fmt.Sprintf("must be at least %d characters long", min),
+			map[string]interface{}{"min": min})
+		return
+	}
+
+	o.Add(field, RuleLength, fmt.Sprintf("must be from %d to %d characters long", min, max),
+		map[string]interface{}{"min": min, "max": max})
+}
+
+// Range value must be from min to max.
+func (o *Validator) Range(field string, value, min, max int64) {
+	if o.Has(field) {
+		return
+	}
+
+	if value < min || value > max {
+		o.Add(field, RuleRange, fmt.Sprintf("must be from %d to %d", min, max),
+			map[string]interface{}{"min": min, "max": max})
+	}
+}
+
+// OneOf value must be one of the allowed values.
+func (o *Validator) OneOf(field, value string, values ...string) {
+	if o.Has(field) {
+		return
+	}
+
+	for _, allowed := range values {
+		if value == allowed {
+			return
+		}
+	}
+
+	o.Add(field, RuleOneOf, "must be one of "+strings.Join(values, ", "),
+		map[string]interface{}{"values": values})
+}
@@ -0,0 +1,118 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+func rulesOf(v *Validator, field string) []string {
+	var rules []string
+	for _, violation := range v.Violations() {
+		if violation.Field == field {
+			rules = append(rules, violation.Rule)
+		}
+	}
+
+	return rules
+}
+
+func TestPassword(t *testing.T) {
+	var tests = []struct {
+		name      string
+		password  string
+		policy    PasswordPolicy
+		wantRules []string
+	}{
+		{name: "Case-1: strong password", password: "Q@werty1_23"},
+		{name: "Case-2: empty", password: "", wantRules: []string{RuleRequired}},
+		{name: "Case-3: every rule is reported", password: "qwerty",
+			wantRules: []string{RuleLength, RuleUppercase, RuleDigit, RuleSpecial, RuleCommon}},
+		{name: "Case-4: common password of any case", password: "p@ssw0rd1",
+			wantRules: []string{RuleUppercase, RuleCommon}},
+		{name: "Case-5: common complex password", password: "Welcome123!", wantRules: []string{RuleCommon}},
+		{name: "Case-6: length from the policy", password: "Q@werty1_23",
+			policy: PasswordPolicy{MinLength: 12, MaxLength: 64}, wantRules: []string{RuleLength}},
+		{name: "Case-7: length in symbols", password: "Я@werty1_23" + strings.Repeat("я", 21)},
+		{name: "Case-8: longer than bcrypt hashes", password: "Я@werty1_23" + strings.Repeat("я", 40),
+			policy: PasswordPolicy{MinLength: 8, MaxLength: 64}, wantRules: []string{RuleLength}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewValidator(tt.policy)
+			v.Password("password", tt.password)
+
+			got := rulesOf(v, "password")
+			if strings.Join(got, ",") != strings.Join(tt.wantRules, ",") {
+				t.Errorf("Password() rules = %v, want %v", got, tt.wantRules)
+			}
+		})
+	}
+}
+
+type testInput struct {
+	Email string `json:"email" binding:"required"`
+	Age   int    `json:"age"`
+	Note  string `binding:"required"`
+}
+
+func TestAddBindError(t *testing.T) {
+	var tests = []struct {
+		name        string
+		body        string
+		wantField   string
+		wantRule    string
+		wantDecoded bool
+	}{
+		{name: "Case-1: required field by json name", body: `{"Note": "x"}`,
+			wantField: "email", wantRule: RuleRequired, wantDecoded: true},
+		{name: "Case-2: field without json tag", body: `{"email": "a@b.c"}`,
+			wantField: "Note", wantRule: RuleRequired, wantDecoded: true},
+		{name: "Case-3: wrong type", body: `{"email": "a@b.c", "age": "old"}`, wantField: "age", wantRule: RuleType},
+		{name: "Case-4: broken json", body: `{"email": `, wantField: bodyField, wantRule: RuleJSON},
+		{name: "Case-5: empty body", body: ``, wantField: bodyField, wantRule: RuleRequired},
+		{name: "Case-6: syntax error", body: `{"email" "a"}`, wantField: bodyField, wantRule: RuleJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var input testInput
+
+			err := binding.JSON.BindBody([]byte(tt.body), &input)
+			if err == nil {
+				t.Fatal("BindBody() error is nil")
+			}
+
+			v := new(Validator)
+			decoded := v.AddBindError(&input, err)
+			if decoded != tt.wantDecoded {
+				t.Errorf("AddBindError() = %v, want %v", decoded, tt.wantDecoded)
+			}
+
+			violations := v.Violations()
+			if len(violations) != 1 || violations[0].Field != tt.wantField || violations[0].Rule != tt.wantRule {
+				t.Errorf("AddBindError() violations = %+v, want %v %v", violations, tt.wantField, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestValidatorErr(t *testing.T) {
+	v := new(Validator)
+	if err := v.Err(); err != nil {
+		t.Fatalf("Err() = %v, want nil", err)
+	}
+
+	v.Email("email", "ivan")
+	v.Required("email", "")
+	v.Length("name", "Iv", 4, 24)
+
+	if got := len(v.Violations()); got != 2 {
+		t.Fatalf("Violations() = %+v, want one violation per field", v.Violations())
+	}
+
+	want := "email: must be a valid email address; name: must be from 4 to 24 characters long"
+	if err := v.Err(); err == nil || err.Error() != want {
+		t.Errorf("Err() = %v, want %v", err, want)
+	}
+}
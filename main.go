@@ -11,9 +11,14 @@ import (
 	"github.com/Dsmit05/metida/internal/config"
 	"github.com/Dsmit05/metida/internal/cryptography"
 	"github.com/Dsmit05/metida/internal/debag"
+	"github.com/Dsmit05/metida/internal/lockout"
 	"github.com/Dsmit05/metida/internal/logger"
+	"github.com/Dsmit05/metida/internal/mail"
 	"github.com/Dsmit05/metida/internal/metrics"
+	"github.com/Dsmit05/metida/internal/ratelimit"
+	"github.com/Dsmit05/metida/internal/rbac"
 	"github.com/Dsmit05/metida/internal/repositories"
+	"github.com/Dsmit05/metida/internal/storage"
 	"github.com/Dsmit05/metida/internal/utils"
 )
 
@@ -63,17 +68,59 @@ func main() {
 	metric := metrics.NewServiceMetrics()
 
 	// Init connect to db
-	db, err := repositories.NewPostgresRepository(cfg, metric)
+	db, err := repositories.NewPostgresRepository(ctx, cfg, metric)
 	if err != nil {
 		logger.Error("repositories.NewPostgresRepository()", err)
 		return
 	}
 	defer db.Close()
 
-	managerToken := cryptography.NewManagerToken(cfg.Cryptography.Secret)
+	// Init keys for signing access tokens
+	keySet, err := cryptography.LoadKeySet(cfg.GetJWTActiveKeyID(), cfg.GetJWTKeyFiles()...)
+	if err != nil {
+		logger.Error("cryptography.LoadKeySet()", err)
+		return
+	}
+
+	managerToken := cryptography.NewManagerToken(cfg.Cryptography.Secret, keySet, cfg.GetJWTClaimsOptions())
+
+	// Init mail sender
+	mailSender, err := mail.NewSender(cfg)
+	if err != nil {
+		logger.Error("mail.NewSender()", err)
+		return
+	}
+
+	// Init role permissions
+	permissions, err := rbac.NewPermissions(ctx, db)
+	if err != nil {
+		logger.Error("rbac.NewPermissions()", err)
+		return
+	}
+
+	// Init brute-force protection of sign-in
+	loginGuard, err := lockout.NewGuard(cfg, db, metric)
+	if err != nil {
+		logger.Error("lockout.NewGuard()", err)
+		return
+	}
+
+	// Init store of rate limits
+	rateLimitStore, err := ratelimit.NewStore(cfg)
+	if err != nil {
+		logger.Error("ratelimit.NewStore()", err)
+		return
+	}
+
+	fileStorage, err := storage.NewStorage(cfg)
+	if err != nil {
+		logger.Error("storage.NewStorage()", err)
+		return
+	}
 
 	// Start api server
-	apiServer := api.NewApiServer(db, managerToken, cfg, metric)
+	apiServer := api.NewApiServer(
+		db, managerToken, mailSender, permissions, loginGuard, rateLimitStore, fileStorage, cfg, metric)
 	go apiServer.Start()
 
 	// Start debag server
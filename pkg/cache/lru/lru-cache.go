@@ -16,15 +16,18 @@ var (
 
 // Cache is the main cache type.
 type Cache struct {
-	len               int        // len cache data.
-	cap               int        // maximum cache capacity.
-	mx                sync.Mutex // mu is the mutex variable to prevent race conditions.
-	lst               *list.List // doubly linked list.
+	len               int                    // len cache data.
+	cap               int                    // maximum cache capacity.
+	mx                sync.Mutex             // mu is the mutex variable to prevent race conditions.
+	lst               *list.List             // doubly linked list.
+	items             map[KeyI]*list.Element // index of the list, so the search does not iterate it.
 	defaultExpiration time.Duration
+	now               func() time.Time
 }
 
 // KeyI is key Cache, your key is to implement the method Equally(KeyI)
-// this method should be able to equal your structure
+// this method should be able to equal your structure.
+// The key is also used as a map key, so its type must be comparable.
 type KeyI interface {
 	Equally(KeyI) bool
 }
@@ -47,22 +50,26 @@ type unit struct {
 // return:
 // *Cache: Initialized Cache
 func NewLruCache(cap int, defaultExpiration, cleanupInterval time.Duration) *Cache {
-	lst := list.New()
+	lruCache := newLruCache(cap, defaultExpiration, time.Now)
 
+	go lruCache.clearExpiredDataWithInterval(cleanupInterval)
+
+	return lruCache
+}
+
+func newLruCache(cap int, defaultExpiration time.Duration, now func() time.Time) *Cache {
 	if defaultExpiration < 0 {
 		defaultExpiration = 0
 	}
 
-	lruCache := &Cache{
+	return &Cache{
 		cap:               cap,
 		mx:                sync.Mutex{},
-		lst:               lst,
+		lst:               list.New(),
+		items:             make(map[KeyI]*list.Element),
 		defaultExpiration: defaultExpiration,
+		now:               now,
 	}
-
-	go lruCache.clearExpiredDataWithInterval(cleanupInterval)
-
-	return lruCache
 }
 
 // Add adding unit in cache
@@ -75,82 +82,47 @@ func NewLruCache(cap int, defaultExpiration, cleanupInterval time.Duration) *Cac
 // return:
 // - error: key creation error
 func (c *Cache) Add(key KeyI, val ValI, exp time.Duration) error {
-	_, found := c.get(key)
-	if found {
-		return ErrKeyAlreadyExist
-	}
-
-	if exp < -1 {
-		return ErrExpirationInvalid
-	}
-
-	var expiration int64
-
-	switch exp {
-	case 0:
-		expiration = 0
-	case -1:
-		expiration = time.Now().Add(c.defaultExpiration).UnixNano()
-	default:
-		expiration = time.Now().Add(exp).UnixNano()
-	}
-
-	item := unit{
-		Key:        key,
-		Val:        val,
-		Expiration: expiration,
-	}
-
 	c.mx.Lock()
 	defer c.mx.Unlock()
 
-	if c.Cap() > 0 && c.Len() == c.Cap() {
-		lruKey := c.getLRU()
-		c.delete(lruKey.Key)
+	expiration, err := c.expiration(exp)
+	if err != nil {
+		return err
 	}
 
-	c.lst.PushFront(item)
-	c.len++
+	// the search is done under the lock, so parallel Add can't insert the same key twice
+	if _, found := c.get(key); found {
+		return ErrKeyAlreadyExist
+	}
+
+	c.push(unit{Key: key, Val: val, Expiration: expiration})
 
 	return nil
 }
 
 // Get return value with changing order
 func (c *Cache) Get(key KeyI) (ValI, bool) {
-	if c.Len() == 0 {
-		return nil, false
-	}
-
 	c.mx.Lock()
 	defer c.mx.Unlock()
 
-	val, found := c.get(key)
-	if val == nil {
-		return nil, found
+	e, found := c.get(key)
+	if !found {
+		return nil, false
 	}
 
-	e := c.lst.Remove(val)
-	c.lst.PushFront(e) // insert the item to the top
+	c.lst.MoveToFront(e) // insert the item to the top
 
-	return val.Value.(unit).Val, found
+	return e.Value.(unit).Val, true
 }
 
 // IsExist check element in the cache, without changing order.
 func (c *Cache) IsExist(key KeyI) bool {
-	if c.Len() == 0 {
-		return false
-	}
-
 	c.mx.Lock()
-	_, found := c.get(key)
+	defer c.mx.Unlock()
 
-	if found {
-		c.mx.Unlock()
-		return true
-	}
-	c.mx.Unlock()
+	_, found := c.get(key)
 
-	return false
+	return found
 }
 
 // Clear deleting all elements
@@ -162,23 +134,29 @@ func (c *Cache) Clear() {
 
 // Peek return value of the key without changing the order.
 func (c *Cache) Peek(key KeyI) (ValI, bool) {
-	if c.Len() == 0 {
-		return nil, false
-	}
-
 	c.mx.Lock()
-	val, found := c.get(key)
-	c.mx.Unlock()
+	defer c.mx.Unlock()
 
+	e, found := c.get(key)
 	if !found {
-		return nil, found
+		return nil, false
 	}
 
-	return val.Value.(unit).Val, found
+	return e.Value.(unit).Val, true
+}
+
+// Delete removes the key, a missing key is not an error.
+func (c *Cache) Delete(key KeyI) {
+	c.mx.Lock()
+	c.delete(key)
+	c.mx.Unlock()
 }
 
 // Len return cache length.
 func (c *Cache) Len() int {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
 	return c.len
 }
 
@@ -190,10 +168,10 @@ func (c *Cache) Cap() int {
 // Replace changing the key value taking into account the order of elements.
 func (c *Cache) Replace(key KeyI, val ValI) error {
 	c.mx.Lock()
-	e, found := c.get(key)
+	defer c.mx.Unlock()
 
+	e, found := c.get(key)
 	if !found {
-		c.mx.Unlock()
 		return ErrKeyNotExist
 	}
 
@@ -202,7 +180,6 @@ func (c *Cache) Replace(key KeyI, val ValI) error {
 		Val:        val,
 		Expiration: e.Value.(unit).Expiration,
 	}
-	c.mx.Unlock()
 
 	return nil
 }
@@ -210,17 +187,13 @@ func (c *Cache) Replace(key KeyI, val ValI) error {
 // ClearExpiredData deleting elements data with expired lifetime.
 func (c *Cache) ClearExpiredData() {
 	c.mx.Lock()
-	l := c.Len()
-	c.mx.Unlock()
+	defer c.mx.Unlock()
 
-	if l == 0 {
+	if c.len == 0 {
 		return
 	}
 
-	c.mx.Lock()
-	now := time.Now().UnixNano()
-	c.clearExpiredData(now)
-	c.mx.Unlock()
+	c.clearExpiredData(c.now().UnixNano())
 }
 
 // UpdateValue updating the lifetime and/or key value
@@ -232,21 +205,111 @@ func (c *Cache) UpdateValue(key KeyI, val ValI, exp time.Duration) error {
 	c.mx.Lock()
 	defer c.mx.Unlock()
 
-	newExpTime := time.Now().Add(exp).Unix()
-	_, err := c.update(key, val, newExpTime)
+	e, found := c.get(key)
+	if !found {
+		return ErrKeyNotExist
+	}
+
+	old := e.Value.(unit)
+
+	expiration, err := c.updatedExpiration(exp, old.Expiration)
+	if err != nil {
+		return err
+	}
+
+	if val == nil {
+		val = old.Val
+	}
 
-	return err
+	e.Value = unit{Key: key, Val: val, Expiration: expiration}
+	c.lst.MoveToFront(e)
+
+	return nil
 }
 
-// get iterable the cache, return true if the element is found
-func (c *Cache) get(key KeyI) (*list.Element, bool) {
-	for e := c.lst.Front(); e != nil; e = e.Next() {
-		if e.Value.(unit).Key.Equally(key) {
-			return e, true
+// Modify changes value of the key under the lock of the cache, so parallel changes are not lost.
+// fn gets the current value, found is false for a missing or expired key,
+// and return the new value with its lifetime in the same units as in Add.
+func (c *Cache) Modify(key KeyI, fn func(val ValI, found bool) (ValI, time.Duration)) (ValI, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	var old unit
+
+	e, found := c.get(key)
+	if found {
+		old = e.Value.(unit)
+		found = !c.expired(old)
+	}
+
+	val, exp := fn(old.Val, found)
+
+	if e == nil {
+		expiration, err := c.expiration(exp)
+		if err != nil {
+			return nil, err
 		}
+
+		c.push(unit{Key: key, Val: val, Expiration: expiration})
+
+		return val, nil
+	}
+
+	expiration, err := c.updatedExpiration(exp, old.Expiration)
+	if err != nil {
+		return nil, err
+	}
+
+	e.Value = unit{Key: key, Val: val, Expiration: expiration}
+	c.lst.MoveToFront(e)
+
+	return val, nil
+}
+
+// expiration converts lifetime of a new unit to unix nano time, 0 is ∞, -1 is defaultExpiration.
+func (c *Cache) expiration(exp time.Duration) (int64, error) {
+	switch {
+	case exp < -1:
+		return 0, ErrExpirationInvalid
+	case exp == 0:
+		return 0, nil
+	case exp == -1 && c.defaultExpiration == 0:
+		return 0, nil
+	case exp == -1:
+		return c.now().Add(c.defaultExpiration).UnixNano(), nil
+	default:
+		return c.now().Add(exp).UnixNano(), nil
+	}
+}
+
+// updatedExpiration converts new lifetime of the unit, -1 keeps the current expiration.
+func (c *Cache) updatedExpiration(exp time.Duration, current int64) (int64, error) {
+	if exp == -1 {
+		return current, nil
+	}
+
+	return c.expiration(exp)
+}
+
+// expired reports whether lifetime of the unit is over, the unit is still in the cache until cleanup.
+func (c *Cache) expired(item unit) bool {
+	return item.Expiration != 0 && item.Expiration < c.now().UnixNano()
+}
+
+// get return element of the key from the index.
+func (c *Cache) get(key KeyI) (*list.Element, bool) {
+	e, found := c.items[key]
+	return e, found
+}
+
+// push adds the unit to the top, the least recently used unit is removed if the cache is full.
+func (c *Cache) push(item unit) {
+	if c.cap > 0 && c.len >= c.cap {
+		c.delete(c.getLRU().Key)
 	}
 
-	return nil, false
+	c.items[item.Key] = c.lst.PushFront(item)
+	c.len++
 }
 
 // clearExpiredDataWithInterval starts clearing the cache
@@ -265,12 +328,13 @@ func (c *Cache) clearExpiredDataWithInterval(cleanupInterval time.Duration) {
 
 // delete remove data from the list, and reduce length
 func (c *Cache) delete(key KeyI) {
-	v, found := c.get(key)
+	e, found := c.get(key)
 	if !found {
 		return
 	}
 
-	c.lst.Remove(v)
+	c.lst.Remove(e)
+	delete(c.items, key)
 	c.len--
 }
 
@@ -279,14 +343,11 @@ func (c *Cache) getLRU() unit {
 	return c.lst.Back().Value.(unit)
 }
 
-// clear iterate over a doubly linked list and remove elements.
+// clear remove all elements.
 func (c *Cache) clear() {
-	var next *list.Element
-	for e := c.lst.Front(); e != nil; e = next {
-		next = e.Next()
-		c.lst.Remove(e)
-		c.len--
-	}
+	c.lst.Init()
+	c.items = make(map[KeyI]*list.Element)
+	c.len = 0
 }
 
 // clearExpiredData clearing expired data.
@@ -295,40 +356,10 @@ func (c *Cache) clearExpiredData(now int64) {
 	for e := c.lst.Front(); e != nil; e = next {
 		next = e.Next()
 
-		if exp := e.Value.(unit).Expiration; exp != 0 && exp < now {
+		if item := e.Value.(unit); item.Expiration != 0 && item.Expiration < now {
 			c.lst.Remove(e)
+			delete(c.items, item.Key)
 			c.len--
 		}
 	}
 }
-
-// update changing lifetime and/or key value and move it to the top of the list.
-func (c *Cache) update(key KeyI, val ValI, exp int64) (unit, error) {
-	var next *list.Element
-	for e := c.lst.Front(); e != nil; e = next {
-		next = e.Next()
-
-		if k := e.Value.(unit).Key; k == key {
-			if val == nil {
-				val = e.Value.(unit).Val
-			}
-
-			if exp == -1 {
-				exp = e.Value.(unit).Expiration
-			}
-
-			c.lst.Remove(e)
-
-			newItem := unit{
-				Key:        key,
-				Val:        val,
-				Expiration: exp,
-			}
-			c.lst.PushFront(newItem)
-
-			return newItem, nil
-		}
-	}
-
-	return unit{}, ErrKeyNotExist
-}
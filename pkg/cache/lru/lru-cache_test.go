@@ -1,6 +1,10 @@
 package lru
 
-import "testing"
+import (
+	"sync"
+	"testing"
+	"time"
+)
 
 //go test -bench=. -benchmem -benchtime=5x
 const lenCache = 10000
@@ -68,3 +72,146 @@ func BenchmarkLruCacheExist(b *testing.B) {
 		}
 	}
 }
+
+type testKey string
+
+func (o testKey) Equally(k KeyI) bool {
+	return o == k
+}
+
+func TestCacheAddExpiration(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	var tests = []struct {
+		name    string
+		exp     time.Duration
+		want    int64
+		wantErr error
+	}{
+		{name: "Case-1: zero never expires", exp: 0, want: 0},
+		{name: "Case-2: default expiration", exp: -1, want: now.Add(time.Minute).UnixNano()},
+		{name: "Case-3: own lifetime in nanoseconds", exp: time.Second, want: now.Add(time.Second).UnixNano()},
+		{name: "Case-4: invalid lifetime", exp: -2, wantErr: ErrExpirationInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newLruCache(0, time.Minute, func() time.Time { return now })
+
+			if err := c.Add(testKey("key"), 1, tt.exp); err != tt.wantErr {
+				t.Fatalf("Add() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if c.Len() != 0 {
+					t.Errorf("Len() = %v after error", c.Len())
+				}
+				return
+			}
+
+			if got := c.items[testKey("key")].Value.(unit).Expiration; got != tt.want {
+				t.Errorf("Add() expiration = %v, want %v", got, tt.want)
+			}
+
+			if err := c.Add(testKey("key"), 2, tt.exp); err != ErrKeyAlreadyExist {
+				t.Errorf("Add() the same key error = %v, want %v", err, ErrKeyAlreadyExist)
+			}
+		})
+	}
+}
+
+func TestCacheUpdateValue(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	var tests = []struct {
+		name    string
+		key     testKey
+		val     ValI
+		exp     time.Duration
+		wantVal ValI
+		want    int64
+		wantErr error
+	}{
+		{name: "Case-1: zero never expires", key: "key", val: 2, exp: 0, wantVal: 2, want: 0},
+		{name: "Case-2: minus one keeps lifetime", key: "key", val: 2, exp: -1, wantVal: 2,
+			want: now.Add(time.Minute).UnixNano()},
+		{name: "Case-3: new lifetime in nanoseconds", key: "key", exp: time.Hour, wantVal: 1,
+			want: now.Add(time.Hour).UnixNano()},
+		{name: "Case-4: invalid lifetime", key: "key", val: 2, exp: -2, wantVal: 1,
+			want: now.Add(time.Minute).UnixNano(), wantErr: ErrExpirationInvalid},
+		{name: "Case-5: missing key", key: "other", val: 2, exp: 0, wantErr: ErrKeyNotExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newLruCache(0, 0, func() time.Time { return now })
+			_ = c.Add(testKey("key"), 1, time.Minute)
+
+			if err := c.UpdateValue(tt.key, tt.val, tt.exp); err != tt.wantErr {
+				t.Fatalf("UpdateValue() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == ErrKeyNotExist {
+				return
+			}
+
+			item := c.items[testKey("key")].Value.(unit)
+			if item.Val != tt.wantVal || item.Expiration != tt.want {
+				t.Errorf("UpdateValue() = %v %v, want %v %v", item.Val, item.Expiration, tt.wantVal, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacheModify(t *testing.T) {
+	now := time.Unix(1000, 0)
+	c := newLruCache(2, 0, func() time.Time { return now })
+
+	increment := func(val ValI, found bool) (ValI, time.Duration) {
+		if !found {
+			return 1, time.Minute
+		}
+
+		return val.(int) + 1, -1
+	}
+
+	t.Run("Case-1: parallel changes are not lost", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = c.Modify(testKey("a"), increment)
+			}()
+		}
+		wg.Wait()
+
+		if val, _ := c.Peek(testKey("a")); val != 100 {
+			t.Errorf("Modify() value = %v, want 100", val)
+		}
+	})
+
+	t.Run("Case-2: expired value is not found", func(t *testing.T) {
+		now = now.Add(time.Hour)
+
+		if val, _ := c.Modify(testKey("a"), increment); val != 1 {
+			t.Errorf("Modify() value = %v, want 1", val)
+		}
+	})
+
+	t.Run("Case-3: least recently used key is evicted", func(t *testing.T) {
+		_, _ = c.Modify(testKey("b"), increment)
+		_, _ = c.Modify(testKey("c"), increment)
+
+		if c.IsExist(testKey("a")) || !c.IsExist(testKey("b")) || !c.IsExist(testKey("c")) || c.Len() != 2 {
+			t.Errorf("keys a=%v b=%v c=%v, len = %v", c.IsExist(testKey("a")), c.IsExist(testKey("b")),
+				c.IsExist(testKey("c")), c.Len())
+		}
+	})
+
+	t.Run("Case-4: delete and cleanup", func(t *testing.T) {
+		c.Delete(testKey("b"))
+		now = now.Add(time.Hour)
+		c.ClearExpiredData()
+
+		if c.Len() != 0 || len(c.items) != 0 || c.lst.Len() != 0 {
+			t.Errorf("Len() = %v, index = %v, list = %v", c.Len(), len(c.items), c.lst.Len())
+		}
+	})
+}
@@ -0,0 +1,131 @@
+// Package diff compares texts line by line.
+package diff
+
+import "strings"
+
+// Op kind of the line in the diff.
+type Op byte
+
+const (
+	Equal  Op = ' '
+	Insert Op = '+'
+	Delete Op = '-'
+)
+
+// MaxCells limit of the comparison table, bigger texts are shown as fully replaced.
+const MaxCells = 4000000
+
+// Line of the diff, deleted lines go before inserted ones.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Lines return the shortest line diff turning a into b.
+func Lines(a, b string) []Line {
+	x, y := split(a), split(b)
+
+	// common prefix and suffix are not compared
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]Line, 0, len(x)+len(y))
+	for _, text := range x[:prefix] {
+		lines = append(lines, Line{Op: Equal, Text: text})
+	}
+
+	lines = append(lines, compare(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix])...)
+
+	for _, text := range x[len(x)-suffix:] {
+		lines = append(lines, Line{Op: Equal, Text: text})
+	}
+
+	return lines
+}
+
+// Stat return number of inserted and deleted lines.
+func Stat(lines []Line) (inserted, deleted int) {
+	for _, line := range lines {
+		switch line.Op {
+		case Insert:
+			inserted++
+		case Delete:
+			deleted++
+		}
+	}
+
+	return inserted, deleted
+}
+
+// compare finds the longest common subsequence of lines.
+func compare(x, y []string) []Line {
+	lines := make([]Line, 0, len(x)+len(y))
+
+	if len(x) == 0 || len(y) == 0 || (len(x)+1)*(len(y)+1) > MaxCells {
+		for _, text := range x {
+			lines = append(lines, Line{Op: Delete, Text: text})
+		}
+		for _, text := range y {
+			lines = append(lines, Line{Op: Insert, Text: text})
+		}
+
+		return lines
+	}
+
+	// lcs[i][j] length of the common subsequence of x[i:] and y[j:]
+	width := len(y) + 1
+	lcs := make([]int32, (len(x)+1)*width)
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			switch {
+			case x[i] == y[j]:
+				lcs[i*width+j] = lcs[(i+1)*width+j+1] + 1
+			case lcs[(i+1)*width+j] >= lcs[i*width+j+1]:
+				lcs[i*width+j] = lcs[(i+1)*width+j]
+			default:
+				lcs[i*width+j] = lcs[i*width+j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			lines = append(lines, Line{Op: Equal, Text: x[i]})
+			i++
+			j++
+		case lcs[(i+1)*width+j] >= lcs[i*width+j+1]:
+			lines = append(lines, Line{Op: Delete, Text: x[i]})
+			i++
+		default:
+			lines = append(lines, Line{Op: Insert, Text: y[j]})
+			j++
+		}
+	}
+
+	for ; i < len(x); i++ {
+		lines = append(lines, Line{Op: Delete, Text: x[i]})
+	}
+	for ; j < len(y); j++ {
+		lines = append(lines, Line{Op: Insert, Text: y[j]})
+	}
+
+	return lines
+}
+
+// split empty text has no lines.
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, "\n")
+}
@@ -0,0 +1,59 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	var tests = []struct {
+		name string
+		a    string
+		b    string
+		want []Line
+	}{
+		{name: "Case-1: equal texts",
+			a:    "one\ntwo",
+			b:    "one\ntwo",
+			want: []Line{{Equal, "one"}, {Equal, "two"}},
+		},
+		{name: "Case-2: line is inserted",
+			a:    "one\nthree",
+			b:    "one\ntwo\nthree",
+			want: []Line{{Equal, "one"}, {Insert, "two"}, {Equal, "three"}},
+		},
+		{name: "Case-3: line is changed",
+			a:    "one\ntwo\nthree",
+			b:    "one\n2\nthree",
+			want: []Line{{Equal, "one"}, {Delete, "two"}, {Insert, "2"}, {Equal, "three"}},
+		},
+		{name: "Case-4: text from empty",
+			a:    "",
+			b:    "one",
+			want: []Line{{Insert, "one"}},
+		},
+		{name: "Case-5: lines are moved",
+			a:    "a\nb\nc\nd",
+			b:    "b\nc\nd\na",
+			want: []Line{{Delete, "a"}, {Equal, "b"}, {Equal, "c"}, {Equal, "d"}, {Insert, "a"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Case-6: big texts are fully replaced", func(t *testing.T) {
+		a := strings.Repeat("a\n", 3000) + "x"
+		b := strings.Repeat("b\n", 3000) + "x"
+
+		inserted, deleted := Stat(Lines(a, b))
+		if inserted != 3000 || deleted != 3000 {
+			t.Errorf("Stat() = +%v -%v, want +3000 -3000", inserted, deleted)
+		}
+	})
+}
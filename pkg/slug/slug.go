@@ -0,0 +1,68 @@
+package slug
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxLength maximum length of the slug, longer slugs are cut by the word boundary.
+const MaxLength = 80
+
+// cyrillic transliteration of russian letters.
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "h", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "sch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+// Make converts text into the lower case url-safe slug: latin letters, digits and hyphens.
+// Russian letters are transliterated, other symbols become word separators.
+func Make(text string) string {
+	var builder strings.Builder
+
+	separator := false
+	for _, r := range strings.ToLower(text) {
+		part, ok := cyrillic[r]
+		if !ok && r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			part, ok = string(r), true
+		}
+
+		if !ok {
+			separator = true
+			continue
+		}
+
+		if part == "" {
+			continue
+		}
+
+		if separator && builder.Len() > 0 {
+			builder.WriteByte('-')
+		}
+
+		builder.WriteString(part)
+		separator = false
+	}
+
+	return cut(builder.String())
+}
+
+// IsValid checks that s is already normalized slug.
+func IsValid(s string) bool {
+	return s != "" && Make(s) == s
+}
+
+func cut(s string) string {
+	if len(s) <= MaxLength {
+		return s
+	}
+
+	s = s[:MaxLength]
+	if i := strings.LastIndexByte(s, '-'); i > 0 {
+		s = s[:i]
+	}
+
+	return strings.Trim(s, "-")
+}
@@ -0,0 +1,48 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	var tests = []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "Case-1: latin words", text: "Hello, World!", want: "hello-world"},
+		{name: "Case-2: russian words", text: "Привет, мир", want: "privet-mir"},
+		{name: "Case-3: separators on the edges", text: "  --Go 1.18--  ", want: "go-1-18"},
+		{name: "Case-4: soft sign is skipped", text: "Подъезд", want: "podezd"},
+		{name: "Case-5: only symbols", text: "!@#$", want: ""},
+		{name: "Case-6: long text is cut by word", text: strings.Repeat("word ", 30), want: strings.TrimSuffix(strings.Repeat("word-", 16), "-")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Make(tt.text); got != tt.want {
+				t.Errorf("Make() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsValid(t *testing.T) {
+	var tests = []struct {
+		name string
+		slug string
+		want bool
+	}{
+		{name: "Case-1: valid slug", slug: "hello-world", want: true},
+		{name: "Case-2: upper case", slug: "Hello-World", want: false},
+		{name: "Case-3: empty", slug: "", want: false},
+		{name: "Case-4: double hyphen", slug: "hello--world", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsValid(tt.slug); got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}